@@ -0,0 +1,61 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+// BrokerRule represents the routing rule a Broker client publishes on the rules exchange.
+type BrokerRule struct {
+	// MaxLatency is the maximum latency (in ms) accepted from the announcing nodes.
+	// +kubebuilder:validation:Minimum=0
+	MaxLatency int `json:"maxLatency,omitempty"`
+	// MinBandwidth is the minimum bandwidth (in Mbps) accepted from the announcing nodes.
+	// +kubebuilder:validation:Minimum=0
+	MinBandwidth int `json:"minBandwidth,omitempty"`
+	// Locations is the list of locations accepted from the announcing nodes.
+	Locations []string `json:"locations,omitempty"`
+}
+
+// BrokerMetric represents the static metrics a Broker client announces on the announcements exchange.
+type BrokerMetric struct {
+	// Bandwidth is the bandwidth (in Mbps) announced by the node.
+	// +kubebuilder:validation:Minimum=0
+	Bandwidth int `json:"bandwidth,omitempty"`
+	// Latency is the latency (in ms) announced by the node.
+	// +kubebuilder:validation:Minimum=0
+	Latency int `json:"latency,omitempty"`
+	// Location is the location announced by the node.
+	Location string `json:"location,omitempty"`
+}
+
+// BrokerFilter represents the rules a subscriber applies to the announcements received from a Broker.
+type BrokerFilter struct {
+	// Domains is the list of domains accepted. If empty, every domain is accepted.
+	Domains []string `json:"domains,omitempty"`
+	// Locations is the list of locations (e.g. regions) accepted. If empty, every location is accepted.
+	Locations []string `json:"locations,omitempty"`
+	// MinCPU is the minimum amount of available CPU a node must announce.
+	MinCPU *resource.Quantity `json:"minCpu,omitempty"`
+	// MinMemory is the minimum amount of available memory a node must announce.
+	MinMemory *resource.Quantity `json:"minMemory,omitempty"`
+	// MinGPU is the minimum number of available GPUs a node must announce.
+	MinGPU *resource.Quantity `json:"minGpu,omitempty"`
+	// FlavorTypes is the list of Flavor types of interest. A node is accepted if it offers at least one of them.
+	FlavorTypes []nodecorev1alpha1.FlavorTypeIdentifier `json:"flavorTypes,omitempty"`
+}
@@ -27,8 +27,13 @@ type BrokerSpec struct {
 	ClCert  string `json:"clcert"`
 	CaCert  string `json:"cacert"`
 	Role    string `json:"role"`
-	Rule    string `json:"rule"`
-	Metric  string `json:"metric"`
+	// Rule is the routing rule published on the rules exchange of the Broker.
+	Rule *BrokerRule `json:"rule,omitempty"`
+	// Metric contains the static metrics announced to the Broker, alongside the ones computed from the local Flavors.
+	Metric *BrokerMetric `json:"metric,omitempty"`
+	// Filter contains the rules applied to the announcements received from the Broker.
+	// Only the announcements matching all of them are admitted as KnownClusters.
+	Filter *BrokerFilter `json:"filter,omitempty"`
 }
 
 // ClCert  *corev1.Secret `json:"clcert"`
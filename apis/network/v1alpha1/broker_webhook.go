@@ -0,0 +1,205 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+const (
+	// BrokerRolePublisher is the role of a Broker client that only publishes announcements.
+	BrokerRolePublisher = "publisher"
+	// BrokerRoleSubscriber is the role of a Broker client that only receives announcements.
+	BrokerRoleSubscriber = "subscriber"
+	// BrokerRoleBoth is the role of a Broker client that both publishes and receives announcements.
+	BrokerRoleBoth = "both"
+)
+
+// log is for logging in this package.
+var brokerlog = logf.Log.WithName("broker-resource")
+
+// SetupWebhookWithManager setups the webhooks for the Broker resource with the manager.
+func (r *Broker) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&Broker{}).
+		WithDefaulter(&Broker{}).
+		WithValidator(&Broker{}).
+		Complete()
+}
+
+//nolint:lll // kubebuilder directives are too long, but they must be on the same line
+//+kubebuilder:webhook:path=/mutate-network-fluidos-eu-v1alpha1-broker,mutating=true,failurePolicy=fail,sideEffects=None,groups=network.fluidos.eu,resources=brokers,verbs=create;update,versions=v1alpha1,name=mbroker.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomDefaulter = &Broker{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+func (r *Broker) Default(ctx context.Context, obj runtime.Object) error {
+	_ = ctx
+	broker := obj.(*Broker)
+	brokerlog.Info("DEFAULT WEBHOOK")
+	brokerlog.Info("default", "name", broker.Name)
+
+	if broker.Spec.Name == "" {
+		broker.Spec.Name = broker.Name
+	}
+	if broker.Spec.Role == "" {
+		broker.Spec.Role = BrokerRoleBoth
+	}
+
+	return nil
+}
+
+//nolint:lll // kubebuilder directives are too long, but they must be on the same line
+//+kubebuilder:webhook:path=/validate-network-fluidos-eu-v1alpha1-broker,mutating=false,failurePolicy=fail,sideEffects=None,groups=network.fluidos.eu,resources=brokers,verbs=create;update,versions=v1alpha1,name=vbroker.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &Broker{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *Broker) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	broker := obj.(*Broker)
+	brokerlog.Info("VALIDATE CREATE WEBHOOK")
+	brokerlog.Info("validate create", "name", broker.Name)
+
+	return validateBrokerSpec(&broker.Spec)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *Broker) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	_ = oldObj
+	broker := newObj.(*Broker)
+	brokerlog.Info("VALIDATE UPDATE WEBHOOK")
+	brokerlog.Info("validate update", "name", broker.Name)
+
+	return validateBrokerSpec(&broker.Spec)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *Broker) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	broker := obj.(*Broker)
+	brokerlog.Info("VALIDATE DELETE WEBHOOK")
+	brokerlog.Info("validate delete", "name", broker.Name)
+
+	return nil, nil
+}
+
+func validateBrokerSpec(spec *BrokerSpec) (admission.Warnings, error) {
+	var warnings admission.Warnings
+
+	if spec.Address == "" {
+		return nil, fmt.Errorf("broker address must be set")
+	}
+	if spec.ClCert == "" || spec.CaCert == "" {
+		return nil, fmt.Errorf("broker client and CA certificate secrets must be set")
+	}
+
+	switch spec.Role {
+	case BrokerRolePublisher, BrokerRoleSubscriber, BrokerRoleBoth:
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown role %q, the broker client will act as publisher and subscriber", spec.Role))
+	}
+
+	if err := validateBrokerRule(spec.Rule); err != nil {
+		return warnings, err
+	}
+	if err := validateBrokerMetric(spec.Metric); err != nil {
+		return warnings, err
+	}
+	if err := validateBrokerFilter(spec.Filter); err != nil {
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+func validateBrokerRule(rule *BrokerRule) error {
+	if rule == nil {
+		return nil
+	}
+	if rule.MaxLatency < 0 {
+		return fmt.Errorf("rule maxLatency must not be negative")
+	}
+	if rule.MinBandwidth < 0 {
+		return fmt.Errorf("rule minBandwidth must not be negative")
+	}
+	if err := validateUniqueValues("rule locations", rule.Locations); err != nil {
+		return err
+	}
+	return nil
+}
+
+func validateBrokerMetric(metric *BrokerMetric) error {
+	if metric == nil {
+		return nil
+	}
+	if metric.Latency < 0 {
+		return fmt.Errorf("metric latency must not be negative")
+	}
+	if metric.Bandwidth < 0 {
+		return fmt.Errorf("metric bandwidth must not be negative")
+	}
+	return nil
+}
+
+func validateBrokerFilter(filter *BrokerFilter) error {
+	if filter == nil {
+		return nil
+	}
+	if err := validateUniqueValues("filter domains", filter.Domains); err != nil {
+		return err
+	}
+	if err := validateUniqueValues("filter locations", filter.Locations); err != nil {
+		return err
+	}
+	for name, q := range map[string]*resource.Quantity{"minCpu": filter.MinCPU, "minMemory": filter.MinMemory, "minGpu": filter.MinGPU} {
+		if q != nil && q.Sign() < 0 {
+			return fmt.Errorf("filter %s must not be negative", name)
+		}
+	}
+	for _, ft := range filter.FlavorTypes {
+		switch ft {
+		case nodecorev1alpha1.TypeK8Slice, nodecorev1alpha1.TypeVM, nodecorev1alpha1.TypeService, nodecorev1alpha1.TypeSensor:
+		default:
+			return fmt.Errorf("filter flavor type %s not supported", ft)
+		}
+	}
+	return nil
+}
+
+func validateUniqueValues(field string, values []string) error {
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if v == "" {
+			return fmt.Errorf("%s must not contain empty values", field)
+		}
+		if seen[v] {
+			return fmt.Errorf("%s contains the duplicated value %s", field, v)
+		}
+		seen[v] = true
+	}
+	return nil
+}
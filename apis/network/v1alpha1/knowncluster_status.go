@@ -21,3 +21,12 @@ func (knowncluster *KnownCluster) UpdateStatus() {
 	knowncluster.Status.LastUpdateTime = tools.GetTimeNow()
 	knowncluster.Status.ExpirationTime = tools.GetExpirationTime(0, 0, 10)
 }
+
+// UpdateLastSeen updates the status of the KnownCluster after an announcement has been received.
+func (knowncluster *KnownCluster) UpdateLastSeen() {
+	knowncluster.UpdateStatus()
+	knowncluster.Status.LastSeenTime = knowncluster.Status.LastUpdateTime
+	if knowncluster.Status.FirstSeenTime == "" {
+		knowncluster.Status.FirstSeenTime = knowncluster.Status.LastSeenTime
+	}
+}
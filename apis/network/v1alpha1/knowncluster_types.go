@@ -18,11 +18,52 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// KnownClusterSourceType represents the type of source from which a KnownCluster has been discovered.
+type KnownClusterSourceType string
+
+const (
+	// KnownClusterSourceMulticast is the source of the KnownClusters discovered through multicast on the LAN.
+	KnownClusterSourceMulticast KnownClusterSourceType = "Multicast"
+	// KnownClusterSourceBroker is the source of the KnownClusters discovered through a Broker.
+	KnownClusterSourceBroker KnownClusterSourceType = "Broker"
+	// KnownClusterSourceStatic is the source of the KnownClusters statically configured. They never expire.
+	KnownClusterSourceStatic KnownClusterSourceType = "Static"
+)
+
+// KnownClusterSource represents the source from which a KnownCluster has been discovered.
+type KnownClusterSource struct {
+	// Type of the source.
+	// +kubebuilder:validation:Enum=Multicast;Broker;Static
+	Type KnownClusterSourceType `json:"type"`
+
+	// Name of the source, e.g. the name of the Broker the announcement has been received from.
+	Name string `json:"name,omitempty"`
+}
+
+// KnownClusterPhase represents the health of a KnownCluster.
+type KnownClusterPhase string
+
+const (
+	// KnownClusterReady is the phase of a KnownCluster whose REAR gateway is reachable and ready.
+	KnownClusterReady KnownClusterPhase = "Ready"
+	// KnownClusterUnreachable is the phase of a KnownCluster whose REAR gateway cannot be reached or is not ready.
+	KnownClusterUnreachable KnownClusterPhase = "Unreachable"
+)
+
 // KnownClusterSpec defines the desired state of KnownCluster.
 type KnownClusterSpec struct {
 
 	// Address of the KnownCluster.
 	Address string `json:"address"`
+
+	// NodeID of the KnownCluster.
+	NodeID string `json:"nodeID,omitempty"`
+
+	// Domain of the KnownCluster.
+	Domain string `json:"domain,omitempty"`
+
+	// Source from which the KnownCluster has been first discovered.
+	Source *KnownClusterSource `json:"source,omitempty"`
 }
 
 // KnownClusterStatus defines the observed state of KnownCluster.
@@ -33,11 +74,32 @@ type KnownClusterStatus struct {
 
 	// This field represents the last update time of the KnownCluster.
 	LastUpdateTime string `json:"lastUpdateTime"`
+
+	// This field represents the time the KnownCluster has been announced for the first time.
+	FirstSeenTime string `json:"firstSeenTime,omitempty"`
+
+	// This field represents the time the KnownCluster has been announced for the last time.
+	LastSeenTime string `json:"lastSeenTime,omitempty"`
+
+	// This field represents the round trip time measured towards the REAR gateway of the KnownCluster.
+	GatewayRTT *metav1.Duration `json:"gatewayRTT,omitempty"`
+
+	// This field represents the health of the KnownCluster, as checked through its REAR gateway. It is set for static KnownClusters only.
+	Phase KnownClusterPhase `json:"phase,omitempty"`
+
+	// This field contains the reason of the current phase of the KnownCluster.
+	Message string `json:"message,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:shortName=kclust;kclusts
+//+kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.spec.address`
+//+kubebuilder:printcolumn:name="Domain",type=string,JSONPath=`.spec.domain`
+//+kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source.type`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="RTT",type=string,JSONPath=`.status.gatewayRTT`
+//+kubebuilder:printcolumn:name="Last Seen",type=string,JSONPath=`.status.lastSeenTime`
 
 // KnownCluster is the Schema for the clusters API.
 type KnownCluster struct {
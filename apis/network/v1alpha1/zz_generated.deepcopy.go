@@ -19,6 +19,8 @@
 package v1alpha1
 
 import (
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -27,7 +29,7 @@ func (in *Broker) DeepCopyInto(out *Broker) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -49,6 +51,51 @@ func (in *Broker) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerFilter) DeepCopyInto(out *BrokerFilter) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Locations != nil {
+		in, out := &in.Locations, &out.Locations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinCPU != nil {
+		in, out := &in.MinCPU, &out.MinCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MinMemory != nil {
+		in, out := &in.MinMemory, &out.MinMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MinGPU != nil {
+		in, out := &in.MinGPU, &out.MinGPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FlavorTypes != nil {
+		in, out := &in.FlavorTypes, &out.FlavorTypes
+		*out = make([]nodecorev1alpha1.FlavorTypeIdentifier, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerFilter.
+func (in *BrokerFilter) DeepCopy() *BrokerFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerList) DeepCopyInto(out *BrokerList) {
 	*out = *in
@@ -81,9 +128,59 @@ func (in *BrokerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerMetric) DeepCopyInto(out *BrokerMetric) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerMetric.
+func (in *BrokerMetric) DeepCopy() *BrokerMetric {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerMetric)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerRule) DeepCopyInto(out *BrokerRule) {
+	*out = *in
+	if in.Locations != nil {
+		in, out := &in.Locations, &out.Locations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerRule.
+func (in *BrokerRule) DeepCopy() *BrokerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BrokerSpec) DeepCopyInto(out *BrokerSpec) {
 	*out = *in
+	if in.Rule != nil {
+		in, out := &in.Rule, &out.Rule
+		*out = new(BrokerRule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metric != nil {
+		in, out := &in.Metric, &out.Metric
+		*out = new(BrokerMetric)
+		**out = **in
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(BrokerFilter)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerSpec.
@@ -116,8 +213,8 @@ func (in *KnownCluster) DeepCopyInto(out *KnownCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownCluster.
@@ -170,9 +267,29 @@ func (in *KnownClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnownClusterSource) DeepCopyInto(out *KnownClusterSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownClusterSource.
+func (in *KnownClusterSource) DeepCopy() *KnownClusterSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KnownClusterSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KnownClusterSpec) DeepCopyInto(out *KnownClusterSpec) {
 	*out = *in
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(KnownClusterSource)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownClusterSpec.
@@ -188,6 +305,11 @@ func (in *KnownClusterSpec) DeepCopy() *KnownClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KnownClusterStatus) DeepCopyInto(out *KnownClusterStatus) {
 	*out = *in
+	if in.GatewayRTT != nil {
+		in, out := &in.GatewayRTT, &out.GatewayRTT
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownClusterStatus.
@@ -14,10 +14,18 @@
 
 package v1alpha1
 
-import "github.com/fluidos-project/node/pkg/utils/tools"
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluidos-project/node/pkg/utils/tools"
+)
 
 // SetStatus sets the status of the allocation.
 func (allocation *Allocation) SetStatus(status Status, msg string) {
+	if status == Released && allocation.Status.Status != Released {
+		allocation.Status.ReleaseTime = tools.GetTimeNow()
+	}
 	allocation.Status.Status = status
 	allocation.Status.LastUpdateTime = tools.GetTimeNow()
 	allocation.Status.Message = msg
@@ -28,3 +36,41 @@ func (allocation *Allocation) SetResourceRef(resourceRef GenericRef) {
 	allocation.Status.ResourceRef = resourceRef
 	allocation.Status.LastUpdateTime = tools.GetTimeNow()
 }
+
+// SetPeeringStep records the peering step reached by an allocation, and the time at which it has been reached.
+func (allocation *Allocation) SetPeeringStep(step PeeringStep, msg string) {
+	if allocation.Status.PeeringStep != step {
+		allocation.Status.PeeringStepTime = tools.GetTimeNow()
+	}
+	allocation.Status.PeeringStep = step
+	allocation.Status.LastUpdateTime = tools.GetTimeNow()
+	allocation.Status.Message = msg
+}
+
+// SetReleaseStep records the teardown step reached by a released allocation.
+func (allocation *Allocation) SetReleaseStep(step ReleaseStep, msg string) {
+	allocation.Status.ReleaseStep = step
+	allocation.Status.LastUpdateTime = tools.GetTimeNow()
+	allocation.Status.Message = msg
+}
+
+// SetDegraded sets the allocation as Degraded for the given reasons, or back to Active if there are none.
+// It returns true if the Degraded condition of the allocation has changed.
+func (allocation *Allocation) SetDegraded(reasons []string, msg string) bool {
+	condition := metav1.Condition{
+		Type:    AllocationDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Healthy",
+		Message: "Allocation is healthy",
+	}
+	if len(reasons) == 0 {
+		allocation.SetStatus(Active, msg)
+	} else {
+		allocation.SetStatus(Degraded, msg)
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Impaired"
+		condition.Message = "Allocation is impaired, see its degraded reasons"
+	}
+	allocation.Status.DegradedReasons = reasons
+	return meta.SetStatusCondition(&allocation.Status.Conditions, condition)
+}
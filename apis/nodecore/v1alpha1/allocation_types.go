@@ -28,10 +28,45 @@ const (
 	ResourceCreation Status = "ResourceCreation"
 	Peering          Status = "Peering"
 	Released         Status = "Released"
+	Degraded         Status = "Degraded"
 	Inactive         Status = "Inactive"
 	Error            Status = "Error"
 )
 
+// Allocation condition types.
+const (
+	// AllocationDegraded is true while an active allocation is impaired.
+	AllocationDegraded = "Degraded"
+)
+
+// ReleaseStep is the step reached by the teardown of a released allocation.
+type ReleaseStep string
+
+// ReleaseStep values, in the order in which they are performed.
+const (
+	ReleaseDrainingPods             ReleaseStep = "DrainingPods"
+	ReleaseRemovingResourceSlice    ReleaseStep = "RemovingResourceSlice"
+	ReleaseRemovingVirtualNode      ReleaseStep = "RemovingVirtualNode"
+	ReleaseRemovingNamespaceOffload ReleaseStep = "RemovingNamespaceOffloading"
+	ReleaseRemovingAuthentication   ReleaseStep = "RemovingAuthentication"
+	ReleaseRemovingNetworking       ReleaseStep = "RemovingNetworking"
+	ReleaseRemovingTenantNamespace  ReleaseStep = "RemovingTenantNamespace"
+	ReleaseRestoringFlavor          ReleaseStep = "RestoringFlavor"
+	ReleaseCompleted                ReleaseStep = "Completed"
+)
+
+// PeeringStep is the step reached by the peering of an allocation.
+type PeeringStep string
+
+// PeeringStep values, in the order in which they are performed.
+const (
+	PeeringEstablishingNetwork PeeringStep = "EstablishingNetwork"
+	PeeringAuthenticating      PeeringStep = "Authenticating"
+	PeeringRequestingResources PeeringStep = "RequestingResources"
+	PeeringWaitingVirtualNode  PeeringStep = "WaitingVirtualNode"
+	PeeringCompleted           PeeringStep = "Completed"
+)
+
 // AllocationSpec defines the desired state of Allocation.
 type AllocationSpec struct {
 	// This flag indicates if the allocation is a forwarding allocation
@@ -56,6 +91,27 @@ type AllocationStatus struct {
 
 	// Related resource of the allocation
 	ResourceRef GenericRef `json:"resourceRef,omitempty"`
+
+	// PeeringStep is the peering step the allocation is waiting on, or Completed once the peering is established
+	PeeringStep PeeringStep `json:"peeringStep,omitempty"`
+
+	// PeeringStepTime is the time at which the allocation reached its peering step
+	PeeringStepTime string `json:"peeringStepTime,omitempty"`
+
+	// ReleaseStep is the last teardown step reached once the allocation has been released
+	ReleaseStep ReleaseStep `json:"releaseStep,omitempty"`
+
+	// ReleaseTime is the time at which the allocation has been released
+	ReleaseTime string `json:"releaseTime,omitempty"`
+
+	// DegradedReasons lists why an active allocation is impaired, while it is Degraded
+	DegradedReasons []string `json:"degradedReasons,omitempty"`
+
+	// Conditions contains the conditions of the allocation, such as its health.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //nolint:lll // kubebuilder directives are too long, but they must be on the same line
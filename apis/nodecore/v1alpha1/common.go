@@ -80,6 +80,8 @@ type Configuration struct {
 type LiqoCredentials struct {
 	ClusterID  string `json:"liqoID"`
 	Kubeconfig string `json:"kubeconfig"`
+	// ExpirationTime is the time after which the token in the Kubeconfig is no longer valid.
+	ExpirationTime string `json:"expirationTime,omitempty"`
 }
 
 // ParseConfiguration parses the configuration data into the correct type.
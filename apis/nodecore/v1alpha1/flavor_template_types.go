@@ -0,0 +1,98 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FlavorTemplateSpec defines the offer of the K8Slice Flavors generated for the nodes selected by the FlavorTemplate.
+type FlavorTemplateSpec struct {
+	// NodeSelector selects the nodes whose Flavors are generated from the FlavorTemplate.
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+
+	// Priority of the FlavorTemplate. When several FlavorTemplates select a node, the one with the highest priority applies,
+	// and ties are broken by name.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// Partitionability is the partitioning policy of the Flavors, in place of the default one of the node.
+	// +optional
+	Partitionability *Partitionability `json:"partitionability,omitempty"`
+
+	// Price is the fixed price of the whole Flavors, in place of the rate card and of the default price.
+	// Their partitions and remainders are charged a share of it, by the mean of their CPU and memory ratios.
+	// +optional
+	Price *Price `json:"price,omitempty"`
+
+	// Availability is the availability of the Flavors. They are available by default.
+	// Sold Flavors and Flavors withdrawn while their nodes are unhealthy are never made available by the FlavorTemplate.
+	// +optional
+	Availability *bool `json:"availability,omitempty"`
+
+	// Properties of the Flavors, in place of the configured ones.
+	// +optional
+	Properties *FlavorTemplateProperties `json:"properties,omitempty"`
+
+	// NetworkPropertyType is the network property type of the Flavors, in place of the configured one.
+	// +optional
+	NetworkPropertyType string `json:"networkPropertyType,omitempty"`
+
+	// Location is the location of the Flavors, in place of the configured one.
+	// +optional
+	Location *Location `json:"location,omitempty"`
+}
+
+// FlavorTemplateProperties represents the properties of the K8Slice Flavors generated from a FlavorTemplate.
+type FlavorTemplateProperties struct {
+	// Latency to reach the K8Slice Flavors
+	Latency int `json:"latency,omitempty"`
+	// Security standards complied by the K8Slice Flavors
+	SecurityStandards []string `json:"securityStandards,omitempty"`
+	// Carbon footprint of the K8Slice Flavors
+	CarbonFootprint *CarbonFootprint `json:"carbon-footprint,omitempty"`
+	// Network authorization policies of the K8Slice Flavors
+	NetworkAuthorizations *NetworkAuthorizations `json:"networkAuthorizations,omitempty"`
+	// AdditionalProperties represents the additional properties of the K8Slice Flavors.
+	AdditionalProperties map[string]runtime.RawExtension `json:"additionalProperties,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FlavorTemplate is the Schema for the flavortemplates API.
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Price",type=string,priority=1,JSONPath=`.spec.price.amount`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=ft
+type FlavorTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec FlavorTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// FlavorTemplateList contains a list of FlavorTemplate.
+type FlavorTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FlavorTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FlavorTemplate{}, &FlavorTemplateList{})
+}
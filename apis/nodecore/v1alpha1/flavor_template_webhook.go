@@ -0,0 +1,77 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var flavortemplatelog = logf.Log.WithName("flavortemplate-resource")
+
+// SetupWebhookWithManager setups the webhooks for the FlavorTemplate resource with the manager.
+func (r *FlavorTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&FlavorTemplate{}).
+		WithValidator(&FlavorTemplate{}).
+		Complete()
+}
+
+//nolint:lll // kubebuilder directives are too long, but they must be on the same line
+//+kubebuilder:webhook:path=/validate-nodecore-fluidos-eu-v1alpha1-flavortemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=nodecore.fluidos.eu,resources=flavortemplates,verbs=create;update,versions=v1alpha1,name=vflavortemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.CustomValidator = &FlavorTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (r *FlavorTemplate) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	flavorTemplate := obj.(*FlavorTemplate)
+	flavortemplatelog.Info("validate create", "name", flavorTemplate.Name)
+
+	return nil, validateFlavorTemplate(flavorTemplate)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (r *FlavorTemplate) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	_ = oldObj
+	flavorTemplate := newObj.(*FlavorTemplate)
+	flavortemplatelog.Info("validate update", "name", flavorTemplate.Name)
+
+	return nil, validateFlavorTemplate(flavorTemplate)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (r *FlavorTemplate) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	_ = ctx
+	_ = obj
+	return nil, nil
+}
+
+// validateFlavorTemplate checks that the node selector of a FlavorTemplate is a valid label selector.
+func validateFlavorTemplate(flavorTemplate *FlavorTemplate) error {
+	if _, err := metav1.LabelSelectorAsSelector(&flavorTemplate.Spec.NodeSelector); err != nil {
+		return fmt.Errorf("invalid node selector: %w", err)
+	}
+	return nil
+}
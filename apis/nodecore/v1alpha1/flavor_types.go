@@ -101,6 +101,12 @@ type FlavorSpec struct {
 	Location *Location `json:"location,omitempty"`
 }
 
+// Flavor condition types.
+const (
+	// FlavorAnnotationsValid is false when some annotations of the nodes of the Flavor are invalid, and have been ignored.
+	FlavorAnnotationsValid = "AnnotationsValid"
+)
+
 // FlavorStatus defines the observed state of Flavor.
 type FlavorStatus struct {
 
@@ -112,6 +118,15 @@ type FlavorStatus struct {
 
 	// This field represents the last update time of the Flavor.
 	LastUpdateTime string `json:"lastUpdateTime"`
+
+	// This field reports why the node of the Flavor cannot run new workloads. It is empty while the node is healthy and schedulable.
+	UnavailableReason string `json:"unavailableReason,omitempty"`
+
+	// Conditions contains the conditions of the Flavor, such as the validity of the annotations of its nodes.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -122,6 +137,7 @@ type FlavorStatus struct {
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.flavorType.typeIdentifier`
 // +kubebuilder:printcolumn:name="Owner Name",type=string,priority=1,JSONPath=`.spec.owner.nodeID`
 // +kubebuilder:printcolumn:name="Available",type=boolean,JSONPath=`.spec.availability`
+// +kubebuilder:printcolumn:name="Unavailable Reason",type=string,priority=1,JSONPath=`.status.unavailableReason`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:printcolumn:name="Kubernetes Node Owner",type=string,JSONPath=`.metadata.ownerReferences[0].name`
 // +kubebuilder:resource:shortName=fl
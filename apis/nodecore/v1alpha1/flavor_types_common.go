@@ -14,7 +14,10 @@
 
 package v1alpha1
 
-import "k8s.io/apimachinery/pkg/runtime"
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 // CarbonFootprint represents the carbon footprint of a Flavor.
 type CarbonFootprint struct {
@@ -30,6 +33,28 @@ type NetworkAuthorizations struct {
 	MandatoryCommunications []NetworkIntent `json:"mandatoryCommunications"`
 }
 
+// NodeLimits represents the resources available on a single node.
+type NodeLimits struct {
+	// CPU available on the node
+	CPU resource.Quantity `json:"cpu"`
+	// Memory available on the node
+	Memory resource.Quantity `json:"memory"`
+	// Pods available on the node
+	Pods resource.Quantity `json:"pods"`
+}
+
+// NodePool represents the pool of nodes aggregated by a Flavor.
+type NodePool struct {
+	// Label grouping the nodes of the pool
+	Label string `json:"label"`
+	// Value of the label shared by the nodes of the pool
+	Value string `json:"value"`
+	// Nodes aggregated by the Flavor
+	Nodes []string `json:"nodes"`
+	// PerNodeLimits are the largest resources available on a single node of the pool, which bound the resources of a single pod
+	PerNodeLimits NodeLimits `json:"perNodeLimits"`
+}
+
 // Properties represents the properties of a Flavor.
 type Properties struct {
 	// Latency to reach the K8Slice Flavor
@@ -42,4 +67,6 @@ type Properties struct {
 	NetworkAuthorizations *NetworkAuthorizations `json:"networkAuthorizations,omitempty"`
 	// AdditionalProperties represents the additional properties of the K8Slice Flavor.
 	AdditionalProperties map[string]runtime.RawExtension `json:"additionalProperties,omitempty"`
+	// NodePool describes the nodes aggregated by the K8Slice Flavor, when it advertises a pool of nodes
+	NodePool *NodePool `json:"nodePool,omitempty"`
 }
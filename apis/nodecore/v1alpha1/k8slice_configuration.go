@@ -15,6 +15,8 @@
 package v1alpha1
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -28,6 +30,94 @@ type K8SliceConfiguration struct {
 	Pods resource.Quantity `json:"pods"`
 	// Gpu is the GPU of the K8Slice partition.
 	Gpu *GPU `json:"gpu,omitempty"`
-	// Storage is the Storage of the K8Slice partition.
+	// Storage is the persistent Storage of the K8Slice partition.
 	Storage *resource.Quantity `json:"storage,omitempty"`
+	// StorageClass is the StorageClass the Storage of the K8Slice partition is bought from.
+	// When empty, the Storage can be claimed from any StorageClass of the Flavor.
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// Validate validates the K8SliceConfiguration over the partitionability of the given K8Slice Flavor,
+// checking that every resource is at least its minimum and is a whole number of steps above it.
+func (kc *K8SliceConfiguration) Validate(k8Slice *K8Slice) error {
+	partitionability := &k8Slice.Policies.Partitionability
+
+	checks := []struct {
+		name             string
+		value, min, step resource.Quantity
+	}{
+		{"cpu", kc.CPU, partitionability.CPUMin, partitionability.CPUStep},
+		{"memory", kc.Memory, partitionability.MemoryMin, partitionability.MemoryStep},
+		{"pods", kc.Pods, partitionability.PodsMin, partitionability.PodsStep},
+	}
+	for _, c := range checks {
+		if c.value.Sign() <= 0 {
+			return fmt.Errorf("%s must be positive", c.name)
+		}
+		if c.value.Cmp(c.min) < 0 {
+			return fmt.Errorf("%s %s is below the minimum %s", c.name, c.value.String(), c.min.String())
+		}
+		if c.step.Sign() > 0 {
+			above := c.value.DeepCopy()
+			above.Sub(c.min)
+			if above.MilliValue()%c.step.MilliValue() != 0 {
+				return fmt.Errorf("%s %s is not a multiple of %s above the minimum %s", c.name, c.value.String(), c.step.String(), c.min.String())
+			}
+		}
+	}
+
+	if err := kc.ValidateStorage(k8Slice); err != nil {
+		return err
+	}
+	return kc.ValidateGPU(k8Slice)
+}
+
+// ValidateStorage validates the persistent Storage of the K8SliceConfiguration over the one offered by the given K8Slice Flavor,
+// through its StorageClass when set.
+func (kc *K8SliceConfiguration) ValidateStorage(k8Slice *K8Slice) error {
+	if kc.Storage == nil {
+		if kc.StorageClass != "" {
+			return fmt.Errorf("storage class %s requires a storage size", kc.StorageClass)
+		}
+		return nil
+	}
+	if kc.Storage.Sign() < 0 {
+		return fmt.Errorf("storage must not be negative")
+	}
+
+	var available resource.Quantity
+	if kc.StorageClass != "" {
+		capacity, found := k8Slice.Characteristics.StorageClassCapacity(kc.StorageClass)
+		if !found {
+			return fmt.Errorf("the flavor does not offer storage class %s", kc.StorageClass)
+		}
+		available = capacity
+	} else if k8Slice.Characteristics.Storage != nil {
+		available = *k8Slice.Characteristics.Storage
+	}
+	if kc.Storage.Cmp(available) > 0 {
+		return fmt.Errorf("storage %s exceeds the %s available", kc.Storage.String(), available.String())
+	}
+	return nil
+}
+
+// ValidateGPU validates the GPUs and MIG instances of the K8SliceConfiguration over the ones available on the given K8Slice Flavor.
+func (kc *K8SliceConfiguration) ValidateGPU(k8Slice *K8Slice) error {
+	if kc.Gpu == nil {
+		return nil
+	}
+	available := k8Slice.Characteristics.Gpu
+	if available == nil {
+		return fmt.Errorf("the flavor has no GPU")
+	}
+	if kc.Gpu.Count < 0 || kc.Gpu.Count > available.Count {
+		return fmt.Errorf("gpu count %d is not within the %d GPUs available", kc.Gpu.Count, available.Count)
+	}
+	for _, profile := range kc.Gpu.MIGProfiles {
+		if profile.Count < 0 || profile.Count > available.MIGCount(profile.Name) {
+			return fmt.Errorf("mig profile %s count %d is not within the %d instances available",
+				profile.Name, profile.Count, available.MIGCount(profile.Name))
+		}
+	}
+	return nil
 }
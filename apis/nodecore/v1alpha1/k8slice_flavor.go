@@ -48,8 +48,28 @@ type K8SliceCharacteristics struct {
 	Pods resource.Quantity `json:"pods"`
 	// GPU contains the GPU traits of the K8Slice Flavor.
 	Gpu *GPU `json:"gpu,omitempty"`
-	// Storage is the amount of storage offered by this K8Slice Flavor.
+	// Storage is the amount of persistent storage offered by this K8Slice Flavor, across all its StorageClasses.
 	Storage *resource.Quantity `json:"storage,omitempty"`
+	// StorageClasses lists the persistent storage offered by this K8Slice Flavor through each StorageClass.
+	StorageClasses []StorageClassCapacity `json:"storageClasses,omitempty"`
+}
+
+// StorageClassCapacity represents the persistent storage offered through a StorageClass.
+type StorageClassCapacity struct {
+	// Name of the StorageClass
+	Name string `json:"name"`
+	// Capacity offered through the StorageClass
+	Capacity resource.Quantity `json:"capacity"`
+}
+
+// StorageClassCapacity returns the persistent storage offered through the given StorageClass, and whether it is offered.
+func (kc *K8SliceCharacteristics) StorageClassCapacity(name string) (resource.Quantity, bool) {
+	for _, class := range kc.StorageClasses {
+		if class.Name == name {
+			return class.Capacity, true
+		}
+	}
+	return resource.Quantity{}, false
 }
 
 // GPU represents the GPU characteristics of a K8Slice Flavor.
@@ -91,6 +111,26 @@ type GPU struct {
 	HourlyRate            float64           `json:"hourly_rate,omitempty"`
 	Provider              string            `json:"provider,omitempty"`
 	PreEmptible           bool              `json:"pre_emptible,omitempty"`
+	// MIGProfiles lists the Multi-Instance GPU profiles available, each advertised as a distinct partitionable unit
+	MIGProfiles []MIGProfile `json:"mig_profiles,omitempty"`
+}
+
+// MIGCount returns the instances of the given Multi-Instance GPU profile available on the GPU.
+func (gpu *GPU) MIGCount(name string) int64 {
+	for _, profile := range gpu.MIGProfiles {
+		if profile.Name == name {
+			return profile.Count
+		}
+	}
+	return 0
+}
+
+// MIGProfile represents the instances of a Multi-Instance GPU profile (e.g., 1g.5gb) available on a K8Slice Flavor.
+type MIGProfile struct {
+	// Name of the profile
+	Name string `json:"name"`
+	// Count of the instances of the profile
+	Count int64 `json:"count"`
 }
 
 // Policies represents the policies of a K8Slice Flavor, such as the partitionability of the K8Slice Flavor.
@@ -35,6 +35,10 @@ type K8SliceSelector struct {
 	// StorageFilter is the Storage filter of the K8SliceSelector.
 	StorageFilter *ResourceQuantityFilter `json:"storageFilter,omitempty"`
 
+	// StorageClassFilter is the StorageClass filter of the K8SliceSelector.
+	// When set, the Storage filter applies to the storage offered through the matching StorageClasses.
+	StorageClassFilter *StringFilter `json:"storageClassFilter,omitempty"`
+
 	//GPUFilters is the advanced GPU filter of the K8SliceSelector
 	GPUFilters []GPUFieldSelector `json:"gpuFilters,omitempty"`
 }
@@ -92,6 +96,15 @@ func ParseK8SliceSelector(k8SliceSelector *K8SliceSelector) (map[FilterType]inte
 		}
 		filters[storageFilterType] = storageFilterData
 	}
+	if k8SliceSelector.StorageClassFilter != nil {
+		klog.Info("Parsing StorageClass filter")
+		// Parse StorageClass filter
+		storageClassFilterType, storageClassFilterData, err := ParseStringFilter(k8SliceSelector.StorageClassFilter)
+		if err != nil {
+			return nil, err
+		}
+		filters[storageClassFilterType] = storageClassFilterData
+	}
 
 	return filters, nil
 }
@@ -19,6 +19,7 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -28,7 +29,7 @@ func (in *Allocation) DeepCopyInto(out *Allocation) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Allocation.
@@ -101,6 +102,18 @@ func (in *AllocationSpec) DeepCopy() *AllocationSpec {
 func (in *AllocationStatus) DeepCopyInto(out *AllocationStatus) {
 	*out = *in
 	out.ResourceRef = in.ResourceRef
+	if in.DegradedReasons != nil {
+		in, out := &in.DegradedReasons, &out.DegradedReasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllocationStatus.
@@ -171,7 +184,7 @@ func (in *Flavor) DeepCopyInto(out *Flavor) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flavor.
@@ -250,6 +263,13 @@ func (in *FlavorSpec) DeepCopy() *FlavorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FlavorStatus) DeepCopyInto(out *FlavorStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorStatus.
@@ -262,6 +282,142 @@ func (in *FlavorStatus) DeepCopy() *FlavorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlavorTemplate) DeepCopyInto(out *FlavorTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorTemplate.
+func (in *FlavorTemplate) DeepCopy() *FlavorTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(FlavorTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlavorTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlavorTemplateList) DeepCopyInto(out *FlavorTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FlavorTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorTemplateList.
+func (in *FlavorTemplateList) DeepCopy() *FlavorTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(FlavorTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FlavorTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlavorTemplateProperties) DeepCopyInto(out *FlavorTemplateProperties) {
+	*out = *in
+	if in.SecurityStandards != nil {
+		in, out := &in.SecurityStandards, &out.SecurityStandards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CarbonFootprint != nil {
+		in, out := &in.CarbonFootprint, &out.CarbonFootprint
+		*out = new(CarbonFootprint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkAuthorizations != nil {
+		in, out := &in.NetworkAuthorizations, &out.NetworkAuthorizations
+		*out = new(NetworkAuthorizations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalProperties != nil {
+		in, out := &in.AdditionalProperties, &out.AdditionalProperties
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorTemplateProperties.
+func (in *FlavorTemplateProperties) DeepCopy() *FlavorTemplateProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(FlavorTemplateProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlavorTemplateSpec) DeepCopyInto(out *FlavorTemplateSpec) {
+	*out = *in
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.Partitionability != nil {
+		in, out := &in.Partitionability, &out.Partitionability
+		*out = new(Partitionability)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Price != nil {
+		in, out := &in.Price, &out.Price
+		*out = new(Price)
+		**out = **in
+	}
+	if in.Availability != nil {
+		in, out := &in.Availability, &out.Availability
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = new(FlavorTemplateProperties)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Location != nil {
+		in, out := &in.Location, &out.Location
+		*out = new(Location)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlavorTemplateSpec.
+func (in *FlavorTemplateSpec) DeepCopy() *FlavorTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FlavorTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FlavorType) DeepCopyInto(out *FlavorType) {
 	*out = *in
@@ -286,6 +442,11 @@ func (in *GPU) DeepCopyInto(out *GPU) {
 	out.NetworkBandwidth = in.NetworkBandwidth.DeepCopy()
 	out.InterconnectBandwidth = in.InterconnectBandwidth.DeepCopy()
 	out.ClockSpeed = in.ClockSpeed.DeepCopy()
+	if in.MIGProfiles != nil {
+		in, out := &in.MIGProfiles, &out.MIGProfiles
+		*out = make([]MIGProfile, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPU.
@@ -363,6 +524,13 @@ func (in *K8SliceCharacteristics) DeepCopyInto(out *K8SliceCharacteristics) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]StorageClassCapacity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new K8SliceCharacteristics.
@@ -431,6 +599,11 @@ func (in *K8SliceSelector) DeepCopyInto(out *K8SliceSelector) {
 		*out = new(ResourceQuantityFilter)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StorageClassFilter != nil {
+		in, out := &in.StorageClassFilter, &out.StorageClassFilter
+		*out = new(StringFilter)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.GPUFilters != nil {
 		in, out := &in.GPUFilters, &out.GPUFilters
 		*out = make([]GPUFieldSelector, len(*in))
@@ -480,6 +653,21 @@ func (in *Location) DeepCopy() *Location {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MIGProfile) DeepCopyInto(out *MIGProfile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MIGProfile.
+func (in *MIGProfile) DeepCopy() *MIGProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(MIGProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkAuthorizations) DeepCopyInto(out *NetworkAuthorizations) {
 	*out = *in
@@ -561,6 +749,45 @@ func (in *NodeIdentityAdditionalInfo) DeepCopy() *NodeIdentityAdditionalInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLimits) DeepCopyInto(out *NodeLimits) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+	out.Pods = in.Pods.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLimits.
+func (in *NodeLimits) DeepCopy() *NodeLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePool) DeepCopyInto(out *NodePool) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.PerNodeLimits.DeepCopyInto(&out.PerNodeLimits)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
+func (in *NodePool) DeepCopy() *NodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NumberFilter) DeepCopyInto(out *NumberFilter) {
 	*out = *in
@@ -740,6 +967,11 @@ func (in *Properties) DeepCopyInto(out *Properties) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.NodePool != nil {
+		in, out := &in.NodePool, &out.NodePool
+		*out = new(NodePool)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Properties.
@@ -1172,6 +1404,22 @@ func (in *SourceDestination) DeepCopy() *SourceDestination {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClassCapacity) DeepCopyInto(out *StorageClassCapacity) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClassCapacity.
+func (in *StorageClassCapacity) DeepCopy() *StorageClassCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClassCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StringFilter) DeepCopyInto(out *StringFilter) {
 	*out = *in
@@ -0,0 +1,49 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+// K8SlicePartition returns the partition of the K8Slice Flavor bought by the Contract.
+// When the Contract has no configuration, the whole Flavor has been bought.
+func (contract *Contract) K8SlicePartition() (*nodecorev1alpha1.K8SliceConfiguration, error) {
+	if contract.Spec.Configuration != nil {
+		configurationTypeIdentifier, configurationData, err := nodecorev1alpha1.ParseConfiguration(contract.Spec.Configuration, &contract.Spec.Flavor)
+		if err != nil {
+			return nil, err
+		}
+		if configurationTypeIdentifier != nodecorev1alpha1.TypeK8Slice {
+			return nil, fmt.Errorf("configuration of contract %s is not a K8Slice type", contract.Name)
+		}
+		k8SliceConfiguration := configurationData.(nodecorev1alpha1.K8SliceConfiguration)
+		return &k8SliceConfiguration, nil
+	}
+
+	k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(contract.Spec.Flavor.Spec.FlavorType)
+	if err != nil {
+		return nil, err
+	}
+	return &nodecorev1alpha1.K8SliceConfiguration{
+		CPU:     k8Slice.Characteristics.CPU,
+		Memory:  k8Slice.Characteristics.Memory,
+		Pods:    k8Slice.Characteristics.Pods,
+		Gpu:     k8Slice.Characteristics.Gpu,
+		Storage: k8Slice.Characteristics.Storage,
+	}, nil
+}
@@ -57,11 +57,25 @@ type ContractSpec struct {
 	IngressTelemetryEndpoint *TelemetryServer `json:"ingressTelemetryEndpoint,omitempty"`
 }
 
+// Contract condition types.
+const (
+	// ContractExpiring is true when the contract is about to expire and should be renewed.
+	ContractExpiring = "Expiring"
+	// ContractExpired is true once the contract has expired and its Allocation has been released.
+	ContractExpired = "Expired"
+)
+
 // ContractStatus defines the observed state of Contract.
 type ContractStatus struct {
 
 	// This is the status of the contract.
 	Phase nodecorev1alpha1.PhaseStatus `json:"phase"`
+
+	// Conditions contains the conditions of the contract lifecycle, such as its expiration.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -36,6 +36,9 @@ type TransactionSpec struct {
 
 	// ExpirationTime is the time when the reservation will expire
 	ExpirationTime string `json:"expirationTime,omitempty"`
+
+	// Price is the price of the partition reserved, as set by the seller
+	Price *nodecorev1alpha1.Price `json:"price,omitempty"`
 }
 
 // TransactionStatus defines the observed state of Transaction.
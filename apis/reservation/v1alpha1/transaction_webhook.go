@@ -16,6 +16,7 @@ package v1alpha1
 
 import (
 	"context"
+	"fmt"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -25,6 +26,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	advertisementv1alpha1 "github.com/fluidos-project/node/apis/advertisement/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 )
 
 // log is for logging in this package.
@@ -139,6 +141,23 @@ func validateTransaction(transaction *Transaction) error {
 		}
 	}
 
-	// Validate the Configuration
-	return validateConfiguration(transaction.Spec.Configuration, &peeringCandidate.Spec.Flavor)
+	if peeringCandidate != nil {
+		// Validate the Configuration
+		return validateConfiguration(transaction.Spec.Configuration, &peeringCandidate.Spec.Flavor)
+	}
+
+	// Without a PeeringCandidate, the Transaction is on a Flavor sold by this node
+	flavors := &nodecorev1alpha1.FlavorList{}
+	if err := k8sClientTransaction.List(ctxTransaction, flavors, client.InNamespace(transaction.Namespace)); err != nil {
+		transactionlog.Error(err, "Error when listing Flavors")
+		return err
+	}
+	for i := range flavors.Items {
+		if flavors.Items[i].Name == transaction.Spec.FlavorID {
+			// Validate the Configuration
+			return validateConfiguration(transaction.Spec.Configuration, &flavors.Items[i])
+		}
+	}
+
+	return fmt.Errorf("no PeeringCandidate or Flavor found for Flavor %s", transaction.Spec.FlavorID)
 }
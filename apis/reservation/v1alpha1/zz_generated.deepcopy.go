@@ -20,6 +20,7 @@ package v1alpha1
 
 import (
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -29,7 +30,7 @@ func (in *Contract) DeepCopyInto(out *Contract) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Contract.
@@ -122,6 +123,13 @@ func (in *ContractSpec) DeepCopy() *ContractSpec {
 func (in *ContractStatus) DeepCopyInto(out *ContractStatus) {
 	*out = *in
 	out.Phase = in.Phase
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContractStatus.
@@ -326,6 +334,11 @@ func (in *TransactionSpec) DeepCopyInto(out *TransactionSpec) {
 		*out = new(nodecorev1alpha1.Configuration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Price != nil {
+		in, out := &in.Price, &out.Price
+		*out = new(nodecorev1alpha1.Price)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransactionSpec.
@@ -16,6 +16,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	"github.com/fluidos-project/node/pkg/indexer"
 	localresourcemanager "github.com/fluidos-project/node/pkg/local-resource-manager"
 	"github.com/fluidos-project/node/pkg/utils/flags"
@@ -44,6 +46,7 @@ func init() {
 	utilruntime.Must(metricsv1beta1.AddToScheme(scheme))
 	utilruntime.Must(corev1.AddToScheme(scheme))
 	utilruntime.Must(nodecorev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(reservationv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -65,8 +68,14 @@ func main() {
 	flag.StringVar(&flags.PodsStep, "pods-step", "0", "Pods step value")
 	flag.Int64Var(&flags.MinCount, "min-count", 0, "Minimum number of flavors")
 	flag.Int64Var(&flags.MaxCount, "max-count", 0, "Maximum number of flavors")
+	flag.StringVar(&flags.CapacityMode, "capacity-mode", localresourcemanager.CapacityModeRequests,
+		"How the resources in use on a node are accounted: requests, limits or usage")
+	flag.Int64Var(&flags.CapacityChangeThreshold, "capacity-change-threshold", 10,
+		"Change of the node capacity, in percent, above which its Flavor is updated")
 	flag.StringVar(&flags.ResourceNodeLabel, "node-resource-label", "node-role.fluidos.eu/resources",
 		"Label used to filter the k8s nodes from which create flavors")
+	flag.StringVar(&flags.NodePoolLabel, "node-pool-label", "",
+		"Label grouping the k8s nodes into pools advertised by a single flavor (one flavor per node if empty)")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -82,6 +91,13 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	switch flags.CapacityMode {
+	case localresourcemanager.CapacityModeRequests, localresourcemanager.CapacityModeLimits, localresourcemanager.CapacityModeUsage:
+	default:
+		setupLog.Error(fmt.Errorf("unknown capacity mode %q", flags.CapacityMode), "invalid flag", "flag", "capacity-mode")
+		os.Exit(1)
+	}
+
 	var webhookServer webhook.Server
 
 	if *enableWH {
@@ -109,6 +125,7 @@ func main() {
 	setupLog.Info("Manager started", "manager", mgr)
 
 	flavorIndexer := indexer.FlavorByNodeName{}
+	podIndexer := indexer.PodByNodeName{}
 
 	// Register the controller
 	if err = (&localresourcemanager.NodeReconciler{
@@ -117,6 +134,8 @@ func main() {
 		EnableAutoDiscovery: *enableAutoDiscovery,
 		WebhookServer:       webhookServer,
 		FlavorIndexer:       flavorIndexer,
+		PodIndexer:          podIndexer,
+		Recorder:            mgr.GetEventRecorderFor("node-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Node")
 		os.Exit(1)
@@ -144,6 +163,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ServiceBlueprint")
 			os.Exit(1)
 		}
+		if err = (&nodecorev1alpha1.FlavorTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "FlavorTemplate")
+			os.Exit(1)
+		}
 	} else {
 		setupLog.Info("Webhooks are disabled")
 	}
@@ -170,6 +193,10 @@ func main() {
 		setupLog.Error(err, "problem setting up flavorIndexer")
 		os.Exit(1)
 	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, podIndexer.Object(), podIndexer.Field(), podIndexer.IndexerFunc()); err != nil {
+		setupLog.Error(err, "problem setting up podIndexer")
+		os.Exit(1)
+	}
 
 	setupLog.Info("Starting manager")
 	if err := mgr.Start(ctx); err != nil {
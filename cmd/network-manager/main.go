@@ -18,6 +18,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,6 +28,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
@@ -56,6 +58,10 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	enableLocalDiscovery := flag.Bool("enable-local-discovery", true, "Enable discovery of other clusters on same LAN")
 	cniInterface := flag.String("cniInterface", "", "Name of the CNI virtual interface")
+	discoveryTransports := flag.String("discovery-transports", "multicast,amqp",
+		"Comma separated list of the discovery transports to enable (multicast, amqp, mdns)")
+	enableWH := flag.Bool("enable-webhooks", true, "Enable webhooks server")
+	staticPeers := flag.String("static-peers", "", "Comma separated list of static peers in the nodeID=address format")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -72,11 +78,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	var webhookServer webhook.Server
+
+	if *enableWH {
+		webhookServer = webhook.NewServer(webhook.Options{Port: 9443})
+	} else {
+		setupLog.Info("Webhooks are disabled")
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
+		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "a0b0c1d1.fluidos.eu",
@@ -89,8 +104,20 @@ func main() {
 	// Print something about the mgr
 	setupLog.Info("Manager started", "manager", mgr)
 
+	peers, err := networkmanager.ParseStaticPeers(*staticPeers)
+	if err != nil {
+		setupLog.Error(err, "Unable to parse static peers")
+		os.Exit(1)
+	}
+
+	transports, err := networkmanager.ParseTransports(*discoveryTransports)
+	if err != nil {
+		setupLog.Error(err, "Unable to parse discovery transports")
+		os.Exit(1)
+	}
+
 	// Buffer for clusters multicast messages
-	nm := &networkmanager.NetworkManager{EnableLocalDiscovery: *enableLocalDiscovery}
+	nm := &networkmanager.NetworkManager{EnableLocalDiscovery: *enableLocalDiscovery, StaticPeers: peers, Transports: transports}
 
 	// Start the NetworkManager setup
 	if err := networkmanager.Setup(context.Background(), cl, nm, cniInterface); err != nil {
@@ -104,13 +131,24 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Register the controller
-	if err = (&networkmanager.BrokerReconciler{
-		Client: cl,
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Broker")
-		os.Exit(1)
+	// Register the controller, which runs the AMQP transports
+	if slices.Contains(transports, networkmanager.TransportAMQP) {
+		if err = (&networkmanager.BrokerReconciler{
+			Client:    cl,
+			Scheme:    mgr.GetScheme(),
+			Registrar: nm.Registrar,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Broker")
+			os.Exit(1)
+		}
+	}
+
+	// Register the Broker webhook, as the Brokers are reconciled by the NetworkManager
+	if *enableWH {
+		if err = (&networkv1alpha1.Broker{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Broker")
+			os.Exit(1)
+		}
 	}
 
 	// Register health checks
@@ -122,6 +160,12 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if *enableWH {
+		if err := mgr.AddHealthzCheck("webhook", webhookServer.StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up webhook health check")
+			os.Exit(1)
+		}
+	}
 
 	// Start the NetworkManager reconcile
 	setupLog.Info("Starting manager")
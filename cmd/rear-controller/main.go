@@ -174,7 +174,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	gw := gateway.NewGateway(mgr.GetClient(), mgr.GetConfig())
+	gw := gateway.NewGateway(mgr.GetClient(), mgr.GetConfig(), mgr.GetEventRecorderFor("rear-gateway"))
 
 	if err = (&discoverymanager.DiscoveryReconciler{
 		Client:  mgr.GetClient(),
@@ -195,6 +195,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&contractmanager.ContractReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Gateway:  gw,
+		Recorder: mgr.GetEventRecorderFor("contract-manager"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Contract")
+		os.Exit(1)
+	}
+
 	if *enableWH {
 		// Register Reservation webhook
 		setupLog.Info("Registering webhooks to the manager")
@@ -244,6 +254,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Periodically rotate the Liqo credentials of the contracts bought by this node
+	if err := mgr.Add(manager.RunnableFunc(gw.CredentialsRefresher(flags.CredentialsInterval))); err != nil {
+		klog.Errorf("Unable to set up credentials refresher: %s", err)
+		os.Exit(1)
+	}
+
 	// Start the REAR Gateway HTTP server
 	if err := mgr.Add(manager.RunnableFunc(gw.Start)); err != nil {
 		klog.Errorf("Unable to set up Gateway HTTP server: %s", err)
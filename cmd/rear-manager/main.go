@@ -24,6 +24,7 @@ import (
 	liqoipam "github.com/liqotech/liqo/apis/ipam/v1alpha1"
 	liqonetworking "github.com/liqotech/liqo/apis/networking/v1beta1"
 	liqooffloading "github.com/liqotech/liqo/apis/offloading/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -39,6 +40,7 @@ import (
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	rearmanager "github.com/fluidos-project/node/pkg/rear-manager"
+	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
 )
 
 var (
@@ -113,6 +115,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Index the node of the Pods, to drain the VirtualNodes when releasing the Allocations
+	indexFuncPodNode := func(obj client.Object) []string {
+		pod := obj.(*corev1.Pod)
+		return []string{pod.Spec.NodeName}
+	}
+
+	if err := cache.IndexField(context.Background(), &corev1.Pod{}, virtualfabricmanager.PodNodeNameField, indexFuncPodNode); err != nil {
+		setupLog.Error(err, "unable to create index for field", "field", virtualfabricmanager.PodNodeNameField)
+		os.Exit(1)
+	}
+
 	if err = (&rearmanager.SolverReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -126,6 +139,7 @@ func main() {
 		RestConfig: mgr.GetConfig(),
 		Scheme:     mgr.GetScheme(),
 		Manager:    mgr,
+		Recorder:   mgr.GetEventRecorderFor("allocation-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Allocation")
 		os.Exit(1)
@@ -138,6 +152,9 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Flavor")
 			os.Exit(1)
 		}
+		// Register Allocation webhooks
+		mgr.GetWebhookServer().Register(rearmanager.AllocationMutatingPath, &webhook.Admission{Handler: rearmanager.NewMutator(mgr.GetClient())})
+		mgr.GetWebhookServer().Register(rearmanager.AllocationValidatingPath, &webhook.Admission{Handler: rearmanager.NewValidator(mgr.GetClient(), mgr.GetAPIReader())})
 	} else {
 		setupLog.Info("Webhooks are disabled")
 	}
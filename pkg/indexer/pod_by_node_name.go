@@ -0,0 +1,43 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package indexer
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodByNodeName is a controller-runtime Manager indexer returning
+// a list of corev1.Pod by the name of the node they are scheduled on.
+type PodByNodeName struct{}
+
+func (PodByNodeName) Object() client.Object {
+	return &corev1.Pod{}
+}
+
+func (PodByNodeName) Field() string {
+	return "spec.nodeName"
+}
+
+func (PodByNodeName) IndexerFunc() client.IndexerFunc {
+	return func(obj client.Object) []string {
+		pod := obj.(*corev1.Pod)
+		if pod.Spec.NodeName == "" {
+			return nil
+		}
+
+		return []string{pod.Spec.NodeName}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/parseutil"
+)
+
+// flavorTemplate returns the FlavorTemplate applying to the Flavor of a node, or nil if no FlavorTemplate selects it.
+// When several FlavorTemplates select the node, the one with the highest priority applies, and ties are broken by name.
+// A FlavorTemplate with an invalid node selector, which the validating webhook rejects, is ignored.
+func (r *NodeReconciler) flavorTemplate(ctx context.Context, node client.Object) (*nodecorev1alpha1.FlavorTemplate, error) {
+	var templates nodecorev1alpha1.FlavorTemplateList
+	if err := r.Client.List(ctx, &templates, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		return nil, err
+	}
+
+	var selected *nodecorev1alpha1.FlavorTemplate
+	for i := range templates.Items {
+		template := &templates.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&template.Spec.NodeSelector)
+		if err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Info("FlavorTemplate ignored, its node selector is invalid", "template", template.Name, "error", err.Error())
+			continue
+		}
+		if !selector.Matches(labels.Set(node.GetLabels())) {
+			continue
+		}
+		if selected == nil || template.Spec.Priority > selected.Spec.Priority ||
+			(template.Spec.Priority == selected.Spec.Priority && template.Name < selected.Name) {
+			selected = template
+		}
+	}
+	return selected, nil
+}
+
+// templatePartitionability returns the partitioning policy of the FlavorTemplate, if any, or the one configured through the flags.
+func templatePartitionability(template *nodecorev1alpha1.FlavorTemplate) nodecorev1alpha1.Partitionability {
+	if template != nil && template.Spec.Partitionability != nil {
+		return *template.Spec.Partitionability
+	}
+	return nodecorev1alpha1.Partitionability{
+		CPUMin:     parseutil.ParseQuantityFromString(flags.CPUMin),
+		MemoryMin:  parseutil.ParseQuantityFromString(flags.MemoryMin),
+		PodsMin:    parseutil.ParseQuantityFromString(flags.PodsMin),
+		CPUStep:    parseutil.ParseQuantityFromString(flags.CPUStep),
+		MemoryStep: parseutil.ParseQuantityFromString(flags.MemoryStep),
+		PodsStep:   parseutil.ParseQuantityFromString(flags.PodsStep),
+	}
+}
+
+// applyTemplateProperties sets the properties of a K8Slice Flavor from its FlavorTemplate, if any.
+// The latency and security standards of the template take the place of the configured ones, when set.
+func applyTemplateProperties(properties *nodecorev1alpha1.Properties, template *nodecorev1alpha1.FlavorTemplate) {
+	properties.CarbonFootprint = nil
+	properties.NetworkAuthorizations = nil
+	properties.AdditionalProperties = nil
+	if template == nil || template.Spec.Properties == nil {
+		return
+	}
+
+	templateProperties := template.Spec.Properties
+	if templateProperties.Latency != 0 {
+		properties.Latency = templateProperties.Latency
+	}
+	if len(templateProperties.SecurityStandards) > 0 {
+		properties.SecurityStandards = templateProperties.SecurityStandards
+	}
+	properties.CarbonFootprint = templateProperties.CarbonFootprint
+	properties.NetworkAuthorizations = templateProperties.NetworkAuthorizations
+	properties.AdditionalProperties = templateProperties.AdditionalProperties
+}
+
+// templatePrice returns the fixed price set by a FlavorTemplate, if any.
+func templatePrice(template *nodecorev1alpha1.FlavorTemplate) *nodecorev1alpha1.Price {
+	if template == nil {
+		return nil
+	}
+	return template.Spec.Price
+}
+
+// applyTemplateAvailability makes a Flavor unavailable while its FlavorTemplate states so, and available again afterwards.
+// The Flavors disabled by their template are annotated, to tell them apart from the sold ones, which are left untouched.
+// A Flavor withdrawn while its node is unhealthy is only annotated, and its availability is restored when the node recovers.
+func applyTemplateAvailability(flavor *nodecorev1alpha1.Flavor, template *nodecorev1alpha1.FlavorTemplate) {
+	if isSold(flavor) {
+		return
+	}
+	_, withdrawn := flavor.Annotations[consts.FluidosFlavorWithdrawn]
+
+	if template != nil && template.Spec.Availability != nil && !*template.Spec.Availability {
+		if flavor.Annotations == nil {
+			flavor.Annotations = map[string]string{}
+		}
+		flavor.Annotations[consts.FluidosFlavorDisabled] = "true"
+		flavor.Spec.Availability = false
+		return
+	}
+	if _, disabled := flavor.Annotations[consts.FluidosFlavorDisabled]; disabled {
+		delete(flavor.Annotations, consts.FluidosFlavorDisabled)
+		flavor.Spec.Availability = !withdrawn
+	}
+}
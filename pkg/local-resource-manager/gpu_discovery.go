@@ -0,0 +1,192 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/models"
+)
+
+// Labels set by the NVIDIA GPU Feature Discovery and the AMD node labeller.
+const (
+	nvidiaProductLabel         = "nvidia.com/gpu.product"
+	nvidiaMemoryLabel          = "nvidia.com/gpu.memory"
+	nvidiaFamilyLabel          = "nvidia.com/gpu.family"
+	nvidiaComputeMajorLabel    = "nvidia.com/gpu.compute.major"
+	nvidiaComputeMinorLabel    = "nvidia.com/gpu.compute.minor"
+	nvidiaMIGCapableLabel      = "nvidia.com/mig.capable"
+	nvidiaSharingStrategyLabel = "nvidia.com/gpu.sharing-strategy"
+	amdProductLabel            = "amd.com/gpu.product-name"
+	amdMemoryLabel             = "amd.com/gpu.vram"
+	amdFamilyLabel             = "amd.com/gpu.family"
+)
+
+// nfdVendorLabels maps the PCI labels set by the Node Feature Discovery for display and 3D controllers to the GPU vendors.
+var nfdVendorLabels = map[string]string{
+	"feature.node.kubernetes.io/pci-0300_10de.present": "nvidia",
+	"feature.node.kubernetes.io/pci-0302_10de.present": "nvidia",
+	"feature.node.kubernetes.io/pci-0300_1002.present": "amd",
+	"feature.node.kubernetes.io/pci-0380_1002.present": "amd",
+	"feature.node.kubernetes.io/pci-0300_8086.present": "intel",
+}
+
+// discoverGPU detects the GPUs of a node from the extended resources of the device plugins and the labels of the
+// GPU Feature Discovery and Node Feature Discovery. The GPUs and MIG instances available are the allocatable ones
+// minus the ones requested by the pods scheduled on the node, except for the pods offloaded through FLUIDOS contracts.
+func discoverGPU(node *corev1.Node, pods []corev1.Pod) models.GPUMetrics {
+	var gpu models.GPUMetrics
+	var allocatableCount int64
+	requested := gpuRequests(pods)
+
+	// A node is expected to expose the GPUs of a single vendor: the first one found, in a stable order, is advertised
+	vendors := make([]string, 0, len(consts.GPUResources))
+	for vendor := range consts.GPUResources {
+		vendors = append(vendors, vendor)
+	}
+	sort.Strings(vendors)
+	for _, vendor := range vendors {
+		name := corev1.ResourceName(consts.GPUResources[vendor])
+		if allocatable, ok := node.Status.Allocatable[name]; ok && allocatable.Value() > 0 {
+			gpu.Vendor = vendor
+			gpu.Count = available(allocatable, requested[name])
+			allocatableCount = allocatable.Value()
+			break
+		}
+	}
+
+	for name, allocatable := range node.Status.Allocatable {
+		profile, ok := strings.CutPrefix(string(name), consts.NvidiaMIGResourcePrefix)
+		if !ok || allocatable.Value() <= 0 {
+			continue
+		}
+		gpu.Vendor = "nvidia"
+		gpu.MIGProfiles = append(gpu.MIGProfiles, models.MIGProfile{Name: profile, Count: available(allocatable, requested[name])})
+	}
+	sort.Slice(gpu.MIGProfiles, func(i, j int) bool { return gpu.MIGProfiles[i].Name < gpu.MIGProfiles[j].Name })
+	gpu.MultiInstance = len(gpu.MIGProfiles) > 0 || node.Labels[nvidiaMIGCapableLabel] == "true"
+
+	// Without a device plugin, the vendor is still reported, but no GPU can be allocated
+	if gpu.Vendor == "" {
+		for label, vendor := range nfdVendorLabels {
+			if node.Labels[label] == "true" {
+				gpu.Vendor = vendor
+				break
+			}
+		}
+	}
+
+	var memoryPerGPU resource.Quantity
+	switch gpu.Vendor {
+	case "nvidia":
+		gpu.Model = node.Labels[nvidiaProductLabel]
+		gpu.Architecture = node.Labels[nvidiaFamilyLabel]
+		if major, ok := node.Labels[nvidiaComputeMajorLabel]; ok {
+			gpu.ComputeCapability = major + "." + node.Labels[nvidiaComputeMinorLabel]
+		}
+		if strategy, ok := node.Labels[nvidiaSharingStrategyLabel]; ok && strategy != "none" {
+			gpu.Shared = true
+			gpu.SharingStrategy = strategy
+		}
+		// The memory is reported in MiB
+		if mib, err := resource.ParseQuantity(node.Labels[nvidiaMemoryLabel] + "Mi"); err == nil {
+			memoryPerGPU = mib
+		}
+	case "amd":
+		gpu.Model = node.Labels[amdProductLabel]
+		gpu.Architecture = node.Labels[amdFamilyLabel]
+		if vram, err := resource.ParseQuantity(node.Labels[amdMemoryLabel]); err == nil {
+			memoryPerGPU = vram
+		}
+	}
+
+	// The total memory is the one of all the GPUs of the node, whether they are requested or not
+	gpu.MemoryTotal = *resource.NewQuantity(memoryPerGPU.Value()*allocatableCount, resource.BinarySI)
+
+	return gpu
+}
+
+// gpuRequests returns the GPUs and MIG instances requested by the pods still running on a node,
+// except for the pods offloaded through FLUIDOS contracts, whose GPUs are already accounted by the contract.
+func gpuRequests(pods []corev1.Pod) corev1.ResourceList {
+	requested := corev1.ResourceList{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || isContractPod(pod) {
+			continue
+		}
+		for name, quantity := range podResources(pod, false) {
+			if !isGPUResource(name) {
+				continue
+			}
+			sum := requested[name]
+			sum.Add(quantity)
+			requested[name] = sum
+		}
+	}
+	return requested
+}
+
+// isGPUResource returns whether a resource is a GPU or a MIG instance.
+func isGPUResource(name corev1.ResourceName) bool {
+	if strings.HasPrefix(string(name), consts.NvidiaMIGResourcePrefix) {
+		return true
+	}
+	for _, gpuResource := range consts.GPUResources {
+		if string(name) == gpuResource {
+			return true
+		}
+	}
+	return false
+}
+
+// available returns the units of an extended resource not requested yet, never below zero.
+func available(allocatable, requested resource.Quantity) int64 {
+	return max(allocatable.Value()-requested.Value(), 0)
+}
+
+// mergeMIGProfiles sums the instances of the MIG profiles of two nodes, keeping the profiles sorted by name.
+func mergeMIGProfiles(a, b []models.MIGProfile) []models.MIGProfile {
+	counts := map[string]int64{}
+	for _, profile := range slices.Concat(a, b) {
+		counts[profile.Name] += profile.Count
+	}
+
+	merged := make([]models.MIGProfile, 0, len(counts))
+	for name, count := range counts {
+		merged = append(merged, models.MIGProfile{Name: name, Count: count})
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// forgeMIGProfiles converts the MIG profiles detected on a node to the ones advertised by its Flavor.
+func forgeMIGProfiles(profiles []models.MIGProfile) []nodecorev1alpha1.MIGProfile {
+	if len(profiles) == 0 {
+		return nil
+	}
+	result := make([]nodecorev1alpha1.MIGProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, nodecorev1alpha1.MIGProfile{Name: profile.Name, Count: profile.Count})
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+)
+
+// locationDefaultKey is the key of the locations ConfigMap applied to the nodes whose region and zone are not configured.
+const locationDefaultKey = "default"
+
+// locationConfig holds the location and network properties advertised by the Flavors of the nodes of a region or zone.
+type locationConfig struct {
+	Latitude            string   `json:"latitude,omitempty"`
+	Longitude           string   `json:"longitude,omitempty"`
+	Country             string   `json:"country,omitempty"`
+	City                string   `json:"city,omitempty"`
+	AdditionalNotes     string   `json:"additionalNotes,omitempty"`
+	NetworkPropertyType string   `json:"networkPropertyType,omitempty"`
+	Latency             int      `json:"latency,omitempty"`
+	SecurityStandards   []string `json:"securityStandards,omitempty"`
+}
+
+// validate checks that the coordinates and the latency of the location are well formed.
+func (l *locationConfig) validate() error {
+	if (l.Latitude == "") != (l.Longitude == "") {
+		return fmt.Errorf("latitude and longitude must be set together")
+	}
+	if l.Latitude != "" {
+		if err := validateCoordinate("latitude", l.Latitude, 90); err != nil {
+			return err
+		}
+		if err := validateCoordinate("longitude", l.Longitude, 180); err != nil {
+			return err
+		}
+	}
+	if l.Latency < 0 {
+		return fmt.Errorf("latency %d must not be negative", l.Latency)
+	}
+	return nil
+}
+
+// validateCoordinate checks that a coordinate is a number of degrees within [-limit, limit].
+func validateCoordinate(name, value string, limit float64) error {
+	degrees, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	if degrees < -limit || degrees > limit {
+		return fmt.Errorf("%s %q out of range [-%g, %g]", name, value, limit, limit)
+	}
+	return nil
+}
+
+// forgeLocation returns the Flavor location described by the configuration.
+func (l *locationConfig) forgeLocation() *nodecorev1alpha1.Location {
+	return &nodecorev1alpha1.Location{
+		Latitude:        l.Latitude,
+		Longitude:       l.Longitude,
+		Country:         l.Country,
+		City:            l.City,
+		AdditionalNotes: l.AdditionalNotes,
+	}
+}
+
+// resolveLocation returns the location of a node, or nil if none is configured. The configuration of its zone is used first,
+// then the one of its region and finally the default one; the location annotations of the node override the configured values.
+func (r *NodeReconciler) resolveLocation(ctx context.Context, node *corev1.Node) (*locationConfig, error) {
+	var location *locationConfig
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: consts.LocationsConfigMapName, Namespace: flags.FluidosNamespace}, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range []string{node.Labels[corev1.LabelTopologyZone], node.Labels[corev1.LabelTopologyRegion], locationDefaultKey} {
+		raw, ok := cm.Data[key]
+		if key == "" || !ok {
+			continue
+		}
+		location = &locationConfig{}
+		if err := yaml.Unmarshal([]byte(raw), location); err != nil {
+			return nil, fmt.Errorf("invalid location %q: %w", key, err)
+		}
+		break
+	}
+
+	for annotation, field := range map[string]func(*locationConfig) *string{
+		consts.FluidosLocationLatitude:  func(l *locationConfig) *string { return &l.Latitude },
+		consts.FluidosLocationLongitude: func(l *locationConfig) *string { return &l.Longitude },
+		consts.FluidosLocationCountry:   func(l *locationConfig) *string { return &l.Country },
+		consts.FluidosLocationCity:      func(l *locationConfig) *string { return &l.City },
+	} {
+		value, ok := node.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		if location == nil {
+			location = &locationConfig{}
+		}
+		*field(location) = value
+	}
+
+	if location == nil {
+		return nil, nil
+	}
+	if err := location.validate(); err != nil {
+		return nil, fmt.Errorf("invalid location of node %s: %w", node.Name, err)
+	}
+	return location, nil
+}
+
+// nodeLocation resolves the location of a node, reporting an invalid configuration through an event on the node.
+// In that case nil is returned, and the location advertised by the Flavor is left untouched until the configuration is fixed.
+func (r *NodeReconciler) nodeLocation(ctx context.Context, node *corev1.Node) *locationConfig {
+	location, err := r.resolveLocation(ctx, node)
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "error resolving the location of the node", "node", node.Name)
+		r.Recorder.Event(node, corev1.EventTypeWarning, "InvalidLocation", err.Error())
+		return nil
+	}
+	return location
+}
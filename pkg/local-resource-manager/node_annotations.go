@@ -0,0 +1,253 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/models"
+)
+
+// annotationDomains are the domains of the node annotations describing the GPUs of a node.
+// Any annotation of these domains not listed in nodeAnnotations is reported as unsupported.
+var annotationDomains = []string{
+	"gpu.fluidos.eu/",
+	"nvidia.fluidos.eu/",
+	"cost.fluidos.eu/",
+	"provider.fluidos.eu/",
+	"workload.fluidos.eu/",
+	"network.fluidos.eu/",
+	"location.fluidos.eu/",
+}
+
+// maxGPUCount is the largest number of GPUs a node may declare through its annotations.
+const maxGPUCount = 1024
+
+// nodeAnnotation describes an annotation of a node supported by the local ResourceManager.
+type nodeAnnotation struct {
+	key string
+	// expected describes the values accepted for the annotation, to report an invalid value.
+	expected string
+	apply    func(gpu *models.GPUMetrics, value string) error
+}
+
+// nodeAnnotations is the schema of the supported node annotations, applied in order on the detected GPU metrics.
+// The GPU count is applied before the per-GPU memory and cores, which are multiplied by it.
+var nodeAnnotations = []nodeAnnotation{
+	stringAnnotation("provider.fluidos.eu/name", func(gpu *models.GPUMetrics, v string) { gpu.Provider = v }),
+	stringAnnotation("gpu.fluidos.eu/vendor", func(gpu *models.GPUMetrics, v string) { gpu.Vendor = v }),
+	stringAnnotation("gpu.fluidos.eu/model", func(gpu *models.GPUMetrics, v string) { gpu.Model = v }),
+	countAnnotation("gpu.fluidos.eu/count", maxGPUCount, func(gpu *models.GPUMetrics, v int64) { gpu.Count = v }),
+	perGPUAnnotation("gpu.fluidos.eu/memory-per-gpu", func(gpu *models.GPUMetrics, v resource.Quantity) { gpu.MemoryTotal = v }),
+	stringAnnotation("gpu.fluidos.eu/tier", func(gpu *models.GPUMetrics, v string) { gpu.Tier = v }),
+	stringAnnotation("gpu.fluidos.eu/architecture", func(gpu *models.GPUMetrics, v string) { gpu.Architecture = v }),
+	stringAnnotation("gpu.fluidos.eu/compute-capability", func(gpu *models.GPUMetrics, v string) { gpu.ComputeCapability = v }),
+	boolAnnotation("nvidia.fluidos.eu/mig-capable", func(gpu *models.GPUMetrics, v bool) { gpu.MultiInstance = v }),
+	floatAnnotation("gpu.fluidos.eu/fp32-tflops", func(gpu *models.GPUMetrics, v float64) { gpu.FP32TFlops = v }),
+	boolAnnotation("gpu.fluidos.eu/sharing-capable", func(gpu *models.GPUMetrics, v bool) { gpu.Shared = v }),
+	stringAnnotation("gpu.fluidos.eu/sharing-strategy", func(gpu *models.GPUMetrics, v string) { gpu.SharingStrategy = v }),
+	stringAnnotation("gpu.fluidos.eu/interconnect", func(gpu *models.GPUMetrics, v string) { gpu.Interconnect = v }),
+	quantityAnnotation("gpu.fluidos.eu/interconnect-bandwidth-gbps",
+		func(gpu *models.GPUMetrics, v resource.Quantity) { gpu.InterconnectBandwidth = v }),
+	perGPUAnnotation("gpu.fluidos.eu/cores", func(gpu *models.GPUMetrics, v resource.Quantity) { gpu.CoresTotal = v }),
+	quantityAnnotation("gpu.fluidos.eu/clock-speed", func(gpu *models.GPUMetrics, v resource.Quantity) { gpu.ClockSpeed = v }),
+	boolAnnotation("gpu.fluidos.eu/interruptible", func(gpu *models.GPUMetrics, v bool) { gpu.Interruptible = v }),
+	boolAnnotation("gpu.fluidos.eu/dedicated", func(gpu *models.GPUMetrics, v bool) { gpu.Dedicated = v }),
+	stringAnnotation("gpu.fluidos.eu/topology", func(gpu *models.GPUMetrics, v string) { gpu.Topology = v }),
+	stringAnnotation("gpu.fluidos.eu/multi-gpu-efficiency", func(gpu *models.GPUMetrics, v string) { gpu.MultiGPUEfficiency = v }),
+	floatAnnotation("cost.fluidos.eu/hourly-rate", func(gpu *models.GPUMetrics, v float64) { gpu.HourlyRate = v }),
+	boolAnnotation("provider.fluidos.eu/preemptible", func(gpu *models.GPUMetrics, v bool) { gpu.PreEmptible = v }),
+	floatAnnotation("workload.fluidos.eu/training-score", func(gpu *models.GPUMetrics, v float64) { gpu.TrainingScore = v }),
+	floatAnnotation("workload.fluidos.eu/inference-score", func(gpu *models.GPUMetrics, v float64) { gpu.InferenceScore = v }),
+	floatAnnotation("workload.fluidos.eu/hpc-score", func(gpu *models.GPUMetrics, v float64) { gpu.HPCScore = v }),
+	floatAnnotation("workload.fluidos.eu/graphics-score", func(gpu *models.GPUMetrics, v float64) { gpu.GraphicsScore = v }),
+	quantityAnnotation("network.fluidos.eu/bandwidth-gbps", func(gpu *models.GPUMetrics, v resource.Quantity) { gpu.NetworkBandwidth = v }),
+	intAnnotation("network.fluidos.eu/latency-ms", func(gpu *models.GPUMetrics, v int64) { gpu.NetworkLatencyMs = v }),
+	stringAnnotation("network.fluidos.eu/tier", func(gpu *models.GPUMetrics, v string) { gpu.NetworkTier = v }),
+	stringAnnotation("location.fluidos.eu/region", func(gpu *models.GPUMetrics, v string) { gpu.Region = v }),
+	stringAnnotation("location.fluidos.eu/zone", func(gpu *models.GPUMetrics, v string) { gpu.Zone = v }),
+}
+
+func stringAnnotation(key string, set func(*models.GPUMetrics, string)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "a non-empty string", apply: func(gpu *models.GPUMetrics, value string) error {
+		if strings.TrimSpace(value) == "" {
+			return fmt.Errorf("empty value")
+		}
+		set(gpu, value)
+		return nil
+	}}
+}
+
+func boolAnnotation(key string, set func(*models.GPUMetrics, bool)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "true or false", apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		set(gpu, v)
+		return nil
+	}}
+}
+
+func intAnnotation(key string, set func(*models.GPUMetrics, int64)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "a non-negative integer", apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return fmt.Errorf("negative value")
+		}
+		set(gpu, v)
+		return nil
+	}}
+}
+
+func countAnnotation(key string, limit int64, set func(*models.GPUMetrics, int64)) nodeAnnotation {
+	expected := fmt.Sprintf("an integer between 0 and %d", limit)
+	return nodeAnnotation{key: key, expected: expected, apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if v < 0 || v > limit {
+			return fmt.Errorf("out of range")
+		}
+		set(gpu, v)
+		return nil
+	}}
+}
+
+func floatAnnotation(key string, set func(*models.GPUMetrics, float64)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "a non-negative number", apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("not a finite non-negative number")
+		}
+		set(gpu, v)
+		return nil
+	}}
+}
+
+func quantityAnnotation(key string, set func(*models.GPUMetrics, resource.Quantity)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "a non-negative quantity (e.g., 40Gi)", apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := resource.ParseQuantity(value)
+		if err != nil {
+			return err
+		}
+		if v.Sign() < 0 {
+			return fmt.Errorf("negative value")
+		}
+		set(gpu, v)
+		return nil
+	}}
+}
+
+// perGPUAnnotation sets the total of a quantity given for each GPU of the node.
+func perGPUAnnotation(key string, set func(*models.GPUMetrics, resource.Quantity)) nodeAnnotation {
+	return nodeAnnotation{key: key, expected: "a non-negative quantity per GPU (e.g., 40Gi)", apply: func(gpu *models.GPUMetrics, value string) error {
+		v, err := resource.ParseQuantity(value)
+		if err != nil {
+			return err
+		}
+		if v.Sign() < 0 {
+			return fmt.Errorf("negative value")
+		}
+		if gpu.Count > 0 && v.Value() > math.MaxInt64/gpu.Count {
+			return fmt.Errorf("too large for %d GPUs", gpu.Count)
+		}
+		set(gpu, *resource.NewQuantity(v.Value()*gpu.Count, v.Format))
+		return nil
+	}}
+}
+
+// applyNodeAnnotations overrides the GPU metrics of a node with its annotations.
+// Invalid values and unsupported annotations are ignored, and returned as messages for the operator.
+func applyNodeAnnotations(annotations map[string]string, gpu *models.GPUMetrics) []string {
+	var invalid []string
+	supported := make(map[string]bool, len(nodeAnnotations))
+	for _, annotation := range nodeAnnotations {
+		supported[annotation.key] = true
+
+		value, found := annotations[annotation.key]
+		if !found {
+			continue
+		}
+		if err := annotation.apply(gpu, value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("annotation %s has invalid value %q (%v), expected %s", annotation.key, value, err, annotation.expected))
+		}
+	}
+
+	var unsupported []string
+	for key := range annotations {
+		if supported[key] {
+			continue
+		}
+		for _, domain := range annotationDomains {
+			if strings.HasPrefix(key, domain) {
+				unsupported = append(unsupported, key)
+				break
+			}
+		}
+	}
+	sort.Strings(unsupported)
+	for _, key := range unsupported {
+		invalid = append(invalid, fmt.Sprintf("annotation %s is not supported", key))
+	}
+
+	return invalid
+}
+
+// reportInvalidAnnotations records an event on a node for each of its annotations that has been ignored.
+func (r *NodeReconciler) reportInvalidAnnotations(node *corev1.Node, invalid []string) {
+	for _, message := range invalid {
+		r.Recorder.Event(node, corev1.EventTypeWarning, "InvalidAnnotation", message)
+	}
+}
+
+// reflectInvalidAnnotations sets the AnnotationsValid condition of a Flavor, listing the annotations ignored on its nodes.
+func (r *NodeReconciler) reflectInvalidAnnotations(ctx context.Context, flavor *nodecorev1alpha1.Flavor, invalid []string) error {
+	condition := metav1.Condition{
+		Type:               nodecorev1alpha1.FlavorAnnotationsValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AnnotationsValid",
+		Message:            "All the annotations of the nodes are valid",
+		ObservedGeneration: flavor.Generation,
+	}
+	if len(invalid) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidAnnotations"
+		condition.Message = strings.Join(invalid, "; ")
+	}
+
+	if !meta.SetStatusCondition(&flavor.Status.Conditions, condition) {
+		return nil
+	}
+	return r.Client.Status().Update(ctx, flavor)
+}
@@ -17,33 +17,54 @@ package localresourcemanager
 import (
 	"context"
 	"encoding/json"
+	"math"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	"github.com/fluidos-project/node/pkg/indexer"
+	"github.com/fluidos-project/node/pkg/utils/consts"
 	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/getters"
 	"github.com/fluidos-project/node/pkg/utils/models"
 	"github.com/fluidos-project/node/pkg/utils/namings"
-	"github.com/fluidos-project/node/pkg/utils/parseutil"
+	"github.com/fluidos-project/node/pkg/utils/pricing"
+	"github.com/fluidos-project/node/pkg/utils/services"
 )
 
+// capacityResyncInterval is the interval after which the capacity of a node is checked again.
+const capacityResyncInterval = time.Minute
+
 // ClusterRole
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=flavors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=flavors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=flavortemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=reservation.fluidos.eu,resources=contracts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses;csistoragecapacities,verbs=get;list;watch
 // +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=metrics.k8s.io,resources=nodes,verbs=get;list;watch
 
@@ -54,6 +75,8 @@ type NodeReconciler struct {
 	EnableAutoDiscovery bool
 	WebhookServer       webhook.Server
 	FlavorIndexer       indexer.FlavorByNodeName
+	PodIndexer          indexer.PodByNodeName
+	Recorder            record.EventRecorder
 }
 
 func (r *NodeReconciler) LabelSelector() labels.Selector {
@@ -92,58 +115,148 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, nil
 	}
 
+	// Get NodeIdentity
+	nodeIdentity := getters.GetNodeIdentity(ctx, r.Client)
+	if nodeIdentity == nil {
+		log.Info("error getting FLUIDOS Node identity")
+		return ctrl.Result{}, nil
+	}
+
+	// The pools the node has left must stop aggregating it
+	pool := nodePool(&node)
+	if err := r.reconcileStalePools(ctx, &node, pool, *nodeIdentity); err != nil {
+		log.Error(err, "error reconciling the node pools left by the node")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// The nodes grouped into a pool are advertised together by the Flavor of the pool
+	if pool != "" {
+		if err := r.reconcilePool(ctx, pool, *nodeIdentity); err != nil {
+			log.Error(err, "error reconciling the node pool", "pool", pool)
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		log.Info("Node pool reconciliation completed", "pool", pool)
+
+		return ctrl.Result{RequeueAfter: capacityResyncInterval}, nil
+	}
+
+	flavor, err := r.nodeFlavor(ctx, &node)
+	if err != nil {
+		log.Error(err, "error listing Flavors")
+		return ctrl.Result{}, nil
+	}
+
+	// The health is checked first, as the nodes that are not Ready have no metrics
+	if reason := nodeUnavailableReason(&node); reason != "" {
+		return r.withdrawNodeFlavor(ctx, &node, flavor, reason)
+	}
+
 	var nodeMetrics metricsv1beta1.NodeMetrics
 	// Get the node metrics referred to the node
 	if err := r.Client.Get(ctx, client.ObjectKey{Name: node.Name}, &nodeMetrics); err != nil {
+		if apierrors.IsNotFound(err) {
+			return r.withdrawNodeFlavor(ctx, &node, flavor, nodeMetricsUnavailable)
+		}
 		log.Error(err, "error getting NodeMetrics", err)
 		return ctrl.Result{}, err
 	}
-	// Get the NodeInfo struct for the node and its metrics
-	nodeInfo, err := GetNodeInfos(&node, &nodeMetrics)
+	// Get the pods scheduled on the node, whose resources are not available anymore
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods, client.MatchingFields{r.PodIndexer.Field(): node.Name}); err != nil {
+		log.Error(err, "error listing Pods", "node", node.Name)
+		return ctrl.Result{}, err
+	}
+	// Get the NodeInfo struct for the node, its metrics and its pods
+	nodeInfo, err := GetNodeInfos(&node, &nodeMetrics, pods.Items)
 	if err != nil {
 		log.Error(err, "error getting NodeInfo", err)
 		return ctrl.Result{}, err
 	}
 	log.Info("NodeInfo created", "value", nodeInfo.Name)
-	// Get NodeIdentity
-	nodeIdentity := getters.GetNodeIdentity(ctx, r.Client)
-	if nodeIdentity == nil {
-		log.Info("error getting FLUIDOS Node identity")
-		return ctrl.Result{}, nil
+	r.reportInvalidAnnotations(&node, nodeInfo.InvalidAnnotations)
+
+	location := r.nodeLocation(ctx, &node)
+	template, err := r.flavorTemplate(ctx, &node)
+	if err != nil {
+		log.Error(err, "error getting the FlavorTemplate of the node")
+		return ctrl.Result{Requeue: true}, nil
 	}
+	if flavor, err = r.createOrUpdateFlavor(ctx, flavor, nodeInfo, *nodeIdentity, []client.Object{&node}, nil, location, template); err != nil {
+		log.Error(err, "error creating or updating Flavor", err)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if err = r.reflectInvalidAnnotations(ctx, flavor, nodeInfo.InvalidAnnotations); err != nil {
+		log.Error(err, "error reporting the invalid annotations of the node on its Flavor")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if err = r.reflectNodeHealth(ctx, "Node "+node.Name, "", flavor); err != nil {
+		log.Error(err, "error reflecting the health of the node on its Flavors")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	log.Info("Flavor reconciliation completed")
+
+	// The node metrics are not watched, so the capacity is checked again periodically
+	return ctrl.Result{RequeueAfter: capacityResyncInterval}, nil
+}
+
+// nodeFlavor returns the Flavor advertising a node on its own, or nil if it has not been created yet.
+func (r *NodeReconciler) nodeFlavor(ctx context.Context, node *corev1.Node) (*nodecorev1alpha1.Flavor, error) {
 	// Get all the Flavors owned by this node as kubernetes ownership:
 	// iterating over a list is required since a Flavor can be owned by multiple resources.
 	var matchFlavors nodecorev1alpha1.FlavorList
-	if err = r.Client.List(ctx, &matchFlavors, client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector(r.FlavorIndexer.Field(), node.Name)}); err != nil {
-		log.Error(err, "error listing Flavors")
-		return ctrl.Result{}, nil
+	if err := r.Client.List(ctx, &matchFlavors,
+		client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector(r.FlavorIndexer.Field(), node.Name)}); err != nil {
+		return nil, err
 	}
-	// Check if you have found any Flavor
-	var flavor *nodecorev1alpha1.Flavor
 
-	for _, i := range matchFlavors.Items {
-		for _, or := range i.OwnerReferences {
+	for i := range matchFlavors.Items {
+		flavor := &matchFlavors.Items[i]
+		// Remainders of a partitioned Flavor inherit its owner, but they are managed by the REAR Manager
+		if _, ok := flavor.Labels[consts.FluidosFlavorRootLabel]; ok {
+			continue
+		}
+		// The Flavors of the node pools are handled separately
+		if _, ok := flavor.Labels[consts.FluidosFlavorNodePoolLabel]; ok {
+			continue
+		}
+		for _, or := range flavor.OwnerReferences {
 			if or.Kind == "Node" {
-				flavor = &i
-
-				log.Info("Flavor found", "namespacedName", client.ObjectKeyFromObject(flavor), "node", node.Name)
-
-				break
+				ctrl.LoggerFrom(ctx).Info("Flavor found", "namespacedName", client.ObjectKeyFromObject(flavor), "node", node.Name)
+				return flavor, nil
 			}
 		}
 	}
 
-	if err = r.createOrUpdateFlavor(ctx, flavor, nodeInfo, *nodeIdentity, &node); err != nil {
-		log.Error(err, "error creating or updating Flavor", err)
-		return ctrl.Result{Requeue: true}, nil
-	}
+	return nil, nil
+}
 
-	log.Info("Flavor reconciliation completed")
+// withdrawNodeFlavor withdraws the Flavor of a node that cannot run new workloads, without updating its capacity.
+// A node that has no Flavor yet gets one once it is healthy.
+func (r *NodeReconciler) withdrawNodeFlavor(ctx context.Context, node *corev1.Node, flavor *nodecorev1alpha1.Flavor,
+	reason string) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Node cannot run new workloads", "node", node.Name, "reason", reason)
+	if flavor != nil {
+		if err := r.reflectNodeHealth(ctx, "Node "+node.Name, reason, flavor); err != nil {
+			log.Error(err, "error reflecting the health of the node on its Flavors")
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
 
-	return ctrl.Result{}, nil
+	// The node conditions are watched, but the node metrics are not
+	return ctrl.Result{RequeueAfter: capacityResyncInterval}, nil
 }
 
-func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodecorev1alpha1.Flavor, nodeInfo *models.NodeInfo, nodeIdentity nodecorev1alpha1.NodeIdentity, owner client.Object) (err error) {
+// createOrUpdateFlavor creates or updates the Flavor advertising a node, or the nodes of a pool when pool is not nil.
+// The location and network properties are updated only when location is not nil.
+// The FlavorTemplate selecting the nodes, if any, takes precedence over the flags, the locations and the rate card.
+func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodecorev1alpha1.Flavor, nodeInfo *models.NodeInfo,
+	nodeIdentity nodecorev1alpha1.NodeIdentity, owners []client.Object, pool *nodecorev1alpha1.NodePool,
+	location *locationConfig, template *nodecorev1alpha1.FlavorTemplate) (*nodecorev1alpha1.Flavor, error) {
 	log := ctrl.LoggerFrom(ctx)
 	// Forge the Flavor from the NodeInfo and NodeIdentity
 	shouldCreate := flavor == nil
@@ -155,6 +268,21 @@ func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodec
 	flavor.Namespace = flags.FluidosNamespace
 
 	log.Info("ready to handle Flavor", "namespacedName", client.ObjectKeyFromObject(flavor), "type", nodecorev1alpha1.TypeK8Slice)
+	// The price follows the rate card when configured, in place of the static one
+	rateCard, err := pricing.GetRateCard(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "error getting the rate card, the price of the Flavor is left untouched")
+	}
+	region := owners[0].GetLabels()[corev1.LabelTopologyRegion]
+	// The fixed price of the FlavorTemplate, if any, takes the place of the rate card
+	fixedPrice := templatePrice(template)
+	// The persistent storage follows the StorageClasses available to the nodes
+	storageClasses, storageErr := r.storageClasses(ctx, owners)
+	if storageErr != nil {
+		log.Error(storageErr, "error getting the storage classes, the storage of the Flavor is left untouched")
+	}
+	// Capacity lost by the node since the last update of the Flavor, if any
+	var lost *nodecorev1alpha1.K8SliceCharacteristics
 	// Creating a new flavor custom resource from the metrics of the node.
 	res, err := controllerutil.CreateOrUpdate(ctx, r.Client, flavor, func() error {
 		var k8sSliceType nodecorev1alpha1.K8Slice
@@ -164,13 +292,22 @@ func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodec
 			}
 		}
 
-		if shouldCreate {
+		// The capacity is updated only when it changes significantly, to avoid churning the Flavor
+		if shouldCreate || capacityChanged(&k8sSliceType.Characteristics, &nodeInfo.ResourceMetrics) {
+			lost = lostCapacity(&k8sSliceType.Characteristics, &nodeInfo.ResourceMetrics)
 			k8sSliceType.Characteristics.CPU = nodeInfo.ResourceMetrics.CPUAvailable
 			k8sSliceType.Characteristics.Memory = nodeInfo.ResourceMetrics.MemoryAvailable
 			k8sSliceType.Characteristics.Pods = nodeInfo.ResourceMetrics.PodsAvailable
-			k8sSliceType.Characteristics.Storage = &nodeInfo.ResourceMetrics.EphemeralStorage
+			k8sSliceType.Properties.NodePool = pool
+		}
+		// The per-node limits follow the capacity, while the nodes of the pool are always kept up to date
+		if !sameNodePool(k8sSliceType.Properties.NodePool, pool) {
+			k8sSliceType.Properties.NodePool = pool
 		}
 
+		if storageErr == nil {
+			setStorage(&k8sSliceType.Characteristics, storageClasses)
+		}
 		k8sSliceType.Characteristics.Architecture = nodeInfo.Architecture
 		k8sSliceType.Characteristics.Gpu = &nodecorev1alpha1.GPU{
 			Model:                 nodeInfo.ResourceMetrics.GPU.Model,
@@ -204,17 +341,16 @@ func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodec
 			HourlyRate:            nodeInfo.ResourceMetrics.GPU.HourlyRate,
 			Provider:              nodeInfo.ResourceMetrics.GPU.Provider,
 			PreEmptible:           nodeInfo.ResourceMetrics.GPU.PreEmptible,
+			MIGProfiles:           forgeMIGProfiles(nodeInfo.ResourceMetrics.GPU.MIGProfiles),
 		}
 		k8sSliceType.Policies = nodecorev1alpha1.Policies{
-			Partitionability: nodecorev1alpha1.Partitionability{
-				CPUMin:     parseutil.ParseQuantityFromString(flags.CPUMin),
-				MemoryMin:  parseutil.ParseQuantityFromString(flags.MemoryMin),
-				PodsMin:    parseutil.ParseQuantityFromString(flags.PodsMin),
-				CPUStep:    parseutil.ParseQuantityFromString(flags.CPUStep),
-				MemoryStep: parseutil.ParseQuantityFromString(flags.MemoryStep),
-				PodsStep:   parseutil.ParseQuantityFromString(flags.PodsStep),
-			},
+			Partitionability: templatePartitionability(template),
+		}
+		if location != nil {
+			k8sSliceType.Properties.Latency = location.Latency
+			k8sSliceType.Properties.SecurityStandards = location.SecurityStandards
 		}
+		applyTemplateProperties(&k8sSliceType.Properties, template)
 		// Serialize K8SliceType to JSON
 		k8SliceTypeJSON, marshalErr := json.Marshal(k8sSliceType)
 		if marshalErr != nil {
@@ -234,29 +370,218 @@ func (r *NodeReconciler) createOrUpdateFlavor(ctx context.Context, flavor *nodec
 			flavor.Spec.Price.Currency = flags.CURRENCY
 			flavor.Spec.Price.Period = flags.PERIOD
 			flavor.Spec.Availability = true
-			// FIXME: NetworkPropertyType should be taken in a smarter way
-			flavor.Spec.NetworkPropertyType = "networkProperty"
-			// FIXME: Location should be taken in a smarter way
-			flavor.Spec.Location = &nodecorev1alpha1.Location{
-				Latitude:        "10",
-				Longitude:       "58",
-				Country:         "Italy",
-				City:            "Turin",
-				AdditionalNotes: "None",
-			}
+		}
+		applyTemplateAvailability(flavor, template)
+		if location != nil {
+			flavor.Spec.NetworkPropertyType = location.NetworkPropertyType
+			flavor.Spec.Location = location.forgeLocation()
+		}
+		if template != nil && template.Spec.NetworkPropertyType != "" {
+			flavor.Spec.NetworkPropertyType = template.Spec.NetworkPropertyType
+		}
+		if template != nil && template.Spec.Location != nil {
+			flavor.Spec.Location = template.Spec.Location.DeepCopy()
+		}
+		switch {
+		case fixedPrice != nil:
+			flavor.Spec.Price = *fixedPrice
+		case rateCard != nil:
+			flavor.Spec.Price = rateCard.Price(&k8sSliceType.Characteristics, region)
 		}
 
-		return controllerutil.SetOwnerReference(owner, flavor, r.Client.Scheme())
+		if flavor.Labels == nil {
+			flavor.Labels = map[string]string{}
+		}
+		// The region prices the partitions of the Flavor at reservation time
+		if region != "" {
+			flavor.Labels[corev1.LabelTopologyRegion] = region
+		} else {
+			delete(flavor.Labels, corev1.LabelTopologyRegion)
+		}
+		// The REAR gateway scales the fixed price of the template to the partitions of the Flavor at reservation time
+		if template != nil {
+			flavor.Labels[consts.FluidosFlavorTemplateLabel] = template.Name
+		} else {
+			delete(flavor.Labels, consts.FluidosFlavorTemplateLabel)
+		}
+		if pool != nil {
+			flavor.Labels[consts.FluidosFlavorNodePoolLabel] = pool.Value
+			// The nodes that left the pool do not own its Flavor anymore
+			flavor.OwnerReferences = nil
+		}
+
+		for _, owner := range owners {
+			if err := controllerutil.SetOwnerReference(owner, flavor, r.Client.Scheme()); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	log.Info("Flavor handling completed", "namespacedName", client.ObjectKeyFromObject(flavor), "res", res)
 
+	if !shouldCreate && lost != nil {
+		if err := r.shrinkRemainders(ctx, flavor, lost); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case fixedPrice != nil:
+		// The fixed price is the one of the whole Flavor, so each remainder is charged its share
+		var whole *nodecorev1alpha1.K8Slice
+		if whole, err = nodecorev1alpha1.ParseK8SliceFlavor(flavor.Spec.FlavorType); err != nil {
+			return nil, err
+		}
+		err = r.repriceRemainders(ctx, flavor, func(characteristics *nodecorev1alpha1.K8SliceCharacteristics) nodecorev1alpha1.Price {
+			return pricing.ScalePrice(*fixedPrice, &whole.Characteristics, characteristics)
+		})
+	case rateCard != nil:
+		err = r.repriceRemainders(ctx, flavor, func(characteristics *nodecorev1alpha1.K8SliceCharacteristics) nodecorev1alpha1.Price {
+			return rateCard.Price(characteristics, region)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return flavor, nil
+}
+
+// repriceRemainders prices the available remainders of a partitioned Flavor, according to their capacity.
+func (r *NodeReconciler) repriceRemainders(ctx context.Context, root *nodecorev1alpha1.Flavor,
+	price func(*nodecorev1alpha1.K8SliceCharacteristics) nodecorev1alpha1.Price) error {
+	var remainders nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &remainders, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorRootLabel: root.Name}); err != nil {
+		return err
+	}
+
+	for i := range remainders.Items {
+		remainder := &remainders.Items[i]
+		if !remainder.Spec.Availability {
+			continue
+		}
+		k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(remainder.Spec.FlavorType)
+		if err != nil {
+			return err
+		}
+		remainderPrice := price(&k8Slice.Characteristics)
+		if remainderPrice == remainder.Spec.Price {
+			continue
+		}
+		remainder.Spec.Price = remainderPrice
+		if err := r.Client.Update(ctx, remainder); err != nil {
+			return err
+		}
+		ctrl.LoggerFrom(ctx).Info("Flavor remainder repriced", "flavor", remainder.Name, "amount", remainderPrice.Amount)
+	}
+
+	return nil
+}
+
+// shrinkRemainders takes the capacity lost by a node off the available remainders of its Flavor, once it has been partitioned.
+// The capacity already sold through Contracts is left untouched, and the remainders are never grown: the capacity gained
+// by the node is given back to the catalog when the Contracts are released, as the root Flavor now holds it.
+func (r *NodeReconciler) shrinkRemainders(ctx context.Context, root *nodecorev1alpha1.Flavor, lost *nodecorev1alpha1.K8SliceCharacteristics) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	var remainders nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &remainders, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorRootLabel: root.Name}); err != nil {
+		return err
+	}
+
+	for i := range remainders.Items {
+		remainder := &remainders.Items[i]
+		if !remainder.Spec.Availability {
+			continue
+		}
+		k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(remainder.Spec.FlavorType)
+		if err != nil {
+			return err
+		}
+		available := k8Slice.Characteristics.DeepCopy()
+		available.CPU.Sub(lost.CPU)
+		available.Memory.Sub(lost.Memory)
+		available.Pods.Sub(lost.Pods)
+
+		if available.CPU.Sign() <= 0 || available.Memory.Sign() <= 0 || available.Pods.Sign() <= 0 {
+			if err := r.Client.Delete(ctx, remainder); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.Info("Flavor remainder deleted, the node has no free capacity left", "flavor", remainder.Name)
+			continue
+		}
+
+		if err := services.SetK8SliceCharacteristics(remainder, available); err != nil {
+			return err
+		}
+		if err := r.Client.Update(ctx, remainder); err != nil {
+			return err
+		}
+		log.Info("Flavor remainder shrunk to the capacity of the node", "flavor", remainder.Name)
+	}
+
 	return nil
 }
 
+// capacityChanged returns whether the available CPU, memory or pods of a node moved away from the characteristics
+// of its Flavor by more than the configured threshold, in percent of the current value.
+func capacityChanged(current *nodecorev1alpha1.K8SliceCharacteristics, metrics *models.ResourceMetrics) bool {
+	for _, pair := range [][2]resource.Quantity{
+		{current.CPU, metrics.CPUAvailable},
+		{current.Memory, metrics.MemoryAvailable},
+		{current.Pods, metrics.PodsAvailable},
+	} {
+		diff := pair[1].DeepCopy()
+		diff.Sub(pair[0])
+		if diff.Sign() == 0 {
+			continue
+		}
+		if pair[0].Sign() <= 0 ||
+			math.Abs(diff.AsApproximateFloat64())*100 > float64(flags.CapacityChangeThreshold)*pair[0].AsApproximateFloat64() {
+			return true
+		}
+	}
+	return false
+}
+
+// lostCapacity returns the CPU, memory and pods that a node no longer has available compared to the characteristics of its Flavor.
+func lostCapacity(current *nodecorev1alpha1.K8SliceCharacteristics, metrics *models.ResourceMetrics) *nodecorev1alpha1.K8SliceCharacteristics {
+	lost := func(from, to resource.Quantity) resource.Quantity {
+		diff := from.DeepCopy()
+		diff.Sub(to)
+		if diff.Sign() < 0 {
+			diff.Set(0)
+		}
+		return diff
+	}
+
+	return &nodecorev1alpha1.K8SliceCharacteristics{
+		CPU:    lost(current.CPU, metrics.CPUAvailable),
+		Memory: lost(current.Memory, metrics.MemoryAvailable),
+		Pods:   lost(current.Pods, metrics.PodsAvailable),
+	}
+}
+
+// mapResourceNodes enqueues all the resource nodes, when a configuration shared by their Flavors changes.
+func (r *NodeReconciler) mapResourceNodes(ctx context.Context, _ client.Object) []reconcile.Request {
+	var nodes corev1.NodeList
+	if err := r.Client.List(ctx, &nodes, client.MatchingLabelsSelector{Selector: r.LabelSelector()}); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "error listing the nodes")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: nodes.Items[i].Name}})
+	}
+	return requests
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -264,5 +589,25 @@ func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return r.LabelSelector().Matches(labels.Set(object.GetLabels()))
 		}))).
 		Owns(&nodecorev1alpha1.Flavor{}, builder.MatchEveryOwner).
+		// The pods scheduled on a node change the capacity it has available
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, object client.Object) []reconcile.Request {
+			pod := object.(*corev1.Pod)
+			if pod.Spec.NodeName == "" {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pod.Spec.NodeName}}}
+		})).
+		// The locations, rate card and storage ConfigMaps apply to the Flavors of all the nodes
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceNodes),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return object.GetNamespace() == flags.FluidosNamespace &&
+					(object.GetName() == consts.LocationsConfigMapName || object.GetName() == consts.RateCardConfigMapName ||
+						object.GetName() == consts.StorageConfigMapName)
+			}))).
+		// The StorageClasses and their capacity change the persistent storage offered by the nodes
+		Watches(&storagev1.StorageClass{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceNodes)).
+		Watches(&storagev1.CSIStorageCapacity{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceNodes)).
+		// The FlavorTemplates may select any node
+		Watches(&nodecorev1alpha1.FlavorTemplate{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceNodes)).
 		Complete(r)
 }
@@ -0,0 +1,160 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/services"
+)
+
+// nodeMetricsUnavailable is the reason of the nodes whose usage is unknown, as the metrics server has no metrics about them.
+const nodeMetricsUnavailable = "NodeMetricsUnavailable"
+
+// nodeUnavailableReason returns why a node cannot run new workloads, or an empty string if it is healthy and schedulable.
+func nodeUnavailableReason(node *corev1.Node) string {
+	ready := false
+	for _, condition := range node.Status.Conditions {
+		switch condition.Type {
+		case corev1.NodeReady:
+			ready = condition.Status == corev1.ConditionTrue
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if condition.Status == corev1.ConditionTrue {
+				return fmt.Sprintf("Node%s", condition.Type)
+			}
+		}
+	}
+	if !ready {
+		return "NodeNotReady"
+	}
+	// Draining nodes are cordoned first, so they are covered as well
+	if node.Spec.Unschedulable {
+		return "NodeCordoned"
+	}
+	for _, taint := range node.Spec.Taints {
+		// The taint set on cordoned nodes is already covered above
+		if taint.Key == corev1.TaintNodeUnschedulable {
+			continue
+		}
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			return fmt.Sprintf("NodeTainted: %s:%s", taint.Key, taint.Effect)
+		}
+	}
+	return ""
+}
+
+// reflectNodeHealth withdraws the available Flavors of a node from the catalog while the node cannot run new workloads,
+// and gives them back once it has recovered. Only the Flavors withdrawn here are made available again,
+// as the ones sold in the meantime must stay unavailable.
+// The subject names the node, or the pool of nodes, advertised by the Flavors in the events.
+func (r *NodeReconciler) reflectNodeHealth(ctx context.Context, subject, reason string, root *nodecorev1alpha1.Flavor) error {
+	log := ctrl.LoggerFrom(ctx)
+	transition := root.Status.UnavailableReason != reason
+
+	var remainders nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &remainders, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorRootLabel: root.Name}); err != nil {
+		return err
+	}
+
+	flavors := []*nodecorev1alpha1.Flavor{root}
+	for i := range remainders.Items {
+		flavors = append(flavors, &remainders.Items[i])
+	}
+
+	for _, flavor := range flavors {
+		_, withdrawn := flavor.Annotations[consts.FluidosFlavorWithdrawn]
+		switch {
+		case reason != "" && flavor.Spec.Availability:
+			flavor.Spec.Availability = false
+			if flavor.Annotations == nil {
+				flavor.Annotations = map[string]string{}
+			}
+			flavor.Annotations[consts.FluidosFlavorWithdrawn] = "true"
+			if err := r.Client.Update(ctx, flavor); err != nil {
+				return err
+			}
+			log.Info("Flavor withdrawn, the node cannot run new workloads", "flavor", flavor.Name, "reason", reason)
+		case reason == "" && withdrawn:
+			// A Flavor disabled by its FlavorTemplate in the meantime stays unavailable
+			_, disabled := flavor.Annotations[consts.FluidosFlavorDisabled]
+			flavor.Spec.Availability = !disabled
+			delete(flavor.Annotations, consts.FluidosFlavorWithdrawn)
+			if err := r.Client.Update(ctx, flavor); err != nil {
+				return err
+			}
+			log.Info("Flavor available again, the node has recovered", "flavor", flavor.Name)
+		}
+
+		if flavor.Status.UnavailableReason != reason {
+			flavor.Status.UnavailableReason = reason
+			if err := r.Client.Status().Update(ctx, flavor); err != nil {
+				return err
+			}
+		}
+	}
+
+	if transition {
+		return r.notifyAllocations(ctx, subject, root.Name, reason)
+	}
+	return nil
+}
+
+// notifyAllocations records an event on the active Allocations of the Flavors of a node, whenever its health changes.
+func (r *NodeReconciler) notifyAllocations(ctx context.Context, subject, root, reason string) error {
+	var allocations nodecorev1alpha1.AllocationList
+	if err := r.Client.List(ctx, &allocations, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		return err
+	}
+
+	for i := range allocations.Items {
+		allocation := &allocations.Items[i]
+		if allocation.Status.Status != nodecorev1alpha1.Active && allocation.Status.Status != nodecorev1alpha1.Degraded {
+			continue
+		}
+
+		var contract reservationv1alpha1.Contract
+		if err := r.Client.Get(ctx, client.ObjectKey{
+			Name:      allocation.Spec.Contract.Name,
+			Namespace: allocation.Spec.Contract.Namespace,
+		}, &contract); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			continue
+		}
+		if services.FlavorRoot(&contract.Spec.Flavor) != root {
+			continue
+		}
+
+		if reason != "" {
+			r.Recorder.Event(allocation, corev1.EventTypeWarning, "NodeUnavailable",
+				fmt.Sprintf("%s cannot run new workloads: %s", subject, reason))
+		} else {
+			r.Recorder.Event(allocation, corev1.EventTypeNormal, "NodeRecovered",
+				fmt.Sprintf("%s is healthy and schedulable again", subject))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/models"
+)
+
+// noHealthyNodeReason is the reason reported by the Flavor of a pool whose nodes cannot run new workloads.
+const noHealthyNodeReason = "NoHealthyNode"
+
+// nodePool returns the pool a node is grouped into, or an empty string if it is advertised by its own Flavor.
+func nodePool(node *corev1.Node) string {
+	if flags.NodePoolLabel == "" {
+		return ""
+	}
+	return node.Labels[flags.NodePoolLabel]
+}
+
+// reconcilePool creates or updates the Flavor aggregating the nodes of a pool, or deletes it once the pool is empty.
+// The capacity of the pool is the one of its healthy nodes; when none is left, the Flavor is withdrawn from the catalog.
+func (r *NodeReconciler) reconcilePool(ctx context.Context, value string, nodeIdentity nodecorev1alpha1.NodeIdentity) error {
+	log := ctrl.LoggerFrom(ctx, "pool", value)
+
+	var nodes corev1.NodeList
+	if err := r.Client.List(ctx, &nodes, client.MatchingLabels{flags.ResourceNodeLabel: "true", flags.NodePoolLabel: value}); err != nil {
+		return err
+	}
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+
+	flavor, err := r.getPoolFlavor(ctx, value)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes.Items) == 0 {
+		if flavor != nil && !isSold(flavor) {
+			if err := r.Client.Delete(ctx, flavor); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			log.Info("Flavor of the node pool deleted, the pool has no nodes left", "flavor", flavor.Name)
+		}
+		return nil
+	}
+
+	var healthy, all []*models.NodeInfo
+	var invalid []string
+	owners := make([]client.Object, 0, len(nodes.Items))
+	names := make([]string, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		owners = append(owners, node)
+		names = append(names, node.Name)
+
+		// The node is now advertised by the Flavor of the pool
+		if err := r.deleteNodeFlavor(ctx, node); err != nil {
+			return err
+		}
+
+		// A node without metrics has an unknown usage, so it counts as unhealthy and adds no capacity to the pool
+		var nodeMetrics metricsv1beta1.NodeMetrics
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: node.Name}, &nodeMetrics); err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Info("Node of the pool skipped, it has no metrics", "node", node.Name)
+				continue
+			}
+			return err
+		}
+		var pods corev1.PodList
+		if err := r.Client.List(ctx, &pods, client.MatchingFields{r.PodIndexer.Field(): node.Name}); err != nil {
+			return err
+		}
+		nodeInfo, err := GetNodeInfos(node, &nodeMetrics, pods.Items)
+		if err != nil {
+			return err
+		}
+
+		r.reportInvalidAnnotations(node, nodeInfo.InvalidAnnotations)
+		for _, message := range nodeInfo.InvalidAnnotations {
+			invalid = append(invalid, fmt.Sprintf("node %s: %s", node.Name, message))
+		}
+
+		all = append(all, nodeInfo)
+		if nodeUnavailableReason(node) == "" {
+			healthy = append(healthy, nodeInfo)
+		}
+	}
+
+	// When no node of the pool has metrics, the capacity is unknown: the Flavor, if any, is withdrawn as it is
+	if len(all) == 0 {
+		if flavor == nil {
+			return nil
+		}
+		return r.reflectNodeHealth(ctx, "Node pool "+value, noHealthyNodeReason, flavor)
+	}
+
+	// When no node of the pool is healthy, the capacity of all the nodes is kept to avoid churning the withdrawn Flavor
+	reason := ""
+	nodeInfos := healthy
+	if len(healthy) == 0 {
+		reason = noHealthyNodeReason
+		nodeInfos = all
+	}
+	nodeInfo, limits := aggregateNodeInfos(value, nodeInfos)
+
+	pool := &nodecorev1alpha1.NodePool{
+		Label:         flags.NodePoolLabel,
+		Value:         value,
+		Nodes:         names,
+		PerNodeLimits: *limits,
+	}
+	// The location and the FlavorTemplate of the pool are the ones of its first node
+	location := r.nodeLocation(ctx, &nodes.Items[0])
+	template, err := r.flavorTemplate(ctx, &nodes.Items[0])
+	if err != nil {
+		return err
+	}
+	if flavor, err = r.createOrUpdateFlavor(ctx, flavor, nodeInfo, nodeIdentity, owners, pool, location, template); err != nil {
+		return err
+	}
+
+	if err = r.reflectInvalidAnnotations(ctx, flavor, invalid); err != nil {
+		return err
+	}
+
+	return r.reflectNodeHealth(ctx, "Node pool "+value, reason, flavor)
+}
+
+// reconcileStalePools reconciles the pools whose Flavor is still owned by a node that is no longer part of them.
+func (r *NodeReconciler) reconcileStalePools(ctx context.Context, node *corev1.Node, pool string, nodeIdentity nodecorev1alpha1.NodeIdentity) error {
+	var flavors nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &flavors, client.MatchingFields{r.FlavorIndexer.Field(): node.Name}); err != nil {
+		return err
+	}
+
+	for i := range flavors.Items {
+		flavor := &flavors.Items[i]
+		if _, ok := flavor.Labels[consts.FluidosFlavorRootLabel]; ok {
+			continue
+		}
+		value, ok := flavor.Labels[consts.FluidosFlavorNodePoolLabel]
+		if !ok || value == pool {
+			continue
+		}
+		if err := r.reconcilePool(ctx, value, nodeIdentity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getPoolFlavor returns the Flavor aggregating the nodes of a pool, or nil if it does not exist yet.
+func (r *NodeReconciler) getPoolFlavor(ctx context.Context, value string) (*nodecorev1alpha1.Flavor, error) {
+	var flavors nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &flavors, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorNodePoolLabel: value}); err != nil {
+		return nil, err
+	}
+
+	for i := range flavors.Items {
+		if _, ok := flavors.Items[i].Labels[consts.FluidosFlavorRootLabel]; !ok {
+			return &flavors.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// deleteNodeFlavor deletes the Flavor advertising a node on its own, once the node has joined a pool.
+// A Flavor that has been sold is kept, as its Contracts still refer to it.
+func (r *NodeReconciler) deleteNodeFlavor(ctx context.Context, node *corev1.Node) error {
+	var flavors nodecorev1alpha1.FlavorList
+	if err := r.Client.List(ctx, &flavors, client.MatchingFields{r.FlavorIndexer.Field(): node.Name}); err != nil {
+		return err
+	}
+
+	for i := range flavors.Items {
+		flavor := &flavors.Items[i]
+		if _, ok := flavor.Labels[consts.FluidosFlavorRootLabel]; ok {
+			continue
+		}
+		if _, ok := flavor.Labels[consts.FluidosFlavorNodePoolLabel]; ok {
+			continue
+		}
+		if isSold(flavor) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, flavor); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		ctrl.LoggerFrom(ctx).Info("Flavor of the node deleted, the node has joined a pool", "flavor", flavor.Name, "node", node.Name)
+	}
+	return nil
+}
+
+// isSold returns whether a Flavor has been made unavailable by a sale, rather than withdrawn because of the health of its nodes
+// or disabled by its FlavorTemplate.
+func isSold(flavor *nodecorev1alpha1.Flavor) bool {
+	_, withdrawn := flavor.Annotations[consts.FluidosFlavorWithdrawn]
+	_, disabled := flavor.Annotations[consts.FluidosFlavorDisabled]
+	return !flavor.Spec.Availability && !withdrawn && !disabled
+}
+
+// sameNodePool returns whether two pools group the same nodes.
+func sameNodePool(a, b *nodecorev1alpha1.NodePool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Label == b.Label && a.Value == b.Value && slices.Equal(a.Nodes, b.Nodes)
+}
@@ -16,39 +16,129 @@ package localresourcemanager
 
 import (
 	"fmt"
-	"strconv"
+	"slices"
 
+	liqoconsts "github.com/liqotech/liqo/pkg/consts"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/models"
 )
 
-// GetNodeInfos returns the NodeInfo struct for a given node and its metrics.
-func GetNodeInfos(node *corev1.Node, nodeMetrics *metricsv1beta1.NodeMetrics) (*models.NodeInfo, error) {
+// Capacity modes, selecting how the resources already in use on a node are accounted.
+const (
+	// CapacityModeRequests accounts the resources requested by the pods scheduled on the node.
+	CapacityModeRequests = "requests"
+	// CapacityModeLimits accounts the resource limits of the pods scheduled on the node,
+	// falling back to the requests of the containers without limits.
+	CapacityModeLimits = "limits"
+	// CapacityModeUsage accounts the resources actually used on the node, as reported by the metrics server.
+	CapacityModeUsage = "usage"
+)
+
+// GetNodeInfos returns the NodeInfo struct for a given node, its metrics and the pods scheduled on it.
+func GetNodeInfos(node *corev1.Node, nodeMetrics *metricsv1beta1.NodeMetrics, pods []corev1.Pod) (*models.NodeInfo, error) {
 	// Check if the node and the node metrics match
 	if node.Name != nodeMetrics.Name {
 		klog.Info("Node and NodeMetrics do not match")
 		return nil, fmt.Errorf("node and node metrics do not match")
 	}
 
-	metricsStruct := forgeResourceMetrics(nodeMetrics, node)
+	metricsStruct, invalid := forgeResourceMetrics(nodeMetrics, node, pods)
 	nodeInfo := forgeNodeInfo(node, metricsStruct)
+	nodeInfo.InvalidAnnotations = invalid
 
 	return nodeInfo, nil
 }
 
+// isContractPod returns whether a pod has been offloaded to this node through a FLUIDOS contract.
+// Its resources are already accounted by the contract, which has reduced the Flavor of the node.
+func isContractPod(pod *corev1.Pod) bool {
+	return pod.Labels[liqoconsts.ManagedByLabelKey] == liqoconsts.ManagedByShadowPodValue
+}
+
+// podResources returns the resources reserved by a pod: the sum of its containers, or its largest init container
+// if greater, plus the pod overhead. If limits is set, the limit of each container replaces its request.
+func podResources(pod *corev1.Pod, limits bool) corev1.ResourceList {
+	containerResources := func(container *corev1.Container) corev1.ResourceList {
+		resources := container.Resources.Requests.DeepCopy()
+		if resources == nil {
+			resources = corev1.ResourceList{}
+		}
+		if limits {
+			for name, quantity := range container.Resources.Limits {
+				resources[name] = quantity.DeepCopy()
+			}
+		}
+		return resources
+	}
+
+	total := corev1.ResourceList{}
+	for i := range pod.Spec.Containers {
+		for name, quantity := range containerResources(&pod.Spec.Containers[i]) {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		for name, quantity := range containerResources(&pod.Spec.InitContainers[i]) {
+			if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+				total[name] = quantity
+			}
+		}
+	}
+	for name, quantity := range pod.Spec.Overhead {
+		sum := total[name]
+		sum.Add(quantity)
+		total[name] = sum
+	}
+
+	return total
+}
+
+// usedResources returns the CPU and memory in use on a node according to the capacity mode, and the number of pods
+// running on it. The pods offloaded through FLUIDOS contracts are not accounted, except for the usage mode, which
+// cannot tell them apart in the node metrics.
+func usedResources(nodeMetrics *metricsv1beta1.NodeMetrics, pods []corev1.Pod) (cpu, memory, podsCount resource.Quantity) {
+	cpu = *resource.NewMilliQuantity(0, resource.DecimalSI)
+	memory = *resource.NewQuantity(0, resource.BinarySI)
+	count := int64(0)
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || isContractPod(pod) {
+			continue
+		}
+		count++
+		if flags.CapacityMode == CapacityModeUsage {
+			continue
+		}
+		resources := podResources(pod, flags.CapacityMode == CapacityModeLimits)
+		cpu.Add(*resources.Cpu())
+		memory.Add(*resources.Memory())
+	}
+
+	if flags.CapacityMode == CapacityModeUsage {
+		cpu = nodeMetrics.Usage.Cpu().DeepCopy()
+		memory = nodeMetrics.Usage.Memory().DeepCopy()
+	}
+
+	return cpu, memory, *resource.NewQuantity(count, resource.DecimalSI)
+}
+
 // forgeResourceMetrics creates from params a new ResourceMetrics Struct.
-func forgeResourceMetrics(nodeMetrics *metricsv1beta1.NodeMetrics, node *corev1.Node) *models.ResourceMetrics {
+// It also returns the messages describing the annotations of the node that have been ignored.
+func forgeResourceMetrics(nodeMetrics *metricsv1beta1.NodeMetrics, node *corev1.Node, pods []corev1.Pod) (*models.ResourceMetrics, []string) {
 	// Get the total and used resources
 	cpuTotal := node.Status.Allocatable.Cpu().DeepCopy()
-	cpuUsed := nodeMetrics.Usage.Cpu().DeepCopy()
 	memoryTotal := node.Status.Allocatable.Memory().DeepCopy()
-	memoryUsed := nodeMetrics.Usage.Memory().DeepCopy()
 	podsTotal := node.Status.Allocatable.Pods().DeepCopy()
-	podsUsed := nodeMetrics.Usage.Pods().DeepCopy()
+	cpuUsed, memoryUsed, podsUsed := usedResources(nodeMetrics, pods)
 	ephemeralStorage := nodeMetrics.Usage.StorageEphemeral().DeepCopy()
 
 	// Compute the available resources
@@ -58,133 +148,15 @@ func forgeResourceMetrics(nodeMetrics *metricsv1beta1.NodeMetrics, node *corev1.
 	cpuAvail.Sub(cpuUsed)
 	memAvail.Sub(memoryUsed)
 	podsAvail.Sub(podsUsed)
-
-	var gpuMetrics models.GPUMetrics
-
-	annotations := node.ObjectMeta.Annotations
-	if annotations == nil {
-		annotations = map[string]string{}
-	}
-
-	if v, found := annotations["provider.fluidos.eu/name"]; found {
-		gpuMetrics.Provider = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/vendor"]; found {
-		gpuMetrics.Vendor = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/model"]; found {
-		gpuMetrics.Model = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/count"]; found {
-		count, _ := strconv.Atoi(v)
-		gpuMetrics.Count = int64(count)
-	}
-	if v, found := annotations["gpu.fluidos.eu/memory-per-gpu"]; found {
-		qty := resource.MustParse(v)
-
-		computed := resource.NewQuantity(0, resource.BinarySI)
-		for range gpuMetrics.Count {
-			computed.Add(qty)
-		}
-
-		gpuMetrics.MemoryTotal = *computed
-	}
-	if v, found := annotations["gpu.fluidos.eu/tier"]; found {
-		gpuMetrics.Tier = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/architecture"]; found {
-		gpuMetrics.Architecture = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/compute-capability"]; found {
-		gpuMetrics.ComputeCapability = v
-	}
-	if v, found := annotations["nvidia.fluidos.eu/mig-capable"]; found {
-		gpuMetrics.MultiInstance, _ = strconv.ParseBool(v)
-	}
-	if v, found := annotations["gpu.fluidos.eu/fp32-tflops"]; found {
-		fp32tFlops, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.FP32TFlops = fp32tFlops
-	}
-	if v, found := annotations["gpu.fluidos.eu/sharing-capable"]; found {
-		gpuMetrics.Shared, _ = strconv.ParseBool(v)
-	}
-	if v, found := annotations["gpu.fluidos.eu/sharing-strategy"]; found {
-		gpuMetrics.SharingStrategy = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/interconnect"]; found {
-		gpuMetrics.Interconnect = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/interconnect-bandwidth-gbps"]; found {
-		qty := resource.MustParse(v)
-		gpuMetrics.InterconnectBandwidth = qty
-	}
-	if v, found := annotations["gpu.fluidos.eu/cores"]; found {
-		qty := resource.MustParse(v)
-
-		computed := resource.NewQuantity(0, resource.BinarySI)
-		for range gpuMetrics.Count {
-			computed.Add(qty)
+	for _, avail := range []*resource.Quantity{&cpuAvail, &memAvail, &podsAvail} {
+		if avail.Sign() < 0 {
+			avail.Set(0)
 		}
-
-		gpuMetrics.CoresTotal = *computed
-	}
-	if v, found := annotations["gpu.fluidos.eu/clock-speed"]; found {
-		gpuMetrics.ClockSpeed = resource.MustParse(v)
-	}
-	if v, found := annotations["gpu.fluidos.eu/interruptible"]; found {
-		gpuMetrics.Interruptible, _ = strconv.ParseBool(v)
-	}
-	if v, found := annotations["gpu.fluidos.eu/dedicated"]; found {
-		gpuMetrics.Dedicated, _ = strconv.ParseBool(v)
-	}
-	if v, found := annotations["gpu.fluidos.eu/topology"]; found {
-		gpuMetrics.Topology = v
-	}
-	if v, found := annotations["gpu.fluidos.eu/multi-gpu-efficiency"]; found {
-		gpuMetrics.MultiGPUEfficiency = v
-	}
-	if v, found := annotations["cost.fluidos.eu/hourly-rate"]; found {
-		hourlyRate, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.HourlyRate = hourlyRate
-	}
-	if v, found := annotations["provider.fluidos.eu/preemptible"]; found {
-		gpuMetrics.PreEmptible, _ = strconv.ParseBool(v)
-	}
-	if v, found := annotations["workload.fluidos.eu/training-score"]; found {
-		score, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.TrainingScore = score
-	}
-	if v, found := annotations["workload.fluidos.eu/inference-score"]; found {
-		score, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.InferenceScore = score
-	}
-	if v, found := annotations["workload.fluidos.eu/hpc-score"]; found {
-		score, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.HPCScore = score
-	}
-	if v, found := annotations["workload.fluidos.eu/graphics-score"]; found {
-		score, _ := strconv.ParseFloat(v, 64)
-		gpuMetrics.GraphicsScore = score
-	}
-	if v, found := annotations["network.fluidos.eu/bandwidth-gbps"]; found {
-		qty := resource.MustParse(v)
-		gpuMetrics.NetworkBandwidth = qty
-	}
-	if v, found := annotations["network.fluidos.eu/latency-ms"]; found {
-		ms, _ := strconv.ParseInt(v, 10, 64)
-		gpuMetrics.NetworkLatencyMs = ms
-	}
-	if v, found := annotations["network.fluidos.eu/tier"]; found {
-		gpuMetrics.NetworkTier = v
 	}
 
-	if v, found := annotations["location.fluidos.eu/zone"]; found {
-		gpuMetrics.Region = v
-	}
-
-	if v, found := annotations["location.fluidos.eu/region"]; found {
-		gpuMetrics.Zone = v
-	}
+	// The GPUs detected on the node are described further by its annotations, which override the detected values
+	gpuMetrics := discoverGPU(node, pods)
+	invalid := applyNodeAnnotations(node.Annotations, &gpuMetrics)
 
 	return &models.ResourceMetrics{
 		CPUTotal:         cpuTotal,
@@ -195,7 +167,7 @@ func forgeResourceMetrics(nodeMetrics *metricsv1beta1.NodeMetrics, node *corev1.
 		PodsAvailable:    podsAvail,
 		EphemeralStorage: ephemeralStorage,
 		GPU:              gpuMetrics,
-	}
+	}, invalid
 }
 
 // forgeNodeInfo creates from params a new NodeInfo struct.
@@ -208,3 +180,60 @@ func forgeNodeInfo(node *corev1.Node, metrics *models.ResourceMetrics) *models.N
 		ResourceMetrics: *metrics,
 	}
 }
+
+// aggregateNodeInfos sums the resources of the nodes of a pool into a single NodeInfo,
+// and returns the largest resources available on a single node of the pool.
+// The GPUs of the pool are described by the first GPU model found, counting the GPUs of every node with the same model.
+func aggregateNodeInfos(name string, nodeInfos []*models.NodeInfo) (*models.NodeInfo, *nodecorev1alpha1.NodeLimits) {
+	pool := &models.NodeInfo{Name: name}
+	limits := &nodecorev1alpha1.NodeLimits{}
+
+	for i, nodeInfo := range nodeInfos {
+		// The architecture and operating system of the pool are reported only when shared by all its nodes
+		if i == 0 {
+			pool.Architecture = nodeInfo.Architecture
+			pool.OperatingSystem = nodeInfo.OperatingSystem
+		}
+		if pool.Architecture != nodeInfo.Architecture {
+			pool.Architecture = ""
+		}
+		if pool.OperatingSystem != nodeInfo.OperatingSystem {
+			pool.OperatingSystem = ""
+		}
+
+		metrics := &nodeInfo.ResourceMetrics
+		pool.ResourceMetrics.CPUTotal.Add(metrics.CPUTotal)
+		pool.ResourceMetrics.CPUAvailable.Add(metrics.CPUAvailable)
+		pool.ResourceMetrics.MemoryTotal.Add(metrics.MemoryTotal)
+		pool.ResourceMetrics.MemoryAvailable.Add(metrics.MemoryAvailable)
+		pool.ResourceMetrics.PodsTotal.Add(metrics.PodsTotal)
+		pool.ResourceMetrics.PodsAvailable.Add(metrics.PodsAvailable)
+		pool.ResourceMetrics.EphemeralStorage.Add(metrics.EphemeralStorage)
+
+		switch {
+		case metrics.GPU.Count == 0:
+		case pool.ResourceMetrics.GPU.Count == 0:
+			pool.ResourceMetrics.GPU = metrics.GPU
+			pool.ResourceMetrics.GPU.CoresTotal = metrics.GPU.CoresTotal.DeepCopy()
+			pool.ResourceMetrics.GPU.MemoryTotal = metrics.GPU.MemoryTotal.DeepCopy()
+			pool.ResourceMetrics.GPU.MIGProfiles = slices.Clone(metrics.GPU.MIGProfiles)
+		case pool.ResourceMetrics.GPU.Model == metrics.GPU.Model:
+			pool.ResourceMetrics.GPU.Count += metrics.GPU.Count
+			pool.ResourceMetrics.GPU.CoresTotal.Add(metrics.GPU.CoresTotal)
+			pool.ResourceMetrics.GPU.MemoryTotal.Add(metrics.GPU.MemoryTotal)
+			pool.ResourceMetrics.GPU.MIGProfiles = mergeMIGProfiles(pool.ResourceMetrics.GPU.MIGProfiles, metrics.GPU.MIGProfiles)
+		}
+
+		if metrics.CPUAvailable.Cmp(limits.CPU) > 0 {
+			limits.CPU = metrics.CPUAvailable.DeepCopy()
+		}
+		if metrics.MemoryAvailable.Cmp(limits.Memory) > 0 {
+			limits.Memory = metrics.MemoryAvailable.DeepCopy()
+		}
+		if metrics.PodsAvailable.Cmp(limits.Pods) > 0 {
+			limits.Pods = metrics.PodsAvailable.DeepCopy()
+		}
+	}
+
+	return pool, limits
+}
@@ -0,0 +1,161 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localresourcemanager
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+)
+
+// storageClasses returns the persistent storage offered through each StorageClass by the Flavor of the given nodes, sorted by name.
+// The storage is shared by the Flavors of all the resource nodes, so each Flavor is given the share of its nodes:
+// the quota configured for a StorageClass in the storage ConfigMap is split among all the resource nodes, and the capacity
+// reported by each CSIStorageCapacity object of the StorageClass among the resource nodes of its topology.
+func (r *NodeReconciler) storageClasses(ctx context.Context, nodes []client.Object) ([]nodecorev1alpha1.StorageClassCapacity, error) {
+	var resourceNodes corev1.NodeList
+	if err := r.Client.List(ctx, &resourceNodes, client.MatchingLabels{flags.ResourceNodeLabel: "true"}); err != nil {
+		return nil, err
+	}
+	allNodes := make([]client.Object, 0, len(resourceNodes.Items))
+	for i := range resourceNodes.Items {
+		allNodes = append(allNodes, &resourceNodes.Items[i])
+	}
+
+	var classes storagev1.StorageClassList
+	if err := r.Client.List(ctx, &classes); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(classes.Items))
+	for i := range classes.Items {
+		existing[classes.Items[i].Name] = true
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: consts.StorageConfigMapName, Namespace: flags.FluidosNamespace}, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+	}
+
+	offered := map[string]resource.Quantity{}
+	for class, raw := range cm.Data {
+		quota, err := resource.ParseQuantity(raw)
+		if err != nil || quota.Sign() < 0 {
+			return nil, fmt.Errorf("invalid storage quota %q of storage class %s", raw, class)
+		}
+		if !existing[class] {
+			ctrl.LoggerFrom(ctx).Info("Storage quota ignored, the storage class does not exist", "storageClass", class)
+			continue
+		}
+		offered[class] = apportion(quota, len(nodes), len(allNodes))
+	}
+
+	var capacities storagev1.CSIStorageCapacityList
+	if err := r.Client.List(ctx, &capacities); err != nil {
+		return nil, err
+	}
+	detected := map[string]resource.Quantity{}
+	for i := range capacities.Items {
+		capacity := &capacities.Items[i]
+		if _, configured := offered[capacity.StorageClassName]; configured || !existing[capacity.StorageClassName] || capacity.Capacity == nil {
+			continue
+		}
+		matches, err := topologyMatches(capacity.NodeTopology, nodes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid topology of CSIStorageCapacity %s/%s: %w", capacity.Namespace, capacity.Name, err)
+		}
+		if matches == 0 {
+			continue
+		}
+		// The nodes of the Flavor are resource nodes, so the topology matches at least as many of those
+		shared, err := topologyMatches(capacity.NodeTopology, allNodes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid topology of CSIStorageCapacity %s/%s: %w", capacity.Namespace, capacity.Name, err)
+		}
+		total := detected[capacity.StorageClassName]
+		total.Add(apportion(*capacity.Capacity, matches, shared))
+		detected[capacity.StorageClassName] = total
+	}
+	for class, capacity := range detected {
+		offered[class] = capacity
+	}
+
+	result := make([]nodecorev1alpha1.StorageClassCapacity, 0, len(offered))
+	for class, capacity := range offered {
+		if capacity.Sign() > 0 {
+			result = append(result, nodecorev1alpha1.StorageClassCapacity{Name: class, Capacity: capacity})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// topologyMatches returns how many of the given nodes the node topology of a CSIStorageCapacity includes.
+// A nil topology includes no node.
+func topologyMatches(topology *metav1.LabelSelector, nodes []client.Object) (int, error) {
+	if topology == nil {
+		return 0, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(topology)
+	if err != nil {
+		return 0, err
+	}
+	matches := 0
+	for _, node := range nodes {
+		if selector.Matches(labels.Set(node.GetLabels())) {
+			matches++
+		}
+	}
+	return matches, nil
+}
+
+// apportion returns the share of a quantity given to share nodes out of the total nodes it is split among, rounded down.
+func apportion(quantity resource.Quantity, share, total int) resource.Quantity {
+	if share >= total {
+		return quantity
+	}
+	value := new(big.Int).Mul(big.NewInt(quantity.Value()), big.NewInt(int64(share)))
+	value.Quo(value, big.NewInt(int64(total)))
+	return *resource.NewQuantity(value.Int64(), quantity.Format)
+}
+
+// setStorage sets the persistent storage offered by the K8Slice characteristics, in total and through each StorageClass.
+func setStorage(characteristics *nodecorev1alpha1.K8SliceCharacteristics, classes []nodecorev1alpha1.StorageClassCapacity) {
+	if len(classes) == 0 {
+		characteristics.Storage = nil
+		characteristics.StorageClasses = nil
+		return
+	}
+	total := resource.NewQuantity(0, resource.BinarySI)
+	for _, class := range classes {
+		total.Add(class.Capacity)
+	}
+	characteristics.Storage = total
+	characteristics.StorageClasses = classes
+}
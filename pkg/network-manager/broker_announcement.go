@@ -0,0 +1,159 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+)
+
+// BrokerAnnouncement is the message published by a Broker client on the announcements exchange.
+type BrokerAnnouncement struct {
+	nodecorev1alpha1.NodeIdentity
+	networkv1alpha1.BrokerMetric
+	// AvailableCPU is the amount of CPU offered by the available Flavors of the node.
+	AvailableCPU resource.Quantity `json:"availableCPU"`
+	// AvailableMemory is the amount of memory offered by the available Flavors of the node.
+	AvailableMemory resource.Quantity `json:"availableMemory"`
+	// AvailableGPU is the number of GPUs offered by the available Flavors of the node.
+	AvailableGPU resource.Quantity `json:"availableGPU"`
+	// FlavorTypes is the list of the Flavor types offered by the node.
+	FlavorTypes []nodecorev1alpha1.FlavorTypeIdentifier `json:"flavorTypes,omitempty"`
+}
+
+// forgeBrokerAnnouncement computes the announcement of the node from its current available Flavors.
+func forgeBrokerAnnouncement(ctx context.Context, cl client.Client,
+	id *nodecorev1alpha1.NodeIdentity, metric *networkv1alpha1.BrokerMetric) (*BrokerAnnouncement, error) {
+	announcement := &BrokerAnnouncement{
+		NodeIdentity:    *id,
+		AvailableCPU:    *resource.NewQuantity(0, resource.DecimalSI),
+		AvailableMemory: *resource.NewQuantity(0, resource.BinarySI),
+		AvailableGPU:    *resource.NewQuantity(0, resource.DecimalSI),
+	}
+	if metric != nil {
+		announcement.BrokerMetric = *metric
+	}
+
+	flavorList := &nodecorev1alpha1.FlavorList{}
+	if err := cl.List(ctx, flavorList, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Flavors: %s", err)
+		return nil, err
+	}
+
+	for i := range flavorList.Items {
+		flavor := &flavorList.Items[i]
+		// Only the available Flavors owned by this node are announced
+		if !flavor.Spec.Availability || flavor.Spec.Owner.NodeID != id.NodeID {
+			continue
+		}
+
+		flavorTypeIdentifier, flavorTypeData, err := nodecorev1alpha1.ParseFlavorType(flavor)
+		if err != nil {
+			klog.Errorf("Error when parsing Flavor %s type: %s", flavor.Name, err)
+			continue
+		}
+		announcement.addFlavorType(flavorTypeIdentifier)
+
+		if flavorTypeIdentifier == nodecorev1alpha1.TypeK8Slice {
+			k8slice := flavorTypeData.(nodecorev1alpha1.K8Slice)
+			announcement.AvailableCPU.Add(k8slice.Characteristics.CPU)
+			announcement.AvailableMemory.Add(k8slice.Characteristics.Memory)
+			if k8slice.Characteristics.Gpu != nil {
+				announcement.AvailableGPU.Add(k8slice.Characteristics.Gpu.Cores)
+			}
+		}
+	}
+
+	return announcement, nil
+}
+
+func (a *BrokerAnnouncement) addFlavorType(flavorType nodecorev1alpha1.FlavorTypeIdentifier) {
+	for _, ft := range a.FlavorTypes {
+		if ft == flavorType {
+			return
+		}
+	}
+	a.FlavorTypes = append(a.FlavorTypes, flavorType)
+}
+
+// headers returns the AMQP headers describing the domain, location and Flavor types of the announcement.
+// They are informative only: no queue is bound on them, and the subscribers select the announcements through their filter.
+func (a *BrokerAnnouncement) headers() amqp.Table {
+	flavorTypes := make([]string, len(a.FlavorTypes))
+	for i, ft := range a.FlavorTypes {
+		flavorTypes[i] = string(ft)
+	}
+	return amqp.Table{
+		"domain":      a.Domain,
+		"location":    a.Location,
+		"flavorTypes": strings.Join(flavorTypes, ","),
+	}
+}
+
+// matchBrokerFilter checks whether the announcement satisfies all the rules of the filter.
+// If not, it returns the reason of the mismatch.
+func matchBrokerFilter(filter *networkv1alpha1.BrokerFilter, a *BrokerAnnouncement) (bool, string) {
+	if filter == nil {
+		return true, ""
+	}
+	if len(filter.Domains) > 0 && !slices.Contains(filter.Domains, a.Domain) {
+		return false, fmt.Sprintf("domain %s not accepted", a.Domain)
+	}
+	if len(filter.Locations) > 0 && !slices.Contains(filter.Locations, a.Location) {
+		return false, fmt.Sprintf("location %s not accepted", a.Location)
+	}
+	if filter.MinCPU != nil && a.AvailableCPU.Cmp(*filter.MinCPU) < 0 {
+		return false, fmt.Sprintf("available CPU %s lower than %s", a.AvailableCPU.String(), filter.MinCPU.String())
+	}
+	if filter.MinMemory != nil && a.AvailableMemory.Cmp(*filter.MinMemory) < 0 {
+		return false, fmt.Sprintf("available memory %s lower than %s", a.AvailableMemory.String(), filter.MinMemory.String())
+	}
+	if filter.MinGPU != nil && a.AvailableGPU.Cmp(*filter.MinGPU) < 0 {
+		return false, fmt.Sprintf("available GPU %s lower than %s", a.AvailableGPU.String(), filter.MinGPU.String())
+	}
+	if len(filter.FlavorTypes) > 0 && !slices.ContainsFunc(a.FlavorTypes, func(ft nodecorev1alpha1.FlavorTypeIdentifier) bool {
+		return slices.Contains(filter.FlavorTypes, ft)
+	}) {
+		return false, fmt.Sprintf("none of the flavor types %v is offered", filter.FlavorTypes)
+	}
+	return true, ""
+}
+
+// buildOutboundMessage builds the announcement message and its headers from the current state of the node.
+func (bc *BrokerClient) buildOutboundMessage(ctx context.Context, cl client.Client) ([]byte, amqp.Table, error) {
+	announcement, err := forgeBrokerAnnouncement(ctx, cl, bc.ID, bc.metric)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg, err := json.Marshal(announcement)
+	if err != nil {
+		klog.Errorf("Error marshalling announcement: %s", err)
+		return nil, nil, err
+	}
+	return msg, announcement.headers(), nil
+}
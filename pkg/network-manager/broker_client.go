@@ -31,10 +31,7 @@ import (
 
 	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
-	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/getters"
-	"github.com/fluidos-project/node/pkg/utils/namings"
-	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 )
 
 // clusterRole
@@ -43,6 +40,7 @@ import (
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=flavors,verbs=get;list;watch
 
 // BrokerClient keeps all the necessary class data.
 type BrokerClient struct {
@@ -50,13 +48,16 @@ type BrokerClient struct {
 	ctx  context.Context
 	canc context.CancelFunc
 
+	cl         client.Client
+	tlsConfig  *tls.Config
 	subFlag    bool
 	pubFlag    bool
 	brokerName string
 	serverAddr string
 	clientCert *corev1.Secret
 	rootCert   *corev1.Secret
-	metric     string
+	metric     *networkv1alpha1.BrokerMetric
+	filter     *networkv1alpha1.BrokerFilter
 	clientName string
 	brokerConn *brokerConnection
 }
@@ -69,7 +70,6 @@ type brokerConnection struct {
 	ruleExchangeName     string
 	queueName            string
 	inboundMsgs          <-chan amqp.Delivery
-	outboundAnnounceMsg  []byte
 	outboundRuleMsg      []byte
 	confirms             chan amqp.Confirmation
 }
@@ -80,6 +80,7 @@ func (bc *BrokerClient) SetupBrokerClient(cl client.Client, broker *networkv1alp
 
 	bc.ctx, bc.canc = context.WithCancel(context.Background())
 	ctx := bc.ctx
+	bc.cl = cl
 	var err error
 
 	bc.ID = getters.GetNodeIdentity(ctx, cl)
@@ -95,17 +96,12 @@ func (bc *BrokerClient) SetupBrokerClient(cl client.Client, broker *networkv1alp
 	bc.brokerConn.announceExchangeName = "announcements_exchange"
 	bc.brokerConn.ruleExchangeName = "rules_exchange"
 
-	bc.brokerConn.outboundAnnounceMsg, err = json.Marshal(bc.ID)
-	if err != nil {
-		return err
-	}
-
 	switch role := broker.Spec.Role; role {
-	case "publisher":
+	case networkv1alpha1.BrokerRolePublisher:
 		bc.pubFlag = true
 		bc.subFlag = false
 		klog.Infof("brokerClient %s set as publisher only", bc.brokerName)
-	case "subscriber":
+	case networkv1alpha1.BrokerRoleSubscriber:
 		bc.pubFlag = false
 		bc.subFlag = true
 		klog.Infof("brokerClient %s set as subscriber only", bc.brokerName)
@@ -169,22 +165,24 @@ func (bc *BrokerClient) SetupBrokerClient(cl client.Client, broker *networkv1alp
 		klog.Errorf("Common Name extraction error: %v", err)
 	}
 
-	bc.brokerConn.outboundRuleMsg, err = json.Marshal(broker.Spec.Rule)
+	rule := broker.Spec.Rule
+	if rule == nil {
+		rule = &networkv1alpha1.BrokerRule{}
+	}
+	bc.brokerConn.outboundRuleMsg, err = json.Marshal(rule)
 	if err != nil {
-		klog.Errorf("Error reading rules JSON: %s", err)
+		klog.Errorf("Error marshalling rule: %s", err)
 	}
 
-	// Set the metric to be sent to the broker.
+	// Set the static metric to be sent to the broker, the remaining ones are computed at each publish.
 	bc.metric = broker.Spec.Metric
-	bc.brokerConn.outboundAnnounceMsg = nil
-	err = bc.buildOutboundMessage()
-	if err != nil {
-		klog.Errorf("Message building error: %v", err)
-	}
+
+	// Set the filter applied to the received announcements.
+	bc.filter = broker.Spec.Filter
 
 	klog.Infof("outbound msg: %s\n", bc.brokerConn.outboundRuleMsg)
 	// TLS config.
-	tlsConfig := &tls.Config{
+	bc.tlsConfig = &tls.Config{
 		Certificates: []tls.Certificate{cert},
 		RootCAs:      caCertPool,
 		ServerName:   bc.serverAddr,
@@ -193,53 +191,73 @@ func (bc *BrokerClient) SetupBrokerClient(cl client.Client, broker *networkv1alp
 
 	bc.clientName = bc.brokerConn.queueName
 
-	err = bc.brokerConnectionConfig(tlsConfig)
+	return nil
+}
+
+var _ Transport = &BrokerClient{}
 
-	return err
+// Name returns the name of the transport.
+func (bc *BrokerClient) Name() string {
+	return TransportAMQP + "/" + bc.brokerName
 }
 
-// ExecuteBrokerClient executes the Network Manager Broker routines.
-func (bc *BrokerClient) ExecuteBrokerClient(cl client.Client) error {
+// Start connects to the Broker and runs the Broker routines until the context is cancelled or the connection is lost.
+func (bc *BrokerClient) Start(ctx context.Context, registrar *Registrar) error {
+	if err := bc.brokerConnectionConfig(bc.tlsConfig); err != nil {
+		return err
+	}
+	defer bc.closeConnection()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Start sending announcement messages
 	klog.Info("executing broker client routines")
-	var err error
 	if bc.pubFlag {
-		go func() {
-			bc.publishOnBroker(bc.brokerConn.announceExchangeName, bc.brokerConn.outboundAnnounceMsg)
-		}()
+		go bc.publishOnBroker(ctx, bc.brokerConn.announceExchangeName, func() ([]byte, amqp.Table, error) {
+			return bc.buildOutboundMessage(ctx, bc.cl)
+		})
 	}
 
+	// Start sending rule messages
+	go bc.publishOnBroker(ctx, bc.brokerConn.ruleExchangeName, func() ([]byte, amqp.Table, error) {
+		return bc.brokerConn.outboundRuleMsg, nil, nil
+	})
+
 	// Start receiving announcement messages
 	if bc.subFlag {
-		go func() {
-			if err = bc.readMsgOnBroker(bc.ctx, cl); err != nil {
-				klog.ErrorS(err, "error receiving advertisement")
-			}
-		}()
+		return bc.readMsgOnBroker(ctx, registrar)
 	}
 
-	// Start sending rule messages
-	go func() {
-		bc.publishOnBroker(bc.brokerConn.ruleExchangeName, bc.brokerConn.outboundRuleMsg)
-	}()
-
-	return err
+	closed := bc.brokerConn.amqpConn.NotifyClose(make(chan *amqp.Error, 1))
+	select {
+	case err := <-closed:
+		return fmt.Errorf("connection to broker %s closed: %v", bc.brokerName, err)
+	case <-ctx.Done():
+		return nil
+	}
 }
 
-func (bc *BrokerClient) publishOnBroker(exchangeName string, message []byte) {
+func (bc *BrokerClient) publishOnBroker(ctx context.Context, exchangeName string, buildMessage func() ([]byte, amqp.Table, error)) {
 	ticker := time.NewTicker(10 * time.Second)
 	for {
 		select {
 		case <-ticker.C:
+			message, headers, err := buildMessage()
+			if err != nil {
+				klog.Errorf("Error building message for %s: %v", exchangeName, err)
+				continue
+			}
 
 			// Pub on exchange
-			err := bc.brokerConn.amqpChan.Publish(
+			err = bc.brokerConn.amqpChan.Publish(
 				exchangeName,
 				"",    // routingKey
 				false, // Mandatory: if not routable -> error
 				false, // Immediate
 				amqp.Publishing{
 					ContentType: "application/json",
+					Headers:     headers,
 					UserId:      bc.clientName,
 					Body:        message,
 					Expiration:  "30000", // TTL ms
@@ -259,7 +277,7 @@ func (bc *BrokerClient) publishOnBroker(exchangeName string, message []byte) {
 				klog.InfoS("No confirmation received, message status unknown from ", exchangeName)
 			}
 
-		case <-bc.ctx.Done():
+		case <-ctx.Done():
 			ticker.Stop()
 			klog.Info("Ticker stopped\n")
 			return
@@ -267,45 +285,38 @@ func (bc *BrokerClient) publishOnBroker(exchangeName string, message []byte) {
 	}
 }
 
-func (bc *BrokerClient) readMsgOnBroker(ctx context.Context, cl client.Client) error {
+func (bc *BrokerClient) readMsgOnBroker(ctx context.Context, registrar *Registrar) error {
 	klog.Info("Listening from Broker")
-	for d := range bc.brokerConn.inboundMsgs {
-		klog.Info("Received remote advertisement from BROKER\n")
-		var remote NetworkManager
-		err := json.Unmarshal(d.Body, &remote.ID)
-		if err != nil {
-			klog.Error("Error unmarshalling message: ", err)
-			continue
-		}
-		// Check if received advertisement is remote
-		if bc.ID.IP != remote.ID.IP {
-			// Create knownCluster CR
-			kc := &networkv1alpha1.KnownCluster{}
-
-			if err := cl.Get(ctx, client.ObjectKey{Name: namings.ForgeKnownClusterName(remote.ID.NodeID), Namespace: flags.FluidosNamespace}, kc); err != nil {
-				if client.IgnoreNotFound(err) == nil {
-					klog.InfoS("KnownCluster not found: creating form Broker", remote.ID.NodeID)
-
-					// Create new KnownCluster CR
-					if err := cl.Create(ctx, resourceforge.ForgeKnownCluster(remote.ID.NodeID, remote.ID.IP)); err != nil {
-						return err
-					}
-					klog.InfoS("KnownCluster created from Broker", "ID", remote.ID.NodeID)
-				}
-			} else {
-				klog.Info("KnownCluster already present: updating from Broker", remote.ID.NodeID)
-				kc.UpdateStatus()
-
-				// Update fetched KnownCluster CR
-				err := cl.Status().Update(ctx, kc)
-				if err != nil {
-					return err
-				}
-				klog.InfoS("KnownCluster updated from Broker", "ID", kc.ObjectMeta.Name)
+	for {
+		select {
+		case d, ok := <-bc.brokerConn.inboundMsgs:
+			if !ok {
+				return fmt.Errorf("delivery channel of broker %s closed", bc.brokerName)
 			}
+			klog.Info("Received remote advertisement from BROKER\n")
+			var remote BrokerAnnouncement
+			err := json.Unmarshal(d.Body, &remote)
+			if err != nil {
+				klog.Error("Error unmarshalling message: ", err)
+				continue
+			}
+			// Check if received advertisement matches the subscriber filter
+			if ok, reason := matchBrokerFilter(bc.filter, &remote); !ok {
+				klog.Infof("Advertisement from %s discarded by the filter of broker %s: %s", remote.NodeID, bc.brokerName, reason)
+				continue
+			}
+
+			registrar.Announce(&Announcement{
+				NodeIdentity: &remote.NodeIdentity,
+				Source: &networkv1alpha1.KnownClusterSource{
+					Type: networkv1alpha1.KnownClusterSourceBroker,
+					Name: bc.brokerName,
+				},
+			})
+		case <-ctx.Done():
+			return nil
 		}
 	}
-	return nil
 }
 
 func extractCNfromCert(certPEM *[]byte) (string, error) {
@@ -382,6 +393,19 @@ func (bc *BrokerClient) brokerConnectionConfig(tlsConfig *tls.Config) error {
 	return nil
 }
 
+func (bc *BrokerClient) closeConnection() {
+	if bc.brokerConn.amqpChan != nil {
+		if err := bc.brokerConn.amqpChan.Close(); err != nil {
+			klog.Errorf("Failed to close channel for broker '%s': %v", bc.brokerName, err)
+		}
+	}
+	if bc.brokerConn.amqpConn != nil {
+		if err := bc.brokerConn.amqpConn.Close(); err != nil {
+			klog.Errorf("Failed to close connection for broker '%s': %v", bc.brokerName, err)
+		}
+	}
+}
+
 func (bc *BrokerClient) extractSecret(cl client.Client, secretName, secretNamespace string, secretDest *corev1.Secret) error {
 	err := cl.Get(context.TODO(), client.ObjectKey{
 		Name:      secretName,
@@ -393,25 +417,3 @@ func (bc *BrokerClient) extractSecret(cl client.Client, secretName, secretNamesp
 	}
 	return nil
 }
-
-func (bc *BrokerClient) buildOutboundMessage() error {
-	var err error
-
-	var data map[string]interface{}
-	err = json.Unmarshal([]byte(bc.metric), &data)
-	if err != nil {
-		klog.Error("Error parsing JSON in builOutboundMessage:", err)
-		return err
-	}
-
-	data["ip"] = bc.ID.IP
-	data["domain"] = bc.ID.Domain
-	data["nodeID"] = bc.ID.NodeID
-
-	bc.brokerConn.outboundAnnounceMsg, err = json.Marshal(data)
-	if err != nil {
-		klog.Errorf("Error reading metric JSON: %s", err)
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,145 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/namings"
+	"github.com/fluidos-project/node/pkg/utils/resourceforge"
+)
+
+// gatewayProbeTimeout is the timeout used when measuring the round trip time towards a REAR gateway.
+const gatewayProbeTimeout = 2 * time.Second
+
+// gatewayProbeWorkers is the maximum number of REAR gateways probed at the same time.
+const gatewayProbeWorkers = 8
+
+// registerKnownCluster creates or updates the KnownCluster of the announcing node.
+func registerKnownCluster(ctx context.Context, cl client.Client,
+	remote *nodecorev1alpha1.NodeIdentity, source *networkv1alpha1.KnownClusterSource) error {
+	kc := &networkv1alpha1.KnownCluster{}
+
+	if err := cl.Get(ctx, client.ObjectKey{Name: namings.ForgeKnownClusterName(remote.NodeID), Namespace: flags.FluidosNamespace}, kc); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		klog.InfoS("KnownCluster not found: creating", "ID", remote.NodeID, "source", source.Type, "name", source.Name)
+
+		// Create new KnownCluster CR
+		kc = resourceforge.ForgeKnownCluster(remote, source)
+		status := kc.Status
+		if err := cl.Create(ctx, kc); err != nil {
+			return err
+		}
+		// The status is not persisted on creation
+		kc.Status = status
+		if err := cl.Status().Update(ctx, kc); err != nil {
+			return err
+		}
+		klog.InfoS("KnownCluster created", "ID", remote.NodeID)
+		return nil
+	}
+
+	klog.InfoS("KnownCluster already present: updating", "ID", remote.NodeID, "source", source.Type, "name", source.Name)
+
+	// Keep the identity of the KnownCluster up to date, since the address of a node may change.
+	// The source is the first one the node has been discovered from, so that it does not flip between transports.
+	// The static KnownClusters are pinned to their configuration instead.
+	if !isStaticKnownCluster(kc) && (kc.Spec.Address != remote.IP || kc.Spec.NodeID != remote.NodeID || kc.Spec.Domain != remote.Domain ||
+		kc.Spec.Source == nil) {
+		if kc.Spec.Address != remote.IP {
+			klog.InfoS("KnownCluster address changed", "ID", remote.NodeID, "old", kc.Spec.Address, "new", remote.IP)
+		}
+		kc.Spec.Address = remote.IP
+		kc.Spec.NodeID = remote.NodeID
+		kc.Spec.Domain = remote.Domain
+		if kc.Spec.Source == nil {
+			kc.Spec.Source = source
+		}
+		if err := cl.Update(ctx, kc); err != nil {
+			return err
+		}
+	}
+
+	// Update Status
+	kc.UpdateLastSeen()
+	if err := cl.Status().Update(ctx, kc); err != nil {
+		return err
+	}
+	klog.InfoS("KnownCluster updated", "ID", kc.ObjectMeta.Name)
+	return nil
+}
+
+// measureGatewayRTT measures the round trip time towards the REAR gateway of the KnownCluster,
+// as the time needed to establish a TCP connection with it.
+func measureGatewayRTT(ctx context.Context, address string) (time.Duration, error) {
+	dialer := &net.Dialer{Timeout: gatewayProbeTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	if err := conn.Close(); err != nil {
+		klog.Errorf("Error closing connection towards %s: %s", address, err)
+	}
+	return rtt, nil
+}
+
+// updateGatewayRTTs updates the gateway round trip time of the KnownClusters, probing at most gatewayProbeWorkers of them at a time.
+func updateGatewayRTTs(ctx context.Context, cl client.Client, kcs []*networkv1alpha1.KnownCluster) {
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, gatewayProbeWorkers)
+	for _, kc := range kcs {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			if err := updateGatewayRTT(ctx, cl, kc); err != nil {
+				klog.Errorf("Error updating the gateway RTT of KnownCluster %s: %s", kc.Name, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// updateGatewayRTT measures and stores the round trip time towards the REAR gateway of the KnownCluster.
+func updateGatewayRTT(ctx context.Context, cl client.Client, kc *networkv1alpha1.KnownCluster) error {
+	original := kc.DeepCopy()
+	rtt, err := measureGatewayRTT(ctx, kc.Spec.Address)
+	if err != nil {
+		klog.Infof("Unable to reach the gateway of KnownCluster %s at %s: %s", kc.Name, kc.Spec.Address, err)
+		if kc.Status.GatewayRTT == nil {
+			return nil
+		}
+		kc.Status.GatewayRTT = nil
+	} else {
+		kc.Status.GatewayRTT = &metav1.Duration{Duration: rtt.Round(time.Microsecond)}
+	}
+	// Patch the status only, since the KnownCluster may have been updated since it has been listed
+	return cl.Status().Patch(ctx, kc, client.MergeFrom(original))
+}
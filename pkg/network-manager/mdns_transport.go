@@ -0,0 +1,288 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"k8s.io/klog/v2"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+const (
+	// mdnsAddress is the IPv4 multicast address and port of mDNS.
+	mdnsAddress = "224.0.0.251:5353"
+	// mdnsService is the DNS-SD service type of the FLUIDOS Nodes.
+	mdnsService = "_fluidos._tcp.local."
+	// mdnsTTL is the TTL (in seconds) of the announced records.
+	mdnsTTL = 120
+)
+
+// MDNSTransport is the discovery transport announcing the node as a DNS-SD service over mDNS.
+// The identity of the node is carried in the TXT record of the service instance.
+type MDNSTransport struct {
+	ID    *nodecorev1alpha1.NodeIdentity
+	Iface *net.Interface
+}
+
+var _ Transport = &MDNSTransport{}
+
+// Name returns the name of the transport.
+func (t *MDNSTransport) Name() string {
+	return TransportMDNS
+}
+
+// Start runs the transport until the context is cancelled.
+func (t *MDNSTransport) Start(ctx context.Context, registrar *Registrar) error {
+	addr, err := net.ResolveUDPAddr("udp4", mdnsAddress)
+	if err != nil {
+		return err
+	}
+
+	announcement, err := t.buildAnnouncement()
+	if err != nil {
+		return err
+	}
+	query, err := buildMDNSQuery()
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", t.Iface, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read when the transport is stopped
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	// Ask the other nodes to announce themselves, then start announcing the local node
+	if _, err := conn.WriteToUDP(query, addr); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := conn.WriteToUDP(announcement, addr); err != nil {
+					klog.Errorf("Error sending mDNS announcement: %s", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	buffer := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var p dnsmessage.Parser
+		header, err := p.Start(buffer[:n])
+		if err != nil {
+			klog.Errorf("Error parsing mDNS message: %s", err)
+			continue
+		}
+
+		// Answer the queries for the FLUIDOS service
+		if !header.Response {
+			if isMDNSServiceQuery(&p) {
+				if _, err := conn.WriteToUDP(announcement, addr); err != nil {
+					klog.Errorf("Error answering mDNS query: %s", err)
+				}
+			}
+			continue
+		}
+
+		remote, err := parseMDNSAnnouncement(&p)
+		if err != nil {
+			klog.Errorf("Error parsing mDNS announcement: %s", err)
+			continue
+		}
+		// Not a FLUIDOS announcement
+		if remote == nil {
+			continue
+		}
+
+		registrar.Announce(&Announcement{
+			NodeIdentity: remote,
+			Source: &networkv1alpha1.KnownClusterSource{
+				Type: networkv1alpha1.KnownClusterSourceMulticast,
+				Name: TransportMDNS,
+			},
+		})
+	}
+}
+
+// buildAnnouncement builds the unsolicited mDNS response announcing the PTR, SRV, TXT and A records of the node.
+func (t *MDNSTransport) buildAnnouncement() ([]byte, error) {
+	service, err := dnsmessage.NewName(mdnsService)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := dnsmessage.NewName(t.ID.NodeID + "." + mdnsService)
+	if err != nil {
+		return nil, err
+	}
+	hostname, err := dnsmessage.NewName(t.ID.NodeID + ".local.")
+	if err != nil {
+		return nil, err
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	if err := b.PTRResource(mdnsResourceHeader(service, dnsmessage.TypePTR), dnsmessage.PTRResource{PTR: instance}); err != nil {
+		return nil, err
+	}
+	if err := b.TXTResource(mdnsResourceHeader(instance, dnsmessage.TypeTXT), dnsmessage.TXTResource{TXT: []string{
+		"nodeID=" + t.ID.NodeID,
+		"domain=" + t.ID.Domain,
+		"ip=" + t.ID.IP,
+	}}); err != nil {
+		return nil, err
+	}
+
+	// The address of the REAR gateway is announced as SRV and A records as well, when it is in the ip:port format
+	if host, portStr, err := net.SplitHostPort(t.ID.IP); err == nil {
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.SRVResource(mdnsResourceHeader(instance, dnsmessage.TypeSRV),
+			dnsmessage.SRVResource{Port: uint16(port), Target: hostname}); err != nil {
+			return nil, err
+		}
+		if ip := net.ParseIP(host).To4(); ip != nil {
+			if err := b.AResource(mdnsResourceHeader(hostname, dnsmessage.TypeA), dnsmessage.AResource{A: [4]byte(ip)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.Finish()
+}
+
+func mdnsResourceHeader(name dnsmessage.Name, resourceType dnsmessage.Type) dnsmessage.ResourceHeader {
+	return dnsmessage.ResourceHeader{
+		Name:  name,
+		Type:  resourceType,
+		Class: dnsmessage.ClassINET,
+		TTL:   mdnsTTL,
+	}
+}
+
+// buildMDNSQuery builds the mDNS query for the FLUIDOS service.
+func buildMDNSQuery() ([]byte, error) {
+	service, err := dnsmessage.NewName(mdnsService)
+	if err != nil {
+		return nil, err
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: service, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// isMDNSServiceQuery checks whether the mDNS query asks for the FLUIDOS service.
+func isMDNSServiceQuery(p *dnsmessage.Parser) bool {
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return false
+	}
+	for _, q := range questions {
+		if strings.EqualFold(q.Name.String(), mdnsService) && (q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMDNSAnnouncement extracts the identity of the announcing node from the TXT record of a FLUIDOS service instance.
+// It returns nil if the message does not announce a FLUIDOS service instance.
+func parseMDNSAnnouncement(p *dnsmessage.Parser) (*nodecorev1alpha1.NodeIdentity, error) {
+	if err := p.SkipAllQuestions(); err != nil {
+		return nil, err
+	}
+	for {
+		h, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Type != dnsmessage.TypeTXT || !strings.HasSuffix(strings.ToLower(h.Name.String()), "."+mdnsService) {
+			if err := p.SkipAnswer(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		txt, err := p.TXTResource()
+		if err != nil {
+			return nil, err
+		}
+		return parseMDNSTXT(txt.TXT)
+	}
+}
+
+func parseMDNSTXT(txt []string) (*nodecorev1alpha1.NodeIdentity, error) {
+	remote := &nodecorev1alpha1.NodeIdentity{}
+	for _, entry := range txt {
+		key, value, _ := strings.Cut(entry, "=")
+		switch key {
+		case "nodeID":
+			remote.NodeID = value
+		case "domain":
+			remote.Domain = value
+		case "ip":
+			remote.IP = value
+		}
+	}
+	if remote.NodeID == "" || remote.IP == "" {
+		return nil, fmt.Errorf("incomplete TXT record %v", txt)
+	}
+	return remote, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+// MulticastTransport is the discovery transport announcing the node through UDP multicast on the LAN.
+type MulticastTransport struct {
+	ID        *nodecorev1alpha1.NodeIdentity
+	Multicast string
+	Iface     *net.Interface
+}
+
+var _ Transport = &MulticastTransport{}
+
+// Name returns the name of the transport.
+func (t *MulticastTransport) Name() string {
+	return TransportMulticast
+}
+
+// Start runs the transport until the context is cancelled.
+func (t *MulticastTransport) Start(ctx context.Context, registrar *Registrar) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	// Start sending multicast messages
+	go func() {
+		errs <- t.sendMulticastMessage(ctx)
+	}()
+	// Start receiving multicast messages
+	go func() {
+		errs <- t.receiveMulticastMessage(ctx, registrar)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (t *MulticastTransport) sendMulticastMessage(ctx context.Context) error {
+	message, err := json.Marshal(t.ID)
+	if err != nil {
+		return err
+	}
+	laddr, err := t.Iface.Addrs()
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{
+		LocalAddr: &net.UDPAddr{
+			IP:   laddr[0].(*net.IPNet).IP,
+			Port: 0,
+		},
+	}
+	conn, err := dialer.Dial("udp", t.Multicast)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ticker := time.NewTicker(5 * time.Second)
+	for {
+		select {
+		case <-ticker.C:
+			_, err = conn.Write(message)
+			if err != nil {
+				return err
+			}
+			klog.Info("Advertisement multicasted")
+		case <-ctx.Done():
+			ticker.Stop()
+			return nil
+		}
+	}
+}
+
+func (t *MulticastTransport) receiveMulticastMessage(ctx context.Context, registrar *Registrar) error {
+	addr, err := net.ResolveUDPAddr("udp", t.Multicast)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.ListenMulticastUDP("udp", t.Iface, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read when the transport is stopped
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buffer := make([]byte, 1024)
+
+	for {
+		n, _, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		var remote nodecorev1alpha1.NodeIdentity
+		err = json.Unmarshal(buffer[:n], &remote)
+		if err != nil {
+			klog.Error("Error unmarshalling message: ", err)
+			continue
+		}
+
+		registrar.Announce(&Announcement{
+			NodeIdentity: &remote,
+			Source: &networkv1alpha1.KnownClusterSource{
+				Type: networkv1alpha1.KnownClusterSourceMulticast,
+				Name: TransportMulticast,
+			},
+		})
+	}
+}
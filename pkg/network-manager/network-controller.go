@@ -16,10 +16,10 @@ package networkmanager
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net"
 	"os"
+	"slices"
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,10 +29,7 @@ import (
 
 	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
-	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/getters"
-	"github.com/fluidos-project/node/pkg/utils/namings"
-	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 	"github.com/fluidos-project/node/pkg/utils/tools"
 )
 
@@ -49,12 +46,16 @@ type NetworkManager struct {
 	Multicast            string
 	Iface                *net.Interface
 	EnableLocalDiscovery bool
+	StaticPeers          map[string]string
+	Transports           []string
+	Registrar            *Registrar
 }
 
 // BrokerReconciler reconciles a Broker object.
 type BrokerReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
+	Registrar     *Registrar
 	ActiveBrokers []*BrokerClient
 }
 
@@ -144,12 +145,13 @@ func Setup(ctx context.Context, cl client.Client, nm *NetworkManager, cniInterfa
 		return fmt.Errorf("failed to get Node Identity")
 	}
 	multicastAddress := os.Getenv("MULTICAST_ADDRESS")
-	if multicastAddress == "" {
+	if multicastAddress == "" && nm.EnableLocalDiscovery && slices.Contains(nm.Transports, TransportMulticast) {
 		return fmt.Errorf("failed to get multicast address")
 	}
 	nm.ID = nodeIdentity
 	nm.Multicast = multicastAddress
-	if nm.EnableLocalDiscovery {
+	nm.Registrar = NewRegistrar(cl, nodeIdentity)
+	if nm.EnableLocalDiscovery && *cniInterface != "" {
 		ifi, err := net.InterfaceByName(*cniInterface)
 		if err != nil {
 			return err
@@ -163,130 +165,53 @@ func Setup(ctx context.Context, cl client.Client, nm *NetworkManager, cniInterfa
 
 // Execute the Network Manager routines.
 func Execute(ctx context.Context, cl client.Client, nm *NetworkManager) error {
-	// Start sending multicast messages
-	if nm.EnableLocalDiscovery {
-		go func() {
-			if err := sendMulticastMessage(ctx, nm); err != nil {
-				klog.ErrorS(err, "Error sending advertisemente")
+	// Start registering the announcements received by the transports
+	go nm.Registrar.Run(ctx)
+
+	// Start the LAN discovery transports, the AMQP ones are started by the BrokerReconciler
+	for _, name := range nm.Transports {
+		var transport Transport
+		switch name {
+		case TransportMulticast:
+			if !nm.EnableLocalDiscovery {
+				continue
+			}
+			if nm.Iface == nil {
+				return fmt.Errorf("the %s transport requires the CNI interface", TransportMulticast)
 			}
-		}()
-		// Start receiving multicast messages
-		go func() {
-			if err := receiveMulticastMessage(ctx, cl, nm); err != nil {
-				klog.ErrorS(err, "Error receiving advertisement")
+			transport = &MulticastTransport{ID: nm.ID, Multicast: nm.Multicast, Iface: nm.Iface}
+		case TransportMDNS:
+			if !nm.EnableLocalDiscovery {
+				continue
 			}
-		}()
+			transport = &MDNSTransport{ID: nm.ID, Iface: nm.Iface}
+		default:
+			continue
+		}
+		go RunTransport(ctx, transport, nm.Registrar)
 	}
+
 	// Do housekeeping
 	go func() {
-		if err := doHousekeeping(ctx, cl); err != nil {
+		if err := doHousekeeping(ctx, cl, nm); err != nil {
 			klog.ErrorS(err, "Error doing housekeeping")
 		}
 	}()
 	return nil
 }
 
-func sendMulticastMessage(ctx context.Context, nm *NetworkManager) error {
-	message, err := json.Marshal(nm.ID)
-	if err != nil {
-		return err
-	}
-	laddr, err := nm.Iface.Addrs()
-	if err != nil {
-		return err
-	}
-	dialer := &net.Dialer{
-		LocalAddr: &net.UDPAddr{
-			IP:   laddr[0].(*net.IPNet).IP,
-			Port: 0,
-		},
-	}
-	conn, err := dialer.Dial("udp", nm.Multicast)
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-	ticker := time.NewTicker(5 * time.Second)
-	for {
-		select {
-		case <-ticker.C:
-			_, err = conn.Write(message)
-			if err != nil {
-				return err
-			}
-			klog.Info("Advertisement multicasted")
-		case <-ctx.Done():
-			ticker.Stop()
-			return nil
-		}
-	}
-}
-
-func receiveMulticastMessage(ctx context.Context, cl client.Client, local *NetworkManager) error {
-	addr, err := net.ResolveUDPAddr("udp", local.Multicast)
-	if err != nil {
-		return err
-	}
-
-	conn, err := net.ListenMulticastUDP("udp", local.Iface, addr)
-	if err != nil {
-		return err
-	}
-	defer conn.Close()
-	buffer := make([]byte, 1024)
-
-	for {
-		n, _, err := conn.ReadFromUDP(buffer)
-		if err != nil {
-			return err
-		}
-		var remote NetworkManager
-		err = json.Unmarshal(buffer[:n], &remote.ID)
-		if err != nil {
-			klog.Error("Error unmarshalling message: ", err)
-			continue
-		}
-
-		// Check if received advertisement is remote
-		if local.ID.IP != remote.ID.IP {
-			klog.InfoS("Received remote advertisement", "ID", remote.ID.NodeID, "Address", remote.ID.IP)
-
-			// Fetch the KnownCluster instance if already present
-			kc := &networkv1alpha1.KnownCluster{}
-
-			if err := cl.Get(ctx, client.ObjectKey{Name: namings.ForgeKnownClusterName(remote.ID.NodeID), Namespace: flags.FluidosNamespace}, kc); err != nil {
-				if client.IgnoreNotFound(err) == nil {
-					klog.Info("KnownCluster not found: creating")
-
-					// Create new KnownCluster CR
-					if err := cl.Create(ctx, resourceforge.ForgeKnownCluster(remote.ID.NodeID, remote.ID.IP)); err != nil {
-						return err
-					}
-					klog.InfoS("KnownCluster created", "ID", remote.ID.NodeID)
-				}
-			} else {
-				klog.Info("KnownCluster already present: updating")
-				// Update Status
-				kc.UpdateStatus()
-
-				// Update fetched KnownCluster CR
-				err := cl.Status().Update(ctx, kc)
-				if err != nil {
-					return err
-				}
-				klog.InfoS("KnownCluster updated", "ID", kc.ObjectMeta.Name)
-			}
-		}
-	}
-}
-
-func doHousekeeping(ctx context.Context, cl client.Client) error {
+func doHousekeeping(ctx context.Context, cl client.Client, nm *NetworkManager) error {
 	ticker := time.NewTicker(20 * time.Second)
 	for {
 		select {
 		case <-ticker.C:
 			klog.Info("Starting housekeeping")
 
+			// Align the static KnownClusters with the configured ones
+			if err := syncStaticPeers(ctx, cl, nm); err != nil {
+				klog.Errorf("Error syncing static peers: %s", err)
+			}
+
 			// Retrieve KnownClusterList
 			kcList := networkv1alpha1.KnownClusterList{}
 			err := cl.List(ctx, &kcList)
@@ -298,17 +223,30 @@ func doHousekeeping(ctx context.Context, cl client.Client) error {
 				continue
 			}
 
-			// Remove all KnownCluster CR with expiration time < now
+			// Remove all KnownCluster CR with expiration time < now, except the static ones which are health-checked instead
+			var alive []*networkv1alpha1.KnownCluster
 			for i := range kcList.Items {
 				kc := &kcList.Items[i]
+				if isStaticKnownCluster(kc) {
+					if err := checkStaticKnownCluster(ctx, cl, kc); err != nil {
+						klog.Errorf("Error checking static KnownCluster %s: %s", kc.Name, err)
+					}
+					continue
+				}
 				if tools.CheckExpiration(kc.Status.ExpirationTime) {
 					err := cl.Delete(ctx, kc)
 					klog.InfoS("KnownCluster expired and deleted", "ID", kc.Name)
 					if err != nil {
 						return err
 					}
+					continue
 				}
+
+				alive = append(alive, kc)
 			}
+
+			// Measure the round trip time towards the gateway of the alive KnownClusters
+			updateGatewayRTTs(ctx, cl, alive)
 		case <-ctx.Done():
 			ticker.Stop()
 			return nil
@@ -335,23 +273,14 @@ func (r *BrokerReconciler) brokerCreate(broker *networkv1alpha1.Broker) error {
 	if err = bc.SetupBrokerClient(r.Client, broker); err != nil {
 		return err
 	}
-	if err = bc.ExecuteBrokerClient(r.Client); err != nil {
-		return err
-	}
+	go RunTransport(bc.ctx, &bc, r.Registrar)
 	r.ActiveBrokers = append(r.ActiveBrokers, &bc)
 	return nil
 }
 
 // Delete the clientBroker.
 func (r *BrokerReconciler) brokerDelete(brokerCl *BrokerClient, index int) error {
-	if err := brokerCl.brokerConn.amqpChan.Close(); err != nil {
-		klog.Errorf("Failed to close channel for broker '%s': %v", brokerCl.brokerName, err)
-		return err
-	}
-	if err := brokerCl.brokerConn.amqpConn.Close(); err != nil {
-		klog.Errorf("Failed to close connection for broker '%s': %v", brokerCl.brokerName, err)
-		return err
-	}
+	// Stopping the transport closes the connection to the broker
 	brokerCl.canc()
 	r.ActiveBrokers = append(r.ActiveBrokers[:index], r.ActiveBrokers[index+1:]...)
 	return nil
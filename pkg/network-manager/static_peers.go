@@ -0,0 +1,191 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/namings"
+)
+
+const (
+	// staticPeersFlagSource is the name of the source of the static peers configured through flag.
+	staticPeersFlagSource = "flag"
+	// staticPeersConfigMapSource is the name of the source of the static peers configured through ConfigMap.
+	staticPeersConfigMapSource = "configmap"
+)
+
+// ParseStaticPeers parses a comma separated list of static peers in the nodeID=address format.
+func ParseStaticPeers(peers string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, peer := range strings.Split(peers, ",") {
+		peer = strings.TrimSpace(peer)
+		if peer == "" {
+			continue
+		}
+		nodeID, address, found := strings.Cut(peer, "=")
+		if !found || nodeID == "" || address == "" {
+			return nil, fmt.Errorf("invalid static peer %q, expected nodeID=address", peer)
+		}
+		result[nodeID] = address
+	}
+	return result, nil
+}
+
+// syncStaticPeers creates, updates and deletes the static KnownClusters configured through flag and ConfigMap.
+// The KnownClusters manually created with a static source are left untouched.
+func syncStaticPeers(ctx context.Context, cl client.Client, nm *NetworkManager) error {
+	peers := make(map[string]*networkv1alpha1.KnownClusterSource)
+	addresses := make(map[string]string)
+
+	for nodeID, address := range nm.StaticPeers {
+		peers[nodeID] = &networkv1alpha1.KnownClusterSource{Type: networkv1alpha1.KnownClusterSourceStatic, Name: staticPeersFlagSource}
+		addresses[nodeID] = address
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: consts.StaticPeersConfigMapName, Namespace: flags.FluidosNamespace}, cm); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	} else {
+		for nodeID, address := range cm.Data {
+			peers[nodeID] = &networkv1alpha1.KnownClusterSource{Type: networkv1alpha1.KnownClusterSourceStatic, Name: staticPeersConfigMapSource}
+			addresses[nodeID] = strings.TrimSpace(address)
+		}
+	}
+
+	for nodeID, source := range peers {
+		if nodeID == nm.ID.NodeID {
+			continue
+		}
+		if err := registerStaticKnownCluster(ctx, cl, nodeID, addresses[nodeID], source); err != nil {
+			klog.Errorf("Error registering static KnownCluster %s: %s", nodeID, err)
+		}
+	}
+
+	// Remove the static KnownClusters no longer configured
+	kcList := networkv1alpha1.KnownClusterList{}
+	if err := cl.List(ctx, &kcList, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		return err
+	}
+	for i := range kcList.Items {
+		kc := &kcList.Items[i]
+		if !isStaticKnownCluster(kc) || (kc.Spec.Source.Name != staticPeersFlagSource && kc.Spec.Source.Name != staticPeersConfigMapSource) {
+			continue
+		}
+		if _, ok := peers[kc.Spec.NodeID]; !ok {
+			if err := cl.Delete(ctx, kc); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			klog.InfoS("Static KnownCluster no longer configured and deleted", "ID", kc.Name)
+		}
+	}
+	return nil
+}
+
+// registerStaticKnownCluster creates or updates a static KnownCluster configured through flag or ConfigMap.
+func registerStaticKnownCluster(ctx context.Context, cl client.Client, nodeID, address string,
+	source *networkv1alpha1.KnownClusterSource) error {
+	kc := &networkv1alpha1.KnownCluster{}
+	err := cl.Get(ctx, client.ObjectKey{Name: namings.ForgeKnownClusterName(nodeID), Namespace: flags.FluidosNamespace}, kc)
+	switch {
+	case client.IgnoreNotFound(err) != nil:
+		return err
+	case err != nil:
+		return registerKnownCluster(ctx, cl, &nodecorev1alpha1.NodeIdentity{NodeID: nodeID, IP: address}, source)
+	case kc.Spec.Address != address || kc.Spec.NodeID != nodeID || kc.Spec.Source == nil || *kc.Spec.Source != *source:
+		kc.Spec.Address = address
+		kc.Spec.NodeID = nodeID
+		kc.Spec.Source = source
+		return cl.Update(ctx, kc)
+	default:
+		return nil
+	}
+}
+
+// isStaticKnownCluster checks whether the KnownCluster has been statically configured.
+func isStaticKnownCluster(kc *networkv1alpha1.KnownCluster) bool {
+	return kc.Spec.Source != nil && kc.Spec.Source.Type == networkv1alpha1.KnownClusterSourceStatic
+}
+
+// checkStaticKnownCluster checks the health of a static KnownCluster through its REAR gateway and updates its status.
+func checkStaticKnownCluster(ctx context.Context, cl client.Client, kc *networkv1alpha1.KnownCluster) error {
+	remote, rtt, err := checkGatewayHealth(ctx, kc.Spec.Address)
+	switch {
+	case err != nil:
+		kc.Status.Phase = networkv1alpha1.KnownClusterUnreachable
+		kc.Status.Message = err.Error()
+		kc.Status.GatewayRTT = nil
+	case kc.Spec.NodeID != "" && remote.NodeID != kc.Spec.NodeID:
+		kc.Status.Phase = networkv1alpha1.KnownClusterUnreachable
+		kc.Status.Message = fmt.Sprintf("gateway at %s belongs to node %s, expected %s", kc.Spec.Address, remote.NodeID, kc.Spec.NodeID)
+		kc.Status.GatewayRTT = nil
+	default:
+		kc.Status.Phase = networkv1alpha1.KnownClusterReady
+		kc.Status.Message = "REAR gateway reachable and ready"
+		kc.Status.GatewayRTT = &metav1.Duration{Duration: rtt.Round(time.Microsecond)}
+	}
+
+	if kc.Status.Phase != networkv1alpha1.KnownClusterReady {
+		klog.Infof("Static KnownCluster %s unreachable: %s", kc.Name, kc.Status.Message)
+	}
+
+	kc.UpdateStatus()
+	return cl.Status().Update(ctx, kc)
+}
+
+// checkGatewayHealth contacts the health endpoint of a REAR gateway, returning the identity of the node and the round trip time.
+func checkGatewayHealth(ctx context.Context, address string) (*nodecorev1alpha1.NodeIdentity, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, gatewayProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s%s", address, consts.GatewayHealthRoute), http.NoBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	rtt := time.Since(start)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("REAR gateway at %s not ready: received status code %d", address, resp.StatusCode)
+	}
+
+	remote := &nodecorev1alpha1.NodeIdentity{}
+	if err := json.NewDecoder(resp.Body).Decode(remote); err != nil {
+		return nil, 0, fmt.Errorf("invalid health response from REAR gateway at %s: %w", address, err)
+	}
+	return remote, rtt, nil
+}
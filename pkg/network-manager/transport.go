@@ -0,0 +1,157 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	networkv1alpha1 "github.com/fluidos-project/node/apis/network/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+)
+
+const (
+	// TransportMulticast is the name of the discovery transport based on UDP multicast.
+	TransportMulticast = "multicast"
+	// TransportAMQP is the name of the discovery transport based on the AMQP Brokers.
+	TransportAMQP = "amqp"
+	// TransportMDNS is the name of the discovery transport based on DNS-SD over mDNS.
+	TransportMDNS = "mdns"
+
+	// announcementsBufferSize is the number of announcements the Registrar can buffer before dropping them.
+	announcementsBufferSize = 256
+	// transportMaxBackoff is the maximum delay before restarting a failed transport.
+	transportMaxBackoff = time.Minute
+)
+
+// Transport is a discovery transport, which announces the local node and receives the announcements of the remote ones.
+type Transport interface {
+	// Name returns the name of the transport.
+	Name() string
+	// Start runs the transport, handing the received announcements to the Registrar, until the context is cancelled.
+	// It returns an error if the transport cannot continue, e.g. because its connection has been lost.
+	Start(ctx context.Context, registrar *Registrar) error
+}
+
+// Announcement is an announcement of a remote node received by a discovery transport.
+type Announcement struct {
+	// NodeIdentity of the announcing node.
+	NodeIdentity *nodecorev1alpha1.NodeIdentity
+	// Source from which the announcement has been received.
+	Source *networkv1alpha1.KnownClusterSource
+}
+
+// Registrar creates and updates the KnownClusters from the announcements received by all the discovery transports.
+type Registrar struct {
+	client        client.Client
+	localID       *nodecorev1alpha1.NodeIdentity
+	announcements chan *Announcement
+}
+
+// NewRegistrar creates a new Registrar.
+func NewRegistrar(cl client.Client, localID *nodecorev1alpha1.NodeIdentity) *Registrar {
+	return &Registrar{
+		client:        cl,
+		localID:       localID,
+		announcements: make(chan *Announcement, announcementsBufferSize),
+	}
+}
+
+// Announce hands an announcement to the Registrar. It never blocks the transport: if the Registrar is overloaded, the announcement is dropped.
+func (r *Registrar) Announce(announcement *Announcement) {
+	select {
+	case r.announcements <- announcement:
+	default:
+		klog.Errorf("Registrar overloaded: announcement from %s dropped", announcement.Source.Type)
+	}
+}
+
+// Run registers the received announcements as KnownClusters until the context is cancelled.
+// Errors are logged and never stop the Registrar.
+func (r *Registrar) Run(ctx context.Context) {
+	for {
+		select {
+		case announcement := <-r.announcements:
+			if err := r.register(ctx, announcement); err != nil {
+				klog.Errorf("Error registering announcement: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Registrar) register(ctx context.Context, announcement *Announcement) error {
+	remote := announcement.NodeIdentity
+	if remote == nil || remote.NodeID == "" || remote.IP == "" {
+		return fmt.Errorf("invalid announcement from %s %s: missing node identity", announcement.Source.Type, announcement.Source.Name)
+	}
+
+	// Check if received advertisement is remote
+	if remote.NodeID == r.localID.NodeID || remote.IP == r.localID.IP {
+		return nil
+	}
+
+	klog.InfoS("Received remote advertisement", "ID", remote.NodeID, "Address", remote.IP, "source", announcement.Source.Type, "name", announcement.Source.Name)
+	return registerKnownCluster(ctx, r.client, remote, announcement.Source)
+}
+
+// RunTransport runs the transport until the context is cancelled, restarting it with an exponential backoff when it fails.
+func RunTransport(ctx context.Context, transport Transport, registrar *Registrar) {
+	backoff := time.Second
+	for {
+		klog.Infof("Starting discovery transport %s", transport.Name())
+		started := time.Now()
+		err := transport.Start(ctx, registrar)
+		if ctx.Err() != nil {
+			klog.Infof("Discovery transport %s stopped", transport.Name())
+			return
+		}
+		// A transport which has been running for a while is restarted promptly
+		if time.Since(started) > transportMaxBackoff {
+			backoff = time.Second
+		}
+		klog.Errorf("Discovery transport %s failed, restarting in %s: %v", transport.Name(), backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(2*backoff, transportMaxBackoff)
+	}
+}
+
+// ParseTransports parses a comma separated list of discovery transports.
+func ParseTransports(transports string) ([]string, error) {
+	var result []string
+	for _, transport := range strings.Split(transports, ",") {
+		transport = strings.TrimSpace(transport)
+		switch transport {
+		case "":
+			continue
+		case TransportMulticast, TransportAMQP, TransportMDNS:
+			result = append(result, transport)
+		default:
+			return nil, fmt.Errorf("unknown discovery transport %q", transport)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package contractmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/rear-controller/gateway"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/getters"
+	"github.com/fluidos-project/node/pkg/utils/parseutil"
+	"github.com/fluidos-project/node/pkg/utils/resourceforge"
+)
+
+// ContractReconciler enforces the expiration of Contracts, on both the buyer and the seller side,
+// and renews or amends the Contracts bought by this node when requested.
+type ContractReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Gateway  *gateway.Gateway
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=reservation.fluidos.eu,resources=contracts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=reservation.fluidos.eu,resources=contracts/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations,verbs=get;list;watch
+//+kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile warns when a Contract is about to expire, releases its Allocation once it has expired,
+// and renews or amends it when the buyer sets the renewal or the amendment annotation.
+func (r *ContractReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx, "contract", req.NamespacedName)
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	var contract reservationv1alpha1.Contract
+	if err := r.Get(ctx, req.NamespacedName, &contract); client.IgnoreNotFound(err) != nil {
+		klog.Errorf("Error when getting Contract %s before reconcile: %s", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	} else if err != nil {
+		klog.Infof("Contract %s not found, probably deleted", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if contract.Annotations[consts.FluidosContractRenewal] == "true" {
+		return r.renewContract(ctx, req, &contract)
+	}
+	if _, ok := contract.Annotations[consts.FluidosContractAmendment]; ok {
+		return r.amendContract(ctx, req, &contract)
+	}
+
+	// Contracts without expiration are not time limited
+	if contract.Spec.ExpirationTime == "" {
+		return ctrl.Result{}, nil
+	}
+	expiration, err := time.Parse(time.RFC3339, contract.Spec.ExpirationTime)
+	if err != nil {
+		klog.Errorf("Error when parsing expiration time of Contract %s: %s", req.NamespacedName, err)
+		return ctrl.Result{}, nil
+	}
+
+	remaining := time.Until(expiration)
+	switch {
+	case remaining <= 0:
+		return r.expireContract(ctx, req, &contract)
+	case remaining <= flags.ExpirationWarning:
+		if meta.SetStatusCondition(&contract.Status.Conditions, metav1.Condition{
+			Type:    reservationv1alpha1.ContractExpiring,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ExpirationApproaching",
+			Message: "Contract expires at " + contract.Spec.ExpirationTime,
+		}) {
+			klog.Infof("Contract %s expires at %s", req.NamespacedName, contract.Spec.ExpirationTime)
+			r.Recorder.Event(&contract, corev1.EventTypeWarning, "Expiring", "Contract expires at "+contract.Spec.ExpirationTime)
+			if err := r.Status().Update(ctx, &contract); err != nil {
+				klog.Errorf("Error when updating Contract %s status: %s", req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	default:
+		if meta.SetStatusCondition(&contract.Status.Conditions, metav1.Condition{
+			Type:    reservationv1alpha1.ContractExpiring,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InForce",
+			Message: "Contract expires at " + contract.Spec.ExpirationTime,
+		}) {
+			if err := r.Status().Update(ctx, &contract); err != nil {
+				klog.Errorf("Error when updating Contract %s status: %s", req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: remaining - flags.ExpirationWarning}, nil
+	}
+}
+
+// expireContract releases the Allocation of an expired Contract, which tears down the peering and frees the resources.
+func (r *ContractReconciler) expireContract(ctx context.Context, req ctrl.Request, contract *reservationv1alpha1.Contract) (ctrl.Result, error) {
+	allocation, err := getters.GetAllocationByContractName(ctx, r.Client, contract.Name)
+	if err == nil && allocation.Status.Status != nodecorev1alpha1.Released {
+		klog.Infof("Contract %s expired, releasing Allocation %s", req.NamespacedName, allocation.Name)
+		allocation.SetStatus(nodecorev1alpha1.Released, "Contract "+contract.Name+" expired")
+		if err := r.Status().Update(ctx, allocation); err != nil {
+			klog.Errorf("Error when releasing Allocation %s: %s", allocation.Name, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	changed := meta.SetStatusCondition(&contract.Status.Conditions, metav1.Condition{
+		Type:    reservationv1alpha1.ContractExpiring,
+		Status:  metav1.ConditionFalse,
+		Reason:  "Expired",
+		Message: "Contract expired at " + contract.Spec.ExpirationTime,
+	})
+	if meta.SetStatusCondition(&contract.Status.Conditions, metav1.Condition{
+		Type:    reservationv1alpha1.ContractExpired,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Expired",
+		Message: "Contract expired at " + contract.Spec.ExpirationTime,
+	}) {
+		changed = true
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "Expired", "Contract expired, its Allocation has been released")
+	}
+	if changed {
+		if err := r.Status().Update(ctx, contract); err != nil {
+			klog.Errorf("Error when updating Contract %s status: %s", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// renewContract asks the seller to extend the expiration of a Contract bought by this node.
+// The peering is left untouched, only the expiration and the price of the Contract are updated.
+func (r *ContractReconciler) renewContract(ctx context.Context, req ctrl.Request, contract *reservationv1alpha1.Contract) (ctrl.Result, error) {
+	if r.Gateway.ID == nil {
+		klog.Infof("Contract %s cannot be renewed until the FLUIDOS Node identity is known", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: flags.LiqoCheckInterval}, nil
+	}
+
+	if contract.Spec.Buyer.NodeID != r.Gateway.ID.NodeID {
+		klog.Infof("Contract %s has not been bought by this node, it can only be renewed by its buyer", req.NamespacedName)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "RenewalFailed", "Contract can only be renewed by its buyer")
+		delete(contract.Annotations, consts.FluidosContractRenewal)
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+
+	// The renewal request of the buyer is dropped once the seller has renewed or refused the Contract,
+	// while it is retried on the errors that may be solved by retrying
+	renewed, err := r.Gateway.RenewContract(ctx, contract)
+	if errors.Is(err, gateway.ErrRejectedBySeller) {
+		klog.Infof("Renewal of Contract %s rejected: %s", req.NamespacedName, err)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "RenewalFailed", "Contract renewal rejected: "+err.Error())
+		delete(contract.Annotations, consts.FluidosContractRenewal)
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+	if err != nil {
+		klog.Errorf("Error when renewing Contract %s: %s", req.NamespacedName, err)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "RenewalFailed", "Contract renewal failed, retrying: "+err.Error())
+		return ctrl.Result{}, err
+	}
+
+	delete(contract.Annotations, consts.FluidosContractRenewal)
+	contract.Spec.ExpirationTime = renewed.ExpirationTime
+	contract.Spec.Flavor.Spec.Price = nodecorev1alpha1.Price{
+		Amount:   renewed.Flavor.Price.Amount,
+		Currency: renewed.Flavor.Price.Currency,
+		Period:   renewed.Flavor.Price.Period,
+	}
+	if err := r.Update(ctx, contract); err != nil {
+		klog.Errorf("Error when updating Contract %s: %s", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	klog.Infof("Contract %s renewed until %s", req.NamespacedName, contract.Spec.ExpirationTime)
+	r.Recorder.Event(contract, corev1.EventTypeNormal, "Renewed", "Contract renewed until "+contract.Spec.ExpirationTime)
+
+	// The expiration conditions are updated by the next reconcile, triggered by the update
+	return ctrl.Result{}, nil
+}
+
+// amendContract asks the seller to resize the resources bought with a K8Slice Contract to the configuration
+// set in the amendment annotation. The ResourceSlice of the peering is resized by its Allocation.
+func (r *ContractReconciler) amendContract(ctx context.Context, req ctrl.Request, contract *reservationv1alpha1.Contract) (ctrl.Result, error) {
+	if r.Gateway.ID == nil {
+		klog.Infof("Contract %s cannot be amended until the FLUIDOS Node identity is known", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: flags.LiqoCheckInterval}, nil
+	}
+
+	// Whatever the outcome, the amendment is attempted once per request of the buyer
+	amendment := contract.Annotations[consts.FluidosContractAmendment]
+	delete(contract.Annotations, consts.FluidosContractAmendment)
+
+	if contract.Spec.Buyer.NodeID != r.Gateway.ID.NodeID {
+		klog.Infof("Contract %s has not been bought by this node, it can only be amended by its buyer", req.NamespacedName)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "AmendmentFailed", "Contract can only be amended by its buyer")
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+
+	configuration, err := parseutil.ParseConfiguration(&nodecorev1alpha1.Configuration{
+		ConfigurationTypeIdentifier: nodecorev1alpha1.TypeK8Slice,
+		ConfigurationData:           runtime.RawExtension{Raw: []byte(amendment)},
+	}, &contract.Spec.Flavor)
+	if err != nil {
+		klog.Errorf("Error when parsing the amendment of Contract %s: %s", req.NamespacedName, err)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "AmendmentFailed", "Invalid K8Slice configuration: "+err.Error())
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+
+	amended, err := r.Gateway.AmendContract(ctx, contract, configuration)
+	if err != nil {
+		klog.Errorf("Error when amending Contract %s: %s", req.NamespacedName, err)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "AmendmentFailed", "Contract amendment failed: "+err.Error())
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+	if amended.Configuration == nil {
+		klog.Errorf("Contract %s amended by the seller without configuration", req.NamespacedName)
+		r.Recorder.Event(contract, corev1.EventTypeWarning, "AmendmentFailed", "Contract amended by the seller without configuration")
+		return ctrl.Result{}, r.Update(ctx, contract)
+	}
+
+	contract.Spec.Configuration, err = resourceforge.ForgeConfigurationFromObj(*amended.Configuration)
+	if err != nil {
+		klog.Errorf("Error when forging the configuration of Contract %s: %s", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+	contract.Spec.Flavor.Spec.Price = nodecorev1alpha1.Price{
+		Amount:   amended.Flavor.Price.Amount,
+		Currency: amended.Flavor.Price.Currency,
+		Period:   amended.Flavor.Price.Period,
+	}
+	if err := r.Update(ctx, contract); err != nil {
+		klog.Errorf("Error when updating Contract %s: %s", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	klog.Infof("Contract %s amended", req.NamespacedName)
+	r.Recorder.Event(contract, corev1.EventTypeNormal, "Amended", "Contract amended to "+amendment)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ContractReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&reservationv1alpha1.Contract{}).
+		Complete(r)
+}
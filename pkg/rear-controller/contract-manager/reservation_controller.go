@@ -28,7 +28,6 @@ import (
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	"github.com/fluidos-project/node/pkg/rear-controller/gateway"
-	"github.com/fluidos-project/node/pkg/utils/getters"
 	"github.com/fluidos-project/node/pkg/utils/models"
 	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 	"github.com/fluidos-project/node/pkg/utils/tools"
@@ -53,9 +52,13 @@ type ReservationReconciler struct {
 //+kubebuilder:rbac:groups=reservation.fluidos.eu,resources=transactions/finalizers,verbs=update
 
 //+kubebuilder:rbac:groups=core,resources=*,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=create
+//+kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
 
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;bind;escalate
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;watch;create;update;patch;delete;bind;escalate
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
 
 //+kubebuilder:rbac:groups=authentication.liqo.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core.liqo.io,resources=*,verbs=get;list;watch;create;update;patch;delete
@@ -319,21 +322,13 @@ func (r *ReservationReconciler) handlePurchase(ctx context.Context,
 
 		transactionID := reservation.Status.TransactionID
 		var contract *models.Contract
-		var liqoCredentials *nodecorev1alpha1.LiqoCredentials
 		var err error
 
 		// Based on the flavorTypeIdentifier, purchase flavor action may need buyer credentials
 		switch flavorTypeIdentifier {
-		case nodecorev1alpha1.TypeK8Slice:
+		case nodecorev1alpha1.TypeK8Slice, nodecorev1alpha1.TypeService:
+			// The buyer peers with the seller, so it does not hand its own Liqo credentials over
 			contract, err = r.Gateway.PurchaseFlavor(ctx, transactionID, reservation.Spec.Seller, nil)
-		case nodecorev1alpha1.TypeService:
-			liqoCredentials, err = getters.GetLiqoCredentials(context.Background(), r.Client, r.RestConfig)
-			if err != nil {
-				klog.Errorf("Error getting Liqo Credentials: %s", err)
-				return ctrl.Result{}, err
-			}
-
-			contract, err = r.Gateway.PurchaseFlavor(ctx, transactionID, reservation.Spec.Seller, liqoCredentials)
 		case nodecorev1alpha1.TypeVM:
 			// TODO (VM): implement the VM flavor purchase
 			klog.Infof("VM flavor purchase not implemented")
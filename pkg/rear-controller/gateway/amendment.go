@@ -0,0 +1,205 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/pricing"
+	"github.com/fluidos-project/node/pkg/utils/resourceforge"
+	"github.com/fluidos-project/node/pkg/utils/services"
+)
+
+// errInsufficientCapacity is returned when the Flavor of a Contract cannot provide the resources requested by an amendment.
+var errInsufficientCapacity = errors.New("insufficient capacity")
+
+// resizeFlavorRemainder moves the difference between the current and the amended partition of a Contract
+// to or from the free remainder of its Flavor, so that the Flavor catalog reflects the amendment.
+// It returns errInsufficientCapacity if the remainder cannot provide the additional resources.
+// Concurrent amendments are detected through the resource version of the remainder, and the resize is retried.
+func (g *Gateway) resizeFlavorRemainder(ctx context.Context, contract *reservationv1alpha1.Contract,
+	current, amended *nodecorev1alpha1.K8SliceConfiguration) error {
+	return retry.OnError(retry.DefaultRetry, func(err error) bool {
+		// The remainder has been updated or deleted by another amendment since it has been read
+		return apierrors.IsConflict(err) || apierrors.IsNotFound(err)
+	}, func() error {
+		return g.tryResizeFlavorRemainder(ctx, contract, current, amended)
+	})
+}
+
+// tryResizeFlavorRemainder performs a single attempt of resizeFlavorRemainder, failing with a conflict
+// if the remainder has been changed since it has been read.
+func (g *Gateway) tryResizeFlavorRemainder(ctx context.Context, contract *reservationv1alpha1.Contract,
+	current, amended *nodecorev1alpha1.K8SliceConfiguration) error {
+	rootName := services.FlavorRoot(&contract.Spec.Flavor)
+
+	remainders := &nodecorev1alpha1.FlavorList{}
+	if err := g.client.List(ctx, remainders, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorRootLabel: rootName}); err != nil {
+		klog.Errorf("Error when listing remainders of Flavor %s: %s", rootName, err)
+		return err
+	}
+	sort.Slice(remainders.Items, func(i, j int) bool {
+		return remainders.Items[i].Name < remainders.Items[j].Name
+	})
+
+	contracts := &reservationv1alpha1.ContractList{}
+	if err := g.client.List(ctx, contracts, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Contracts: %s", err)
+		return err
+	}
+	sold := map[string]bool{}
+	for i := range contracts.Items {
+		sold[contracts.Items[i].Spec.Flavor.Name] = true
+	}
+
+	// The free remainder is the available one, or the one holding a capacity too small to be sold
+	var remainder *nodecorev1alpha1.Flavor
+	for i := range remainders.Items {
+		if sold[remainders.Items[i].Name] {
+			continue
+		}
+		if remainder == nil || (!remainder.Spec.Availability && remainders.Items[i].Spec.Availability) {
+			remainder = &remainders.Items[i]
+		}
+	}
+	available := &nodecorev1alpha1.K8SliceCharacteristics{}
+	if remainder != nil {
+		k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(remainder.Spec.FlavorType)
+		if err != nil {
+			klog.Errorf("Error when parsing Flavor %s: %s", remainder.Name, err)
+			return err
+		}
+		available = k8Slice.Characteristics.DeepCopy()
+	}
+
+	// Give back the current partition and take the amended one
+	available.CPU.Add(current.CPU)
+	available.CPU.Sub(amended.CPU)
+	available.Memory.Add(current.Memory)
+	available.Memory.Sub(amended.Memory)
+	available.Pods.Add(current.Pods)
+	available.Pods.Sub(amended.Pods)
+	if current.Storage != nil && amended.Storage != nil {
+		var storage resource.Quantity
+		if available.Storage != nil {
+			storage = available.Storage.DeepCopy()
+		}
+		storage.Add(*current.Storage)
+		storage.Sub(*amended.Storage)
+		available.Storage = &storage
+	}
+	storageClassExhausted := false
+	for i := range available.StorageClasses {
+		class := &available.StorageClasses[i]
+		if class.Name != amended.StorageClass || current.Storage == nil || amended.Storage == nil {
+			continue
+		}
+		class.Capacity.Add(*current.Storage)
+		class.Capacity.Sub(*amended.Storage)
+		storageClassExhausted = class.Capacity.Sign() < 0
+	}
+
+	if available.CPU.Sign() < 0 || available.Memory.Sign() < 0 || available.Pods.Sign() < 0 ||
+		(available.Storage != nil && available.Storage.Sign() < 0) || storageClassExhausted {
+		return fmt.Errorf("%w: Flavor %s cannot provide the requested resources", errInsufficientCapacity, rootName)
+	}
+
+	// A remainder without some of the resources is kept, but it cannot be sold until it gets them back
+	hasCapacity := available.CPU.Sign() > 0 && available.Memory.Sign() > 0 && available.Pods.Sign() > 0
+	hasFreeResources := available.CPU.Sign() > 0 || available.Memory.Sign() > 0 || available.Pods.Sign() > 0 ||
+		(available.Storage != nil && available.Storage.Sign() > 0)
+
+	switch {
+	case remainder != nil && !hasFreeResources:
+		if err := g.client.Delete(ctx, remainder, client.Preconditions{ResourceVersion: &remainder.ResourceVersion}); err != nil {
+			klog.Errorf("Error when deleting Flavor %s: %s", remainder.Name, err)
+			return err
+		}
+		klog.Infof("Flavor %s has no free capacity left, remainder %s deleted", rootName, remainder.Name)
+	case remainder != nil:
+		if err := services.SetK8SliceCharacteristics(remainder, available); err != nil {
+			klog.Errorf("Error when updating remainder %s: %s", remainder.Name, err)
+			return err
+		}
+		remainder.Spec.Availability = hasCapacity
+		if err := g.client.Update(ctx, remainder); err != nil {
+			klog.Errorf("Error when updating Flavor %s: %s", remainder.Name, err)
+			return err
+		}
+		klog.Infof("Flavor %s updated with the free capacity of Flavor %s, availability %t", remainder.Name, rootName, hasCapacity)
+	case !hasFreeResources:
+		klog.Infof("Flavor %s has no remainder and no freed capacity", rootName)
+	default:
+		root, err := services.GetFlavorByID(rootName, g.client)
+		if err != nil {
+			return err
+		}
+		remainder = resourceforge.ForgeFlavorFromRef(root, root.Spec.FlavorType.DeepCopy())
+		remainder.Labels = services.RemainderLabels(root)
+		rootK8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(root.Spec.FlavorType)
+		if err != nil {
+			klog.Errorf("Error when parsing Flavor %s: %s", rootName, err)
+			return err
+		}
+		available.Architecture = rootK8Slice.Characteristics.Architecture
+		if err := services.SetK8SliceCharacteristics(remainder, available); err != nil {
+			klog.Errorf("Error when forging remainder of Flavor %s: %s", rootName, err)
+			return err
+		}
+		remainder.Spec.Availability = hasCapacity
+		if err := g.client.Create(ctx, remainder); err != nil {
+			klog.Errorf("Error when creating Flavor %s: %s", remainder.Name, err)
+			return err
+		}
+		klog.Infof("Flavor %s created with the free capacity of Flavor %s, availability %t", remainder.Name, rootName, hasCapacity)
+	}
+
+	return nil
+}
+
+// undoAmendment restores the configuration and the price a Contract had before an amendment.
+func (g *Gateway) undoAmendment(ctx context.Context, contract *reservationv1alpha1.Contract,
+	previous *reservationv1alpha1.ContractSpec) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := g.client.Get(ctx, client.ObjectKeyFromObject(contract), contract); err != nil {
+			return err
+		}
+		contract.Spec.Configuration = previous.Configuration
+		contract.Spec.Flavor.Spec.Price = previous.Flavor.Spec.Price
+		return g.client.Update(ctx, contract)
+	})
+}
+
+// repriceContract scales the price of a Contract by the mean of the CPU and memory ratios between the amended
+// and the current partition. The price is kept unchanged if its amount is not a number.
+func repriceContract(price nodecorev1alpha1.Price, current, amended *nodecorev1alpha1.K8SliceConfiguration) nodecorev1alpha1.Price {
+	return pricing.ScalePrice(price,
+		&nodecorev1alpha1.K8SliceCharacteristics{CPU: current.CPU, Memory: current.Memory},
+		&nodecorev1alpha1.K8SliceCharacteristics{CPU: amended.CPU, Memory: amended.Memory})
+}
@@ -18,7 +18,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -30,8 +32,13 @@ import (
 	"github.com/fluidos-project/node/pkg/utils/models"
 	"github.com/fluidos-project/node/pkg/utils/parseutil"
 	"github.com/fluidos-project/node/pkg/utils/resourceforge"
+	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
 )
 
+// ErrRejectedBySeller is returned when the seller refuses a request about a contract, as opposed to
+// the errors reaching the seller or on its side, which may be solved by retrying the request.
+var ErrRejectedBySeller = errors.New("request rejected by the seller")
+
 // ReserveFlavor reserves a flavor with the given flavorID.
 func (g *Gateway) ReserveFlavor(ctx context.Context,
 	reservation *reservationv1alpha1.Reservation, flavor *nodecorev1alpha1.Flavor) (*models.Transaction, error) {
@@ -40,9 +47,9 @@ func (g *Gateway) ReserveFlavor(ctx context.Context,
 		return nil, err
 	}
 
-	liqoCredentials, err := getters.GetLiqoCredentials(ctx, g.client, g.restConfig)
+	liqoClusterID, err := getters.GetLiqoClusterID(ctx, g.restConfig)
 	if err != nil {
-		klog.Errorf("Error when getting Liqo credentials: %s", err)
+		klog.Errorf("Error when getting Liqo cluster ID: %s", err)
 		return nil, err
 	}
 
@@ -57,8 +64,7 @@ func (g *Gateway) ReserveFlavor(ctx context.Context,
 			IP:     g.ID.IP,
 			Domain: g.ID.Domain,
 			AdditionalInformation: &models.NodeIdentityAdditionalInfo{
-				LiqoID:     liqoCredentials.ClusterID,
-				Kubeconfig: liqoCredentials.Kubeconfig,
+				LiqoID: liqoClusterID,
 			},
 		},
 		Configuration: func() *models.Configuration {
@@ -197,6 +203,149 @@ func (g *Gateway) PurchaseFlavor(ctx context.Context, transactionID string,
 	return &contract, nil
 }
 
+// RotateCredentials asks the seller of the given contract for new Liqo credentials, presenting the current ones.
+func (g *Gateway) RotateCredentials(ctx context.Context, contract *reservationv1alpha1.Contract) (*nodecorev1alpha1.LiqoCredentials, error) {
+	err := checkLiqoReadiness(g.LiqoReady)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := peeringToken(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	selectorBytes, err := json.Marshal(models.CredentialsRotationRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes := bytes.NewBuffer(selectorBytes)
+	apiPath := strings.Replace(Routes.Credentials, "{contractID}", contract.Name, 1)
+	url := fmt.Sprintf("http://%s%s", contract.Spec.Seller.IP, apiPath)
+
+	resp, err := makeRequest(ctx, "POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check if the response status code is 200 (OK)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received non-OK response status code: %d", resp.StatusCode)
+	}
+
+	var liqoCredentials models.LiqoCredentials
+	if err := json.NewDecoder(resp.Body).Decode(&liqoCredentials); err != nil {
+		return nil, err
+	}
+
+	return resourceforge.ForgeLiqoCredentialsFromObj(&liqoCredentials)
+}
+
+// RenewContract asks the seller of the given contract to extend its expiration, presenting the current credentials.
+func (g *Gateway) RenewContract(ctx context.Context, contract *reservationv1alpha1.Contract) (*models.Contract, error) {
+	err := checkLiqoReadiness(g.LiqoReady)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := peeringToken(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	selectorBytes, err := json.Marshal(models.ContractRenewalRequest{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes := bytes.NewBuffer(selectorBytes)
+	apiPath := strings.Replace(Routes.Renew, "{contractID}", contract.Name, 1)
+	url := fmt.Sprintf("http://%s%s", contract.Spec.Seller.IP, apiPath)
+
+	resp, err := makeRequest(ctx, "POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check if the response status code is 200 (OK), a client error means the seller refused the renewal
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%w: status code %d: %s", ErrRejectedBySeller, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received non-OK response status code: %d", resp.StatusCode)
+	}
+
+	var renewed models.Contract
+	if err := json.NewDecoder(resp.Body).Decode(&renewed); err != nil {
+		return nil, err
+	}
+
+	return &renewed, nil
+}
+
+// AmendContract asks the seller to resize the resources bought with a K8Slice contract to the given configuration.
+func (g *Gateway) AmendContract(ctx context.Context, contract *reservationv1alpha1.Contract,
+	configuration *models.Configuration) (*models.Contract, error) {
+	err := checkLiqoReadiness(g.LiqoReady)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := peeringToken(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBytes, err := json.Marshal(models.ContractAmendmentRequest{Token: token, Configuration: *configuration})
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes := bytes.NewBuffer(requestBytes)
+	apiPath := strings.Replace(Routes.Amend, "{contractID}", contract.Name, 1)
+	url := fmt.Sprintf("http://%s%s", contract.Spec.Seller.IP, apiPath)
+
+	resp, err := makeRequest(ctx, "POST", url, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Check if the response status code is 200 (OK), the seller explains why the amendment has been refused
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("received non-OK response status code: %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var amended models.Contract
+	if err := json.NewDecoder(resp.Body).Decode(&amended); err != nil {
+		return nil, err
+	}
+
+	return &amended, nil
+}
+
+// peeringToken extracts the token from the Liqo credentials of a contract.
+func peeringToken(contract *reservationv1alpha1.Contract) (string, error) {
+	kubeconfig, err := virtualfabricmanager.DecodeKubeconfig(contract.Spec.PeeringTargetCredentials.Kubeconfig)
+	if err != nil {
+		klog.Errorf("Error decoding the kubeconfig of contract %s: %s", contract.Name, err)
+		return "", err
+	}
+
+	if kubeContext, ok := kubeconfig.Contexts[kubeconfig.CurrentContext]; ok {
+		if authInfo, ok := kubeconfig.AuthInfos[kubeContext.AuthInfo]; ok && authInfo.Token != "" {
+			return authInfo.Token, nil
+		}
+	}
+
+	return "", fmt.Errorf("no token found in the credentials of contract %s", contract.Name)
+}
+
 // DiscoverFlavors is a function that returns an array of Flavor that fit the Selector by performing a get request to an http server.
 func (g *Gateway) DiscoverFlavors(ctx context.Context, selector *nodecorev1alpha1.Selector) ([]*nodecorev1alpha1.Flavor, error) {
 	klog.Info("Discovering flavors")
@@ -25,10 +25,12 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	"github.com/fluidos-project/node/pkg/utils/consts"
 	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/getters"
@@ -44,6 +46,8 @@ import (
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=flavortemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=endpoints;nodes,verbs=get;list
 //	+kubebuilder:rbac:groups=core,resources=*,verbs=get;list;watch
 
 // Gateway is the object that contains all the logical data stractures of the REAR Gateway.
@@ -60,6 +64,9 @@ type Gateway struct {
 	// restConfig is the Kubernetes REST configuration
 	restConfig *rest.Config
 
+	// recorder records the Events of the resources handled by the Gateway
+	recorder record.EventRecorder
+
 	// Readyness of the Gateway. It is set when liqo is installed
 	LiqoReady bool
 
@@ -68,10 +75,11 @@ type Gateway struct {
 }
 
 // NewGateway creates a new Gateway object.
-func NewGateway(c client.Client, restConfig *rest.Config) *Gateway {
+func NewGateway(c client.Client, restConfig *rest.Config, recorder record.EventRecorder) *Gateway {
 	return &Gateway{
 		client:       c,
 		restConfig:   restConfig,
+		recorder:     recorder,
 		Transactions: make(map[string]*models.Transaction),
 		LiqoReady:    false,
 		ClusterID:    "",
@@ -108,6 +116,10 @@ func (g *Gateway) Start(ctx context.Context) error {
 	// router.HandleFunc(Routes.SensorFlavors, g.getSensorFlavorsBySelector).Methods("GET")
 	router.HandleFunc(Routes.Reserve, g.reserveFlavor).Methods("POST")
 	router.HandleFunc(Routes.Purchase, g.purchaseFlavor).Methods("POST")
+	router.HandleFunc(Routes.Health, g.getHealth).Methods("GET")
+	router.HandleFunc(Routes.Credentials, g.rotateCredentials).Methods("POST")
+	router.HandleFunc(Routes.Renew, g.renewContract).Methods("POST")
+	router.HandleFunc(Routes.Amend, g.amendContract).Methods("POST")
 
 	// Configure the HTTP server
 	//nolint:gosec // we are not using a TLS certificate
@@ -145,20 +157,89 @@ func (g *Gateway) CacheRefresher(interval time.Duration) func(ctx context.Contex
 }
 
 // check expired transactions and remove them from the cache.
-//
-//nolint:revive // we need to pass ctx as parameter to be compliant with the Poller interface
 func (g *Gateway) refreshCache(ctx context.Context) (bool, error) {
 	klog.InfofDepth(1, "Refreshing cache")
 	for transactionID, transaction := range g.Transactions {
 		if tools.CheckExpiration(transaction.ExpirationTime) {
 			klog.Infof("Transaction %s expired, removing it from cache...", transactionID)
 			g.removeTransaction(transactionID)
+			if g.isSoldTransaction(transaction) {
+				_ = g.deleteTransaction(ctx, transactionID)
+			}
 			return false, nil
 		}
 	}
 	return false, nil
 }
 
+// CredentialsRefresher is a function that periodically rotates the Liqo credentials of the contracts bought by this node.
+func (g *Gateway) CredentialsRefresher(interval time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return wait.PollUntilContextCancel(ctx, interval, false, g.refreshCredentials)
+	}
+}
+
+// rotate the Liqo credentials that are about to expire.
+// Credentials are rotated once less than a third of the token lifetime is left,
+// unless they already last as long as the contract they have been issued for.
+func (g *Gateway) refreshCredentials(ctx context.Context) (bool, error) {
+	if !g.LiqoReady || g.ID == nil {
+		return false, nil
+	}
+
+	klog.InfofDepth(1, "Refreshing credentials")
+	contracts := &reservationv1alpha1.ContractList{}
+	if err := g.client.List(ctx, contracts, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error listing Contracts: %s", err)
+		return false, nil
+	}
+
+	for i := range contracts.Items {
+		contract := &contracts.Items[i]
+		if contract.Spec.Buyer.NodeID != g.ID.NodeID || contract.Spec.PeeringTargetCredentials.Kubeconfig == "" {
+			continue
+		}
+		if tools.CheckExpiration(contract.Spec.ExpirationTime) {
+			continue
+		}
+		if allocation, err := getters.GetAllocationByContractName(ctx, g.client, contract.Name); err == nil &&
+			allocation.Status.Status == nodecorev1alpha1.Released {
+			continue
+		}
+
+		// Credentials issued by previous versions carry no expiration: rotate them to obtain a bound token
+		if expiration := contract.Spec.PeeringTargetCredentials.ExpirationTime; expiration != "" {
+			tokenExpiration, err := time.Parse(time.RFC3339, expiration)
+			if err != nil {
+				klog.Errorf("Error parsing the expiration time of the credentials of contract %s: %s", contract.Name, err)
+				continue
+			}
+			if time.Until(tokenExpiration) > flags.ExpirationPeeringToken/3 {
+				continue
+			}
+			contractExpiration, err := time.Parse(time.RFC3339, contract.Spec.ExpirationTime)
+			if err == nil && !tokenExpiration.Before(contractExpiration) {
+				continue
+			}
+		}
+
+		liqoCredentials, err := g.RotateCredentials(ctx, contract)
+		if err != nil {
+			klog.Errorf("Error rotating the credentials of contract %s: %s", contract.Name, err)
+			continue
+		}
+
+		contract.Spec.PeeringTargetCredentials = *liqoCredentials
+		if err := g.client.Update(ctx, contract); err != nil {
+			klog.Errorf("Error updating the credentials of contract %s: %s", contract.Name, err)
+			continue
+		}
+		klog.Infof("Credentials of contract %s rotated, valid until %s", contract.Name, liqoCredentials.ExpirationTime)
+	}
+
+	return false, nil
+}
+
 // LiqoChecker is a function that periodically checks if Liqo is ready.
 func (g *Gateway) LiqoChecker(interval time.Duration) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
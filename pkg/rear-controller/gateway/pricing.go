@@ -0,0 +1,118 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/pricing"
+	"github.com/fluidos-project/node/pkg/utils/services"
+)
+
+// priceContract prices the partition bought with a K8Slice Contract, see pricePartition.
+// It returns false if the partition cannot be priced, and the Contract keeps its price.
+func (g *Gateway) priceContract(ctx context.Context, contract *reservationv1alpha1.Contract) (nodecorev1alpha1.Price, bool) {
+	if contract.Spec.Flavor.Spec.FlavorType.TypeIdentifier != nodecorev1alpha1.TypeK8Slice {
+		return nodecorev1alpha1.Price{}, false
+	}
+	partition, err := contract.K8SlicePartition()
+	if err != nil {
+		klog.Errorf("Error parsing the partition of Contract %s: %s", contract.Name, err)
+		return nodecorev1alpha1.Price{}, false
+	}
+
+	return g.pricePartition(ctx, &contract.Spec.Flavor, partition)
+}
+
+// pricePartition prices a partition of a K8Slice Flavor. The fixed price set by the FlavorTemplate of the Flavor
+// is the one of the whole Flavor, so the partition is charged its share; otherwise, the partition is priced through the rate card.
+// It returns false if the price is neither fixed nor priced through a rate card.
+func (g *Gateway) pricePartition(ctx context.Context, flavor *nodecorev1alpha1.Flavor,
+	partition *nodecorev1alpha1.K8SliceConfiguration) (nodecorev1alpha1.Price, bool) {
+	root := g.rootFlavor(ctx, flavor)
+	if fixedPrice := g.fixedPrice(ctx, root); fixedPrice != nil {
+		whole, err := nodecorev1alpha1.ParseK8SliceFlavor(root.Spec.FlavorType)
+		if err != nil {
+			klog.Errorf("Error parsing the K8Slice Flavor %s: %s", root.Name, err)
+			return nodecorev1alpha1.Price{}, false
+		}
+		return pricing.ScalePrice(*fixedPrice, &whole.Characteristics,
+			&nodecorev1alpha1.K8SliceCharacteristics{CPU: partition.CPU, Memory: partition.Memory}), true
+	}
+
+	rateCard, err := pricing.GetRateCard(ctx, g.client)
+	if err != nil {
+		klog.Errorf("Error getting the rate card: %s", err)
+		return nodecorev1alpha1.Price{}, false
+	}
+	if rateCard == nil {
+		return nodecorev1alpha1.Price{}, false
+	}
+
+	k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(flavor.Spec.FlavorType)
+	if err != nil {
+		klog.Errorf("Error parsing the K8Slice Flavor %s: %s", flavor.Name, err)
+		return nodecorev1alpha1.Price{}, false
+	}
+
+	return rateCard.PriceConfiguration(k8Slice, partition, flavorRegion(root)), true
+}
+
+// rootFlavor returns the root Flavor of a Flavor, which is the Flavor itself unless it is a remainder, or nil if it is not found.
+func (g *Gateway) rootFlavor(ctx context.Context, flavor *nodecorev1alpha1.Flavor) *nodecorev1alpha1.Flavor {
+	rootName := services.FlavorRoot(flavor)
+	if rootName == flavor.Name {
+		return flavor
+	}
+	root := &nodecorev1alpha1.Flavor{}
+	if err := g.client.Get(ctx, client.ObjectKey{Name: rootName, Namespace: flags.FluidosNamespace}, root); err != nil {
+		klog.Infof("Root Flavor %s of Flavor %s not found", rootName, flavor.Name)
+		return nil
+	}
+	return root
+}
+
+// flavorRegion returns the region of the node advertised by a root Flavor, which is labelled on it.
+func flavorRegion(root *nodecorev1alpha1.Flavor) string {
+	if root == nil {
+		return ""
+	}
+	return root.Labels[corev1.LabelTopologyRegion]
+}
+
+// fixedPrice returns the price of the whole root Flavor fixed by its FlavorTemplate, or nil if the price is not fixed.
+func (g *Gateway) fixedPrice(ctx context.Context, root *nodecorev1alpha1.Flavor) *nodecorev1alpha1.Price {
+	if root == nil {
+		return nil
+	}
+	templateName, ok := root.Labels[consts.FluidosFlavorTemplateLabel]
+	if !ok {
+		return nil
+	}
+	template := &nodecorev1alpha1.FlavorTemplate{}
+	if err := g.client.Get(ctx, client.ObjectKey{Name: templateName, Namespace: flags.FluidosNamespace}, template); err != nil {
+		klog.Infof("FlavorTemplate %s of Flavor %s not found", templateName, root.Name)
+		return nil
+	}
+	return template.Spec.Price
+}
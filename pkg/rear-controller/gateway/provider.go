@@ -17,9 +17,14 @@ package gateway
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,6 +32,7 @@ import (
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	"github.com/fluidos-project/node/pkg/utils/common"
+	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/getters"
 	"github.com/fluidos-project/node/pkg/utils/models"
 	"github.com/fluidos-project/node/pkg/utils/namings"
@@ -34,6 +40,7 @@ import (
 	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 	"github.com/fluidos-project/node/pkg/utils/services"
 	"github.com/fluidos-project/node/pkg/utils/tools"
+	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
 )
 
 // getFlavors gets all the flavors CRs from the cluster.
@@ -248,6 +255,8 @@ func (g *Gateway) reserveFlavor(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error parsing the Flavor type", http.StatusInternalServerError)
 		return
 	}
+	// The partition reserved, if any, is priced on its own configuration
+	price := flavor.Spec.Price
 	// Check if configuration is valid, based on the Flavor the client wants to reserve
 	switch flavorTypeIdentifier {
 	case nodecorev1alpha1.TypeK8Slice:
@@ -269,12 +278,27 @@ func (g *Gateway) reserveFlavor(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			// Parse the configuration
-			_, _, err = nodecorev1alpha1.ParseConfiguration(configuration, flavor)
+			_, configurationData, err := nodecorev1alpha1.ParseConfiguration(configuration, flavor)
 			if err != nil {
 				klog.Errorf("Error parsing the configuration: %s", err)
 				http.Error(w, "Error parsing the configuration", http.StatusInternalServerError)
 				return
 			}
+			// The GPUs and MIG instances requested must be available on the Flavor
+			k8SliceConfiguration := configurationData.(nodecorev1alpha1.K8SliceConfiguration)
+			k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(flavor.Spec.FlavorType)
+			if err != nil {
+				klog.Errorf("Error parsing the K8Slice Flavor: %s", err)
+				http.Error(w, "Error parsing the K8Slice Flavor", http.StatusInternalServerError)
+				return
+			}
+			if err := k8SliceConfiguration.ValidateGPU(k8Slice); err != nil {
+				http.Error(w, "Error: invalid configuration: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if partitionPrice, ok := g.pricePartition(r.Context(), flavor, &k8SliceConfiguration); ok {
+				price = partitionPrice
+			}
 			// No further checks are needed for the K8Slice flavor configuration
 			// TODO(K8Slice): Implement the K8Slice flavor configuration checks if needed
 		}
@@ -409,12 +433,20 @@ func (g *Gateway) reserveFlavor(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Create a new transaction
-		transaction = resourceforge.ForgeTransactionObj(transactionID, &request)
+		transaction = resourceforge.ForgeTransactionObj(transactionID, &request, price)
 
 		// Add the transaction to the transactions map
 		g.addNewTransaction(transaction)
 	}
 
+	// Record the Transaction, so that the Flavor is not merged back while it is being reserved
+	if g.isSoldTransaction(transaction) {
+		if err := g.storeTransaction(r.Context(), transaction); err != nil {
+			http.Error(w, "Error storing the Transaction", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	klog.Infof("Transaction %s reserved", transaction.TransactionID)
 
 	encodeResponse(w, transaction)
@@ -448,6 +480,9 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 		klog.Infof("Transaction %s expired", transaction.TransactionID)
 		http.Error(w, "Error: transaction Timeout", http.StatusRequestTimeout)
 		g.removeTransaction(transaction.TransactionID)
+		if g.isSoldTransaction(&transaction) {
+			_ = g.deleteTransaction(r.Context(), transaction.TransactionID)
+		}
 		return
 	}
 
@@ -466,6 +501,16 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 	if len(contractList.Items) > 0 {
 		klog.Infof("Contract already exists for transaction %s", transactionID)
 		contract = contractList.Items[0]
+		// A previous attempt may have failed after creating the Contract but before creating its Allocation
+		if _, err := getters.GetAllocationByContractName(r.Context(), g.client, contract.Name); apierrors.IsNotFound(err) {
+			klog.Infof("Creating the missing allocation of Contract %s...", contract.Name)
+			allocation := resourceforge.ForgeAllocation(&contract)
+			if err := g.client.Create(r.Context(), allocation); err != nil {
+				klog.Errorf("Error creating the Allocation: %s", err)
+				http.Error(w, "Contract created but we ran into an error while allocating the resources", http.StatusInternalServerError)
+				return
+			}
+		}
 		// Create a contract object to be returned with the response
 		contractObject := parseutil.ParseContract(&contract)
 		// Respond with the response purchase as JSON
@@ -488,13 +533,23 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The Flavor may have been sold or merged back into another one after the reservation
+	if !flavorSold.Spec.Availability {
+		klog.Errorf("Flavor %s is no longer available", flavorSold.Name)
+		http.Error(w, "Error: Flavor is no longer available", http.StatusConflict)
+		return
+	}
+
 	var liqoCredentials *nodecorev1alpha1.LiqoCredentials
+	// The credentials never outlive the contract they are issued for
+	contractExpiration := time.Now().Add(flags.ExpirationContract)
 
 	// According to the flavor type, create the contract with the right liqo credentials
 	switch flavorSold.Spec.FlavorType.TypeIdentifier {
 	case nodecorev1alpha1.TypeK8Slice:
-		// Create a new Liqo credentials for the K8Slice flavor based on the ones provided by the provider
-		liqoCredentials, err = getters.GetLiqoCredentials(context.Background(), g.client, g.restConfig)
+		// Create a new Liqo credentials for the K8Slice flavor, scoped to the buyer cluster
+		liqoCredentials, err = getters.GetLiqoCredentials(context.Background(), g.client, g.restConfig, transaction.ClusterID,
+			nodecorev1alpha1.TypeK8Slice, contractExpiration)
 		if err != nil {
 			klog.Errorf("Error getting Liqo Credentials: %s", err)
 			http.Error(w, "Error getting Liqo Credentials", http.StatusInternalServerError)
@@ -506,14 +561,10 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Flavor type not supported", http.StatusBadRequest)
 		return
 	case nodecorev1alpha1.TypeService:
-		// Check client sent its liqo credentials
-		if purchase.LiqoCredentials == nil {
-			klog.Errorf("Error: Liqo credentials not provided")
-			http.Error(w, "Error: Liqo credentials not provided", http.StatusBadRequest)
-			return
-		}
-		// Override the Liqo credentials with the ones sent by the client
-		liqoCredentials, err = getters.GetLiqoCredentials(context.Background(), g.client, g.restConfig)
+		// The buyer peers this cluster with itself, so that the Service runs on its resources:
+		// the credentials only grant the consuming side of that peering on this cluster
+		liqoCredentials, err = getters.GetLiqoCredentials(context.Background(), g.client, g.restConfig, transaction.ClusterID,
+			nodecorev1alpha1.TypeService, contractExpiration)
 		if err != nil {
 			klog.Errorf("Error forging the Liqo credentials: %s", err)
 			http.Error(w, "Error forging the Liqo credentials", http.StatusInternalServerError)
@@ -531,10 +582,10 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Obtaining the Seller Liqo Cluster ID
-	sellerLiqoCredentials, err := getters.GetLiqoCredentials(context.Background(), g.client, g.restConfig)
+	sellerLiqoClusterID, err := getters.GetLiqoClusterID(context.Background(), g.restConfig)
 	if err != nil {
-		klog.Errorf("Error getting Liqo Credentials: %s", err)
-		http.Error(w, "Error getting Liqo Credentials", http.StatusInternalServerError)
+		klog.Errorf("Error getting Liqo Cluster ID: %s", err)
+		http.Error(w, "Error getting Liqo Cluster ID", http.StatusInternalServerError)
 		return
 	}
 
@@ -545,9 +596,17 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 		flavorSold,
 		&transaction,
 		liqoCredentials,
-		sellerLiqoCredentials.ClusterID,
+		sellerLiqoClusterID,
 		purchase.IngressTelemetryEndpoint,
 	)
+	// The Contract is bought at the price of the partition when it was reserved
+	if transaction.Price != nil {
+		contract.Spec.Flavor.Spec.Price = nodecorev1alpha1.Price{
+			Amount:   transaction.Price.Amount,
+			Currency: transaction.Price.Currency,
+			Period:   transaction.Price.Period,
+		}
+	}
 	err = g.client.Create(context.Background(), &contract)
 	if err != nil {
 		klog.Errorf("Error creating the Contract: %s", err)
@@ -557,6 +616,11 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 
 	klog.Infof("Contract created!")
 
+	// The Contract now holds the Flavor, the Transaction is no longer needed
+	if g.isSoldTransaction(&transaction) {
+		_ = g.deleteTransaction(r.Context(), transaction.TransactionID)
+	}
+
 	// Create a contract object to be returned with the response
 	contractObject := parseutil.ParseContract(&contract)
 
@@ -581,3 +645,266 @@ func (g *Gateway) purchaseFlavor(w http.ResponseWriter, r *http.Request) {
 	// Respond with the response purchase as JSON
 	encodeResponse(w, contractObject)
 }
+
+// getSoldContract retrieves a contract sold by this node and still in force, on behalf of its buyer.
+// The buyer proves it owns the contract by presenting its current peering token.
+// If the contract cannot be handed to the buyer, an error is written to the response and nil is returned.
+func (g *Gateway) getSoldContract(w http.ResponseWriter, r *http.Request, contractID, token string) *reservationv1alpha1.Contract {
+	contract := &reservationv1alpha1.Contract{}
+	if err := g.client.Get(r.Context(), client.ObjectKey{Name: contractID, Namespace: flags.FluidosNamespace}, contract); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error getting the Contract: %s", err)
+			http.Error(w, "Error getting the Contract", http.StatusInternalServerError)
+			return nil
+		}
+		http.Error(w, "Contract not found", http.StatusNotFound)
+		return nil
+	}
+
+	if contract.Spec.Seller.NodeID != g.ID.NodeID {
+		klog.Errorf("Contract %s has not been sold by this node", contractID)
+		http.Error(w, "Contract not found", http.StatusNotFound)
+		return nil
+	}
+
+	// Nothing can be done on a contract once it is over
+	if tools.CheckExpiration(contract.Spec.ExpirationTime) {
+		klog.Infof("Contract %s expired", contractID)
+		http.Error(w, "Error: Contract expired", http.StatusGone)
+		return nil
+	}
+	allocation, err := getters.GetAllocationByContractName(r.Context(), g.client, contract.Name)
+	if err == nil && allocation.Status.Status == nodecorev1alpha1.Released {
+		klog.Infof("Allocation of contract %s released", contractID)
+		http.Error(w, "Error: Contract released", http.StatusGone)
+		return nil
+	}
+
+	if err := virtualfabricmanager.ValidatePeeringToken(r.Context(), g.client, contract.Spec.BuyerClusterID, token); err != nil {
+		klog.Errorf("Invalid token for contract %s: %s", contractID, err)
+		http.Error(w, "Error: invalid token", http.StatusUnauthorized)
+		return nil
+	}
+
+	return contract
+}
+
+// rotateCredentials issues new Liqo credentials for a contract sold by this node, replacing the ones about to expire.
+func (g *Gateway) rotateCredentials(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contractID := mux.Vars(r)["contractID"]
+
+	var request models.CredentialsRotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	klog.Infof("Rotating credentials for contract %s", contractID)
+
+	contract := g.getSoldContract(w, r, contractID, request.Token)
+	if contract == nil {
+		return
+	}
+
+	contractExpiration, err := time.Parse(time.RFC3339, contract.Spec.ExpirationTime)
+	if err != nil {
+		klog.Errorf("Error parsing the expiration time of the Contract: %s", err)
+		http.Error(w, "Error parsing the expiration time of the Contract", http.StatusInternalServerError)
+		return
+	}
+
+	// The new credentials keep the scope of the flavor type of the contract
+	liqoCredentials, err := getters.GetLiqoCredentials(r.Context(), g.client, g.restConfig, contract.Spec.BuyerClusterID,
+		contract.Spec.Flavor.Spec.FlavorType.TypeIdentifier, contractExpiration)
+	if err != nil {
+		klog.Errorf("Error getting Liqo Credentials: %s", err)
+		http.Error(w, "Error getting Liqo Credentials", http.StatusInternalServerError)
+		return
+	}
+
+	contract.Spec.PeeringTargetCredentials = *liqoCredentials
+	if err := g.client.Update(r.Context(), contract); err != nil {
+		klog.Errorf("Error updating the Contract: %s", err)
+		http.Error(w, "Error updating the Contract", http.StatusInternalServerError)
+		return
+	}
+
+	credentialsObject, err := resourceforge.ForgeLiqoCredentialsObj(liqoCredentials)
+	if err != nil {
+		klog.Errorf("Error forging the Liqo credentials: %s", err)
+		http.Error(w, "Error forging the Liqo credentials", http.StatusInternalServerError)
+		return
+	}
+
+	klog.Infof("Credentials of contract %s rotated, valid until %s", contractID, liqoCredentials.ExpirationTime)
+
+	encodeResponse(w, credentialsObject)
+}
+
+// renewContract extends the expiration of a contract sold by this node, without touching the peering.
+// The contract is renewed at the current price of its Flavor, which may have changed since the purchase.
+func (g *Gateway) renewContract(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contractID := mux.Vars(r)["contractID"]
+
+	var request models.ContractRenewalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	klog.Infof("Renewing contract %s", contractID)
+
+	contract := g.getSoldContract(w, r, contractID, request.Token)
+	if contract == nil {
+		return
+	}
+
+	expiration := time.Now()
+	if contractExpiration, err := time.Parse(time.RFC3339, contract.Spec.ExpirationTime); err == nil {
+		expiration = contractExpiration
+	}
+	contract.Spec.ExpirationTime = expiration.Add(flags.ExpirationContract).Format(time.RFC3339)
+
+	// The rate card may have been repriced since the purchase, otherwise the Contract keeps its price
+	if price, ok := g.priceContract(r.Context(), contract); ok {
+		contract.Spec.Flavor.Spec.Price = price
+	}
+
+	if err := g.client.Update(r.Context(), contract); err != nil {
+		klog.Errorf("Error updating the Contract: %s", err)
+		http.Error(w, "Error updating the Contract", http.StatusInternalServerError)
+		return
+	}
+
+	klog.Infof("Contract %s renewed until %s", contractID, contract.Spec.ExpirationTime)
+
+	encodeResponse(w, parseutil.ParseContract(contract))
+}
+
+// amendContract resizes the resources bought with a K8Slice contract sold by this node, without touching the peering.
+// The amended configuration must fit the partitionability of the Flavor and the capacity still available on it.
+func (g *Gateway) amendContract(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	contractID := mux.Vars(r)["contractID"]
+
+	var request models.ContractAmendmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	klog.Infof("Amending contract %s", contractID)
+
+	contract := g.getSoldContract(w, r, contractID, request.Token)
+	if contract == nil {
+		return
+	}
+
+	if contract.Spec.Flavor.Spec.FlavorType.TypeIdentifier != nodecorev1alpha1.TypeK8Slice || request.Configuration.Type != models.K8SliceNameDefault {
+		http.Error(w, "Error: only K8Slice contracts can be amended", http.StatusBadRequest)
+		return
+	}
+
+	// The Flavor must have been reduced by the allocation of the contract before it can be resized
+	allocation, err := getters.GetAllocationByContractName(r.Context(), g.client, contract.Name)
+	if err != nil || allocation.Status.Status == "" || allocation.Status.Status == nodecorev1alpha1.Inactive {
+		http.Error(w, "Error: Contract not allocated yet", http.StatusConflict)
+		return
+	}
+
+	configuration, err := resourceforge.ForgeConfigurationFromObj(request.Configuration)
+	if err != nil {
+		http.Error(w, "Error: invalid configuration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, configurationData, err := nodecorev1alpha1.ParseConfiguration(configuration, &contract.Spec.Flavor)
+	if err != nil {
+		http.Error(w, "Error: invalid configuration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	amended := configurationData.(nodecorev1alpha1.K8SliceConfiguration)
+
+	k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(contract.Spec.Flavor.Spec.FlavorType)
+	if err != nil {
+		klog.Errorf("Error parsing the Flavor of the Contract: %s", err)
+		http.Error(w, "Error parsing the Flavor of the Contract", http.StatusInternalServerError)
+		return
+	}
+	if err := amended.Validate(k8Slice); err != nil {
+		http.Error(w, "Error: invalid configuration: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := contract.K8SlicePartition()
+	if err != nil {
+		klog.Errorf("Error parsing the partition of the Contract: %s", err)
+		http.Error(w, "Error parsing the partition of the Contract", http.StatusInternalServerError)
+		return
+	}
+	if (current.Gpu == nil) != (amended.Gpu == nil) || (current.Storage == nil) != (amended.Storage == nil) {
+		http.Error(w, "Error: GPU and storage cannot be added to or removed from a Contract", http.StatusBadRequest)
+		return
+	}
+	if current.StorageClass != amended.StorageClass {
+		http.Error(w, "Error: the storage class of a Contract cannot be changed", http.StatusBadRequest)
+		return
+	}
+	// The GPUs are not split across the remainders of a Flavor, so they cannot be resized
+	if !equality.Semantic.DeepEqual(current.Gpu, amended.Gpu) {
+		http.Error(w, "Error: the GPUs of a Contract cannot be changed", http.StatusBadRequest)
+		return
+	}
+
+	// The Contract is updated first, failing if it has been changed since it has been read, then the Flavor is resized.
+	// If the resize fails, the amendment of the Contract is undone.
+	previous := contract.Spec.DeepCopy()
+	contract.Spec.Configuration = configuration
+	if price, ok := g.priceContract(r.Context(), contract); ok {
+		contract.Spec.Flavor.Spec.Price = price
+	} else {
+		contract.Spec.Flavor.Spec.Price = repriceContract(contract.Spec.Flavor.Spec.Price, current, &amended)
+	}
+	if err := g.client.Update(r.Context(), contract); err != nil {
+		klog.Errorf("Error updating the Contract: %s", err)
+		if apierrors.IsConflict(err) {
+			http.Error(w, "Error: Contract changed meanwhile, retry the amendment", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Error updating the Contract", http.StatusInternalServerError)
+		return
+	}
+
+	if err := g.resizeFlavorRemainder(r.Context(), contract, current, &amended); err != nil {
+		if undoErr := g.undoAmendment(r.Context(), contract, previous); undoErr != nil {
+			klog.Errorf("Error undoing the amendment of Contract %s: %s", contractID, undoErr)
+			g.recorder.Event(contract, corev1.EventTypeWarning, "AmendmentInconsistent",
+				"Contract amended but its Flavor could not be resized, it is recomputed on release: "+err.Error())
+			http.Error(w, "Error resizing the Flavor of the Contract", http.StatusInternalServerError)
+			return
+		}
+		if errors.Is(err, errInsufficientCapacity) {
+			klog.Infof("Contract %s cannot be amended: %s", contractID, err)
+			http.Error(w, "Error: "+err.Error(), http.StatusConflict)
+			return
+		}
+		klog.Errorf("Error resizing the Flavor of the Contract: %s", err)
+		http.Error(w, "Error resizing the Flavor of the Contract", http.StatusInternalServerError)
+		return
+	}
+
+	klog.Infof("Contract %s amended to %s CPU, %s memory and %s pods", contractID,
+		amended.CPU.String(), amended.Memory.String(), amended.Pods.String())
+
+	encodeResponse(w, parseutil.ParseContract(contract))
+}
+
+// getHealth returns the identity of the FLUIDOS Node, so that peers can check the gateway is reachable and ready.
+func (g *Gateway) getHealth(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encodeResponse(w, g.ID)
+}
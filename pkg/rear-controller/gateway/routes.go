@@ -14,6 +14,8 @@
 
 package gateway
 
+import "github.com/fluidos-project/node/pkg/utils/consts"
+
 // Routes defines the routes for the rear controller.
 var Routes = struct {
 	// Flavors is the route to get all the flavors.
@@ -30,6 +32,14 @@ var Routes = struct {
 	Reserve string
 	// Purchase is the route to purchase a flavor.
 	Purchase string
+	// Health is the route to check the health of the gateway.
+	Health string
+	// Credentials is the route to rotate the Liqo credentials of a contract.
+	Credentials string
+	// Renew is the route to renew a contract.
+	Renew string
+	// Amend is the route to resize the resources bought with a contract.
+	Amend string
 }{
 	Flavors:        "/api/v2/flavors",
 	K8SliceFlavors: "/api/v2/flavors/k8slice",
@@ -38,4 +48,8 @@ var Routes = struct {
 	SensorsFlavors: "/api/v2/flavors/sensors",
 	Reserve:        "/api/v2/reservations",
 	Purchase:       "/api/v2/transactions/{transactionID}/purchase",
+	Health:         consts.GatewayHealthRoute,
+	Credentials:    "/api/v2/contracts/{contractID}/credentials",
+	Renew:          "/api/v2/contracts/{contractID}/renew",
+	Amend:          "/api/v2/contracts/{contractID}/amend",
 }
@@ -15,6 +15,7 @@
 package gateway
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,8 +26,13 @@ import (
 
 	resourceLib "k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
 	"github.com/fluidos-project/node/pkg/utils/models"
+	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 )
 
 // selectorToQueryParams converts a selector to a query string.
@@ -111,6 +117,38 @@ func (g *Gateway) removeTransaction(transactionID string) {
 	delete(g.Transactions, transactionID)
 }
 
+// storeTransaction records a Transaction on a Flavor sold by this node as a Transaction resource,
+// so that the other components know the Flavor is being reserved.
+func (g *Gateway) storeTransaction(ctx context.Context, transaction *models.Transaction) error {
+	forged := resourceforge.ForgeTransactionFromObj(transaction)
+	stored := &reservationv1alpha1.Transaction{ObjectMeta: forged.ObjectMeta}
+	if _, err := controllerutil.CreateOrUpdate(ctx, g.client, stored, func() error {
+		stored.Spec = forged.Spec
+		return nil
+	}); err != nil {
+		klog.Errorf("Error when storing Transaction %s: %s", transaction.TransactionID, err)
+		return err
+	}
+	return nil
+}
+
+// deleteTransaction deletes the Transaction resource of a Transaction on a Flavor sold by this node.
+func (g *Gateway) deleteTransaction(ctx context.Context, transactionID string) error {
+	stored := &reservationv1alpha1.Transaction{}
+	stored.Name = transactionID
+	stored.Namespace = flags.FluidosNamespace
+	if err := g.client.Delete(ctx, stored); client.IgnoreNotFound(err) != nil {
+		klog.Errorf("Error when deleting Transaction %s: %s", transactionID, err)
+		return err
+	}
+	return nil
+}
+
+// isSoldTransaction returns true if the Transaction is on a Flavor sold by this node.
+func (g *Gateway) isSoldTransaction(transaction *models.Transaction) bool {
+	return g.ID == nil || transaction.Buyer.NodeID != g.ID.NodeID
+}
+
 // handleError handles errors by sending an error response.
 func handleError(w http.ResponseWriter, err error, statusCode int) {
 	http.Error(w, err.Error(), statusCode)
@@ -167,7 +205,14 @@ func encodeK8SliceSelector(selector models.K8SliceSelector) (string, error) {
 		klog.Info("Encoding Storage")
 		storageEncoded := encoderResourceQuantityFilter(reflect.ValueOf(selector.Storage))
 		for key, value := range storageEncoded {
-			values += fmt.Sprintf("[storage]%s=%s&", key, value)
+			values += fmt.Sprintf("filter[storage]%s=%s&", key, value)
+		}
+	}
+	if selector.StorageClass != nil {
+		klog.Info("Encoding StorageClass")
+		storageClassEncoded := encodeStringFilter(reflect.ValueOf(selector.StorageClass))
+		for key, value := range storageClassEncoded {
+			values += fmt.Sprintf("filter[storageClass]%s=%s&", key, value)
 		}
 	}
 
@@ -437,7 +482,7 @@ func decodeK8SliceSelector(values url.Values) (*models.K8SliceSelector, error) {
 	selector := models.K8SliceSelector{}
 
 	// Define the regex pattern for the expected keys
-	keyPattern := regexp.MustCompile(`^filter\[(architecture|cpu|memory|pods|storage)\]\[(match|range)\](?:\[(min|max|regex)\])?$`)
+	keyPattern := regexp.MustCompile(`^filter\[(architecture|cpu|memory|pods|storage|storageClass)\]\[(match|range)\](?:\[(min|max|regex)\])?$`)
 
 	for key, value := range values {
 		// Check if the key matches the expected pattern
@@ -498,6 +543,13 @@ func decodeK8SliceSelector(values url.Values) (*models.K8SliceSelector, error) {
 			}
 			selector.Storage = filter
 			klog.Infof("Selector Storage: %v", selector.Storage)
+		case "storageClass":
+			filter, err := decodeStringFilter(filterTypeName, selector.StorageClass, value)
+			if err != nil {
+				return nil, err
+			}
+			selector.StorageClass = filter
+			klog.Infof("Selector StorageClass: %v", selector.StorageClass)
 		default:
 			return nil, fmt.Errorf("invalid resource: %s", resource)
 		}
@@ -17,6 +17,10 @@ package rearmanager
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/liqotech/liqo/apis/core/v1beta1"
@@ -30,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -48,6 +53,7 @@ import (
 	"github.com/fluidos-project/node/pkg/utils/namings"
 	"github.com/fluidos-project/node/pkg/utils/resourceforge"
 	"github.com/fluidos-project/node/pkg/utils/services"
+	"github.com/fluidos-project/node/pkg/utils/tools"
 	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
 )
 
@@ -60,6 +66,7 @@ import (
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=serviceblueprints,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=serviceblueprints/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nodecore.fluidos.eu,resources=allocations/finalizers,verbs=update
+// +kubebuilder:rbac:groups=reservation.fluidos.eu,resources=transactions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core.liqo.io,resources=foreignclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.liqo.io,resources=foreignclusters/status,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.liqo.io,resources=foreignclusters/finalizers,verbs=get;update;patch
@@ -71,6 +78,7 @@ import (
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete;deletecollection;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;delete;deletecollection;patch;update;get;list;watch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=create;delete;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=create;delete;deletecollection;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=create;delete;deletecollection;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=create;delete;deletecollection;patch;update;get;list;watch
@@ -82,6 +90,15 @@ import (
 // +kubebuilder:rbac:groups="batch",resources=cronjobs,verbs=create;delete;deletecollection;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="batch",resources=jobs,verbs=create;delete;deletecollection;patch;update;get;list;watch
 // +kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses,verbs=create;delete;deletecollection;patch;update;get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings;roles;rolebindings,verbs=get;delete
+
+// releaseRequeueInterval is the interval between two checks of an ongoing teardown.
+const releaseRequeueInterval = 10 * time.Second
+
+// peeringRequeueInterval is the interval between two checks of an ongoing peering.
+const peeringRequeueInterval = 5 * time.Second
 
 // AllocationReconciler reconciles a Allocation object.
 type AllocationReconciler struct {
@@ -89,6 +106,7 @@ type AllocationReconciler struct {
 	RestConfig *rest.Config
 	Manager    manager.Manager
 	Scheme     *runtime.Scheme
+	Recorder   record.EventRecorder
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -204,11 +222,14 @@ func (r *AllocationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 }
 
-// checkInitialStatus checks if the Allocation is in an initial status, if not it sets it to Inactive.
+// checkInitialStatus checks if the Allocation is in a known status, if not it sets it to Inactive.
+// An Allocation in Error is never reset: it stays in Error until it is Released, as enforced by the webhook.
 func (r *AllocationReconciler) checkInitialStatus(allocation *nodecorev1alpha1.Allocation) bool {
-	if allocation.Status.Status != nodecorev1alpha1.Active &&
+	if allocation.Status.Status != nodecorev1alpha1.Error &&
+		allocation.Status.Status != nodecorev1alpha1.Active &&
 		allocation.Status.Status != nodecorev1alpha1.Provisioning &&
 		allocation.Status.Status != nodecorev1alpha1.Released &&
+		allocation.Status.Status != nodecorev1alpha1.Degraded &&
 		allocation.Status.Status != nodecorev1alpha1.Peering &&
 		allocation.Status.Status != nodecorev1alpha1.ResourceCreation &&
 		allocation.Status.Status != nodecorev1alpha1.Inactive {
@@ -223,11 +244,16 @@ func (r *AllocationReconciler) handleK8SliceProviderAllocation(ctx context.Conte
 	allocStatus := allocation.Status.Status
 	// Get the contract related to the Allocation
 	switch allocStatus {
-	case nodecorev1alpha1.Active:
-		// We need to check if the ForeignCluster is still ready
-		// If the ForeignCluster is not ready we need to set the Allocation to Released
-		klog.Infof("Allocation %s is active", req.NamespacedName)
-		return ctrl.Result{}, nil
+	case nodecorev1alpha1.Active, nodecorev1alpha1.Degraded:
+		// The persistent storage bought by the consumer is enforced in its tenant namespace
+		if err := r.syncStorageQuota(ctx, contract.Spec.Buyer.AdditionalInformation.LiqoID); err != nil {
+			klog.Errorf("Error when enforcing the storage quota of Allocation %s: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		// We need to check if the incoming peering is still healthy
+		klog.Infof("Allocation %s is %s", req.NamespacedName, allocStatus)
+		return r.checkAllocationHealth(ctx, req, allocation, contract.Spec.Buyer.AdditionalInformation.LiqoID,
+			virtualfabricmanager.PeeringHealthOptions{ResourceSlice: contract.Name})
 	case nodecorev1alpha1.Provisioning:
 		// We need to check the status of the ForeignCluster
 		// If the ForeignCluster is Ready the Allocation can be set to Active
@@ -278,10 +304,8 @@ func (r *AllocationReconciler) handleK8SliceProviderAllocation(ctx context.Conte
 		}
 		return ctrl.Result{}, nil
 	case nodecorev1alpha1.Released:
-		// The Allocation is released,
-		klog.Infof("Allocation %s is released", req.NamespacedName)
-		// We need to check if the ForeignCluster is again ready
-		return ctrl.Result{}, nil
+		// The Allocation is released, the peering is torn down by the consumer
+		return r.releaseK8SliceProviderAllocation(ctx, req, allocation, contract)
 	case nodecorev1alpha1.Inactive:
 		// Allocation is performed by the provider, so we need to invalidate the Flavor
 		// and eventually create a new one detaching the right Partition from the old one
@@ -327,25 +351,18 @@ func (r *AllocationReconciler) handleK8SliceConsumerAllocation(ctx context.Conte
 	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract) (ctrl.Result, error) {
 	allocStatus := allocation.Status.Status
 	switch allocStatus {
-	case nodecorev1alpha1.Active:
+	case nodecorev1alpha1.Active, nodecorev1alpha1.Degraded:
 		// The Allocation is active,
-		// We need to check if the ForeignCluster is ready
+		// We need to check if the outgoing peering is still healthy
+		clusterID := contract.Spec.PeeringTargetCredentials.ClusterID
 
 		// Get the foreign cluster related to the Allocation
-		fc, err := fcutils.GetForeignClusterByID(ctx, r.Client, v1beta1.ClusterID(contract.Spec.PeeringTargetCredentials.ClusterID))
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				// The ForeignCluster is not found
-				// We need to roll back to the establish peering phase
-				klog.Infof("ForeignCluster %s not found", contract.Spec.PeeringTargetCredentials.ClusterID)
-				// Change the status of the Allocation to Reserved
-				allocation.SetStatus(nodecorev1alpha1.Provisioning, "ForeignCluster not found, peering not yet started")
-			} else {
-				// Error when getting the ForeignCluster
-				klog.Errorf("Error when getting ForeignCluster %s: %v", contract.Spec.PeeringTargetCredentials.ClusterID, err)
-				// Change the status of the Allocation to Error
-				allocation.SetStatus(nodecorev1alpha1.Error, "Error when getting ForeignCluster")
-			}
+		fc, err := fcutils.GetForeignClusterByID(ctx, r.Client, v1beta1.ClusterID(clusterID))
+		if err != nil && !apierrors.IsNotFound(err) {
+			// Error when getting the ForeignCluster
+			klog.Errorf("Error when getting ForeignCluster %s: %v", clusterID, err)
+			// Change the status of the Allocation to Error
+			allocation.SetStatus(nodecorev1alpha1.Error, "Error when getting ForeignCluster")
 			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
 				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
 				return ctrl.Result{}, err
@@ -353,36 +370,28 @@ func (r *AllocationReconciler) handleK8SliceConsumerAllocation(ctx context.Conte
 			return ctrl.Result{}, nil
 		}
 
-		allocation.SetResourceRef(nodecorev1alpha1.GenericRef{
-			Name:       fc.Name,
-			Namespace:  fc.Namespace,
-			Kind:       fc.Kind,
-			APIVersion: fc.APIVersion,
-		})
-
-		// Check if the ForeignCluster is ready with Liqo checks
-		if fcutils.IsProvider(fc.Status.Role) &&
-			fcutils.IsNetworkingModuleEnabled(fc) &&
-			fcutils.IsNetworkingEstablished(fc) &&
-			fcutils.IsAuthenticationModuleEnabled(fc) &&
-			fcutils.IsOffloadingModuleEnabled(fc) {
-			// The ForeignCluster is ready
-			klog.Infof("ForeignCluster %s is ready, outgoing peering established", contract.Spec.PeeringTargetCredentials.ClusterID)
-			// Change the status of the Allocation to Active
-			allocation.SetStatus(nodecorev1alpha1.Active, "Outgoing peering ready, Allocation is Active")
-		} else {
-			// The ForeignCluster is not ready
-			klog.Infof("ForeignCluster %s is not ready yet", contract.Spec.PeeringTargetCredentials.ClusterID)
-			// Change the status of the Allocation to Reserved
-			allocation.SetStatus(nodecorev1alpha1.Active, "Outgoing peering not yet ready, Allocation is Active")
+		if err == nil && allocation.Status.ResourceRef.Name != fc.Name {
+			allocation.SetResourceRef(nodecorev1alpha1.GenericRef{
+				Name:       fc.Name,
+				Namespace:  fc.Namespace,
+				Kind:       fc.Kind,
+				APIVersion: fc.APIVersion,
+			})
+			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
 		}
 
-		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
-			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+		// The Contract may have been amended since the peering was established
+		if err := virtualfabricmanager.SyncResourceSlice(ctx, r.Client, contract, clusterID); err != nil {
+			klog.Errorf("Error when syncing the ResourceSlice of Allocation %s: %v", req.NamespacedName, err)
 			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{}, nil
+		// A missing ForeignCluster is reported as a reason of degradation
+		return r.checkAllocationHealth(ctx, req, allocation, clusterID,
+			virtualfabricmanager.PeeringHealthOptions{ResourceSlice: contract.Name, VirtualNode: true})
 	case nodecorev1alpha1.Peering:
 		// The Allocation is Peering,
 		// We need to establish the peering with the ForeignCluster
@@ -421,17 +430,12 @@ func (r *AllocationReconciler) handleK8SliceConsumerAllocation(ctx context.Conte
 			}
 			return ctrl.Result{}, nil
 		}
-		if _, err = virtualfabricmanager.PeerWithCluster(ctx, r.Client, r.RestConfig, remoteClient, remoteRestConfig, contract); err != nil {
-			klog.Errorf("Error when peering with cluster %s: %s", credentials.ClusterID, err)
-			allocation.SetStatus(nodecorev1alpha1.Error, "Error when peering with cluster "+credentials.ClusterID)
-			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
-				klog.Errorf("Error when updating Solver %s status: %s", req.NamespacedName, err)
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, err
+		if completed, result, err := r.advancePeering(ctx, req, allocation, contract,
+			r.Client, r.RestConfig, remoteClient, remoteRestConfig); !completed {
+			return result, err
 		}
 		// Peering established
-		klog.Infof("Allocation %s has started the peering with cluster %s", req.NamespacedName.Name, credentials.ClusterID)
+		klog.Infof("Allocation %s has established the peering with cluster %s", req.NamespacedName.Name, credentials.ClusterID)
 
 		// Change the status of the Allocation to Active
 		allocation.SetStatus(nodecorev1alpha1.Active, "Allocation is now Active")
@@ -443,8 +447,8 @@ func (r *AllocationReconciler) handleK8SliceConsumerAllocation(ctx context.Conte
 		return ctrl.Result{}, nil
 	case nodecorev1alpha1.Released:
 		// The Allocation is released,
-		// We need to check if the ForeignCluster is again ready
-		return ctrl.Result{}, nil
+		// We need to tear down what has been created by the peering
+		return r.releaseK8SliceConsumerAllocation(ctx, req, allocation, contract)
 	case nodecorev1alpha1.Inactive:
 		klog.Infof("Allocation %s is inactive", req.NamespacedName)
 
@@ -461,15 +465,293 @@ func (r *AllocationReconciler) handleK8SliceConsumerAllocation(ctx context.Conte
 	}
 }
 
+// advancePeering runs the pending steps of the peering of an Allocation, recording the step reached in its status.
+// It returns true once the peering is established. Otherwise the reconcile has to return the given result and error,
+// and the peering resumes from the recorded step on the next reconcile. The Allocation is set in Error when a step
+// fails, or when it is still in progress after flags.ExpirationPeeringStep.
+func (r *AllocationReconciler) advancePeering(ctx context.Context, req ctrl.Request,
+	allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract,
+	localClient client.Client, localRestConfig *rest.Config,
+	remoteClient client.Client, remoteRestConfig *rest.Config) (bool, ctrl.Result, error) {
+	err := virtualfabricmanager.PeerWithCluster(ctx, localClient, localRestConfig, remoteClient, remoteRestConfig, contract,
+		allocation.Status.PeeringStep, func(step nodecorev1alpha1.PeeringStep, msg string) {
+			allocation.SetPeeringStep(step, msg)
+		})
+	if errors.Is(err, virtualfabricmanager.ErrPeeringInProgress) {
+		if tools.CheckExpirationSinceTime(allocation.Status.PeeringStepTime, flags.ExpirationPeeringStep) {
+			err = fmt.Errorf("still in progress after %s: %w", flags.ExpirationPeeringStep, err)
+		} else {
+			klog.Infof("Allocation %s peering in progress: %v", req.NamespacedName, err)
+			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+				return false, ctrl.Result{}, err
+			}
+			return false, ctrl.Result{RequeueAfter: peeringRequeueInterval}, nil
+		}
+	}
+	if err != nil {
+		klog.Errorf("Error when peering Allocation %s: %v", req.NamespacedName, err)
+		allocation.SetStatus(nodecorev1alpha1.Error, "Error during step "+string(allocation.Status.PeeringStep)+": "+err.Error())
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return false, ctrl.Result{}, err
+		}
+		return false, ctrl.Result{}, err
+	}
+
+	allocation.SetPeeringStep(nodecorev1alpha1.PeeringCompleted, "Peering completed")
+	return true, ctrl.Result{}, nil
+}
+
+// releaseK8SliceConsumerAllocation tears down the peering established for a released K8Slice Allocation.
+func (r *AllocationReconciler) releaseK8SliceConsumerAllocation(ctx context.Context,
+	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract) (ctrl.Result, error) {
+	if allocation.Status.ReleaseStep == nodecorev1alpha1.ReleaseCompleted {
+		klog.Infof("Allocation %s is released", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	credentials := contract.Spec.PeeringTargetCredentials
+	klog.Infof("Allocation %s is released, tearing down the peering with cluster %s", req.NamespacedName, credentials.ClusterID)
+
+	kubeconfig, err := virtualfabricmanager.DecodeKubeconfig(credentials.Kubeconfig)
+	if err != nil {
+		klog.Errorf("Error when decoding Kubeconfig: %v", err)
+		allocation.SetStatus(nodecorev1alpha1.Error, "Error when decoding Kubeconfig")
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	remoteClient, _, err := virtualfabricmanager.CreateKubeClientFromConfig(kubeconfig, r.Client.Scheme())
+	if err != nil {
+		klog.Errorf("Error when creating remote client: %v", err)
+		allocation.SetStatus(nodecorev1alpha1.Error, "Error when creating remote client")
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	keepPeering, err := r.isPeerInUse(ctx, allocation, credentials.ClusterID, func(c *reservation.Contract) string {
+		return c.Spec.PeeringTargetCredentials.ClusterID
+	})
+	if err != nil {
+		klog.Errorf("Error when checking other Allocations on cluster %s: %v", credentials.ClusterID, err)
+		return ctrl.Result{}, err
+	}
+
+	err = virtualfabricmanager.UnpeerWithCluster(ctx, r.Client, r.RestConfig, remoteClient, contract, keepPeering,
+		func(step nodecorev1alpha1.ReleaseStep, msg string) {
+			allocation.SetReleaseStep(step, msg)
+		})
+	if errors.Is(err, virtualfabricmanager.ErrTeardownInProgress) {
+		klog.Infof("Allocation %s teardown in progress: %v", req.NamespacedName, err)
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: releaseRequeueInterval}, nil
+	}
+	if err != nil {
+		klog.Errorf("Error when tearing down the peering with cluster %s: %v", credentials.ClusterID, err)
+		allocation.Status.Message = "Error during step " + string(allocation.Status.ReleaseStep) + ": " + err.Error()
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+
+	if keepPeering {
+		allocation.SetReleaseStep(nodecorev1alpha1.ReleaseCompleted, "Teardown completed, peering kept for other contracts")
+	} else {
+		allocation.SetReleaseStep(nodecorev1alpha1.ReleaseCompleted, "Teardown completed, peering removed")
+	}
+	if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+		klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// releaseK8SliceProviderAllocation waits for the consumer to tear down the peering of a released K8Slice Allocation,
+// then gives the released capacity back to the Flavor catalog.
+func (r *AllocationReconciler) releaseK8SliceProviderAllocation(ctx context.Context,
+	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract) (ctrl.Result, error) {
+	if allocation.Status.ReleaseStep == nodecorev1alpha1.ReleaseCompleted {
+		klog.Infof("Allocation %s is released", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	buyerID := contract.Spec.Buyer.AdditionalInformation.LiqoID
+	keepPeering, err := r.isPeerInUse(ctx, allocation, buyerID, func(c *reservation.Contract) string {
+		return c.Spec.Buyer.AdditionalInformation.LiqoID
+	})
+	if err != nil {
+		klog.Errorf("Error when checking other Allocations of cluster %s: %v", buyerID, err)
+		return ctrl.Result{}, err
+	}
+
+	if !keepPeering {
+		// The credentials handed to the consumer expire with its last Allocation, whether it tears down the peering or not
+		if err := virtualfabricmanager.RevokePeeringCredentials(ctx, r.Client, buyerID); err != nil {
+			klog.Errorf("Error when revoking credentials of cluster %s: %v", buyerID, err)
+			allocation.SetReleaseStep(nodecorev1alpha1.ReleaseRemovingAuthentication, "Error when revoking credentials: "+err.Error())
+			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			}
+			return ctrl.Result{}, err
+		}
+
+		// The consumer removes the tenant namespace it created on this cluster as last teardown step
+		ns := &corev1.Namespace{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: virtualfabricmanager.TenantNamespaceName(buyerID)}, ns)
+		if client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when getting tenant namespace of cluster %s: %v", buyerID, err)
+			return ctrl.Result{}, err
+		}
+		if err == nil {
+			if result, err := r.waitConsumerTeardown(ctx, req, allocation, buyerID); err != nil || !result.IsZero() {
+				return result, err
+			}
+		}
+	}
+
+	// The storage released is not available to the consumer anymore, when it keeps the peering for other Contracts
+	if err := r.syncStorageQuota(ctx, buyerID); err != nil {
+		klog.Errorf("Error when updating the storage quota of cluster %s: %v", buyerID, err)
+		return ctrl.Result{}, err
+	}
+
+	// Give the released capacity back to the Flavor catalog
+	allocation.SetReleaseStep(nodecorev1alpha1.ReleaseRestoringFlavor, "Restoring availability of Flavor "+contract.Spec.Flavor.Name)
+	if err := restoreFlavorAvailability(ctx, contract, r.Client); err != nil {
+		if errors.Is(err, errFlavorRestorePending) {
+			klog.Infof("Allocation %s cannot restore Flavor %s yet: %v", req.NamespacedName, contract.Spec.Flavor.Name, err)
+			allocation.Status.Message = "Waiting for pending Contracts before restoring Flavor " + contract.Spec.Flavor.Name
+			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: releaseRequeueInterval}, nil
+		}
+		klog.Errorf("Error when restoring Flavor %s availability: %v", contract.Spec.Flavor.Name, err)
+		allocation.Status.Message = "Error when restoring Flavor availability: " + err.Error()
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+
+	allocation.SetReleaseStep(nodecorev1alpha1.ReleaseCompleted, "Teardown completed")
+	if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+		klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// waitConsumerTeardown waits for the consumer to tear down the peering, up to flags.ExpirationPeeringStep since the release.
+// Past that deadline, the consumer is considered gone or broken, and the peering is torn down on this side.
+// It returns an empty result once the tenant namespace of the consumer is gone.
+func (r *AllocationReconciler) waitConsumerTeardown(ctx context.Context,
+	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, buyerID string) (ctrl.Result, error) {
+	// The Allocations released before the release time was recorded start waiting now
+	changed := false
+	if allocation.Status.ReleaseTime == "" {
+		allocation.Status.ReleaseTime = tools.GetTimeNow()
+		changed = true
+	}
+
+	if !tools.CheckExpirationSinceTime(allocation.Status.ReleaseTime, flags.ExpirationPeeringStep) {
+		klog.Infof("Allocation %s is released, waiting for cluster %s to tear down the peering", req.NamespacedName, buyerID)
+		if allocation.Status.ReleaseStep != nodecorev1alpha1.ReleaseRemovingTenantNamespace {
+			allocation.SetReleaseStep(nodecorev1alpha1.ReleaseRemovingTenantNamespace, "Waiting for cluster "+buyerID+" to tear down the peering")
+			changed = true
+		}
+		if changed {
+			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: releaseRequeueInterval}, nil
+	}
+
+	klog.Infof("Allocation %s is released, cluster %s has not torn down the peering in time, removing it", req.NamespacedName, buyerID)
+	err := virtualfabricmanager.RemoveConsumerPeering(ctx, r.Client, buyerID)
+	if errors.Is(err, virtualfabricmanager.ErrTeardownInProgress) {
+		allocation.SetReleaseStep(nodecorev1alpha1.ReleaseRemovingTenantNamespace, "Removing the peering of cluster "+buyerID+", not torn down in time")
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: releaseRequeueInterval}, nil
+	}
+	if err != nil {
+		klog.Errorf("Error when removing the peering of cluster %s: %v", buyerID, err)
+		allocation.SetReleaseStep(nodecorev1alpha1.ReleaseRemovingTenantNamespace, "Error when removing the peering: "+err.Error())
+		if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+			klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+		}
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// isPeerInUse checks whether another Allocation that has not been released refers to the same peer cluster.
+// The peer of each Contract is extracted by peerOf.
+func (r *AllocationReconciler) isPeerInUse(ctx context.Context, allocation *nodecorev1alpha1.Allocation,
+	clusterID string, peerOf func(*reservation.Contract) string) (bool, error) {
+	allocations := &nodecorev1alpha1.AllocationList{}
+	if err := r.Client.List(ctx, allocations, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Allocations: %v", err)
+		return false, err
+	}
+
+	for i := range allocations.Items {
+		other := &allocations.Items[i]
+		if other.Name == allocation.Name && other.Namespace == allocation.Namespace {
+			continue
+		}
+		if other.Status.Status == nodecorev1alpha1.Released || other.Status.Status == nodecorev1alpha1.Error {
+			continue
+		}
+		contract := &reservation.Contract{}
+		if err := r.Client.Get(ctx, types.NamespacedName{
+			Name:      other.Spec.Contract.Name,
+			Namespace: other.Spec.Contract.Namespace,
+		}, contract); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			klog.Errorf("Error when getting Contract %s: %v", other.Spec.Contract.Name, err)
+			return false, err
+		}
+		if peerOf(contract) == clusterID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (r *AllocationReconciler) handleServiceProviderAllocation(ctx context.Context,
 	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract) (ctrl.Result, error) {
 	allocStatus := allocation.Status.Status
 	// Get the contract related to the Allocation
 	switch allocStatus {
-	case nodecorev1alpha1.Active:
+	case nodecorev1alpha1.Active, nodecorev1alpha1.Degraded:
 		// We need to check if the ForeignCluster is still ready
 		// Get the foreign cluster related to the Allocation
-		fc, err := fcutils.GetForeignClusterByID(ctx, r.Client, v1beta1.ClusterID(contract.Spec.Buyer.AdditionalInformation.LiqoID))
+		_, err := fcutils.GetForeignClusterByID(ctx, r.Client, v1beta1.ClusterID(contract.Spec.Buyer.AdditionalInformation.LiqoID))
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				// The ForeignCluster is not found
@@ -490,30 +772,10 @@ func (r *AllocationReconciler) handleServiceProviderAllocation(ctx context.Conte
 			return ctrl.Result{}, nil
 		}
 
-		// Check if the ForeignCluster is ready with Liqo checks
-		if fcutils.IsNetworkingModuleEnabled(fc) &&
-			fcutils.IsNetworkingEstablished(fc) &&
-			fcutils.IsAuthenticationModuleEnabled(fc) &&
-			fcutils.IsOffloadingModuleEnabled(fc) {
-			// The ForeignCluster is ready
-			klog.Infof("ForeignCluster %s is ready, outgoing peering established", contract.Spec.Buyer.AdditionalInformation.LiqoID)
-			// Change the status of the Allocation to Active
-			// allocation.SetStatus(nodecorev1alpha1.Active, "Outgoing peering ready, Allocation is Active")
-		} else {
-			// The ForeignCluster is not ready
-			klog.Infof("ForeignCluster %s is not ready yet", contract.Spec.Buyer.AdditionalInformation.LiqoID)
-			// Change the status of the Allocation to Active
-			allocation.SetStatus(nodecorev1alpha1.Active, "Outgoing peering not yet ready, Allocation is Active")
-
-			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
-				klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
-				return ctrl.Result{}, err
-			}
-		}
-
+		// Check if the peering is still healthy
 		// TODO(Service): check if the service software applied is working correctly, maybe checking the pods deployed in the namespace offloaded.
-
-		return ctrl.Result{}, nil
+		return r.checkAllocationHealth(ctx, req, allocation, contract.Spec.Buyer.AdditionalInformation.LiqoID,
+			virtualfabricmanager.PeeringHealthOptions{})
 	case nodecorev1alpha1.ResourceCreation:
 		// The Allocation is ResourceCreation,
 
@@ -846,10 +1108,11 @@ func (r *AllocationReconciler) handleServiceConsumerAllocation(ctx context.Conte
 	req ctrl.Request, allocation *nodecorev1alpha1.Allocation, contract *reservation.Contract) (ctrl.Result, error) {
 	allocStatus := allocation.Status.Status
 	switch allocStatus {
-	case nodecorev1alpha1.Active:
-		// TODO(Service): implement Active Allocation Consumer side
-		klog.Infof("Allocation %s is active", req.NamespacedName)
-		return ctrl.Result{}, nil
+	case nodecorev1alpha1.Active, nodecorev1alpha1.Degraded:
+		// We need to check if the peering with the provider is still healthy
+		klog.Infof("Allocation %s is %s", req.NamespacedName, allocStatus)
+		return r.checkAllocationHealth(ctx, req, allocation, contract.Spec.Seller.AdditionalInformation.LiqoID,
+			virtualfabricmanager.PeeringHealthOptions{})
 	case nodecorev1alpha1.Provisioning:
 
 		klog.Infof("Allocation %s is provisioning", req.NamespacedName)
@@ -1034,26 +1297,13 @@ func (r *AllocationReconciler) handleServiceConsumerAllocation(ctx context.Conte
 
 		// Perform peering with inverted localCluster as Remote and remoteCluster as Local
 		// This is to establish a peering in a direction Provider->Consumer, so the provider can consume consumer resources to deploy its service.
-		_, err = virtualfabricmanager.PeerWithCluster(
-			ctx,
-			remoteClient,
-			remoteRestConfig,
-			r.Client,
-			r.RestConfig,
-			contract,
-		)
-		if err != nil {
-			klog.Errorf("Error when peering with cluster %s: %s", credentials.ClusterID, err)
-			allocation.SetStatus(nodecorev1alpha1.Error, "Error when peering with cluster "+credentials.ClusterID)
-			if err := r.updateAllocationStatus(ctx, allocation); err != nil {
-				klog.Errorf("Error when updating Solver %s status: %s", req.NamespacedName, err)
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, err
+		if completed, result, err := r.advancePeering(ctx, req, allocation, contract,
+			remoteClient, remoteRestConfig, r.Client, r.RestConfig); !completed {
+			return result, err
 		}
 
 		// Peering established
-		klog.Infof("Allocation %s has started the peering with cluster %s", req.NamespacedName.Name, credentials.ClusterID)
+		klog.Infof("Allocation %s has established the peering with cluster %s", req.NamespacedName.Name, credentials.ClusterID)
 
 		// Change the status of the Allocation to Active
 		allocation.SetStatus(nodecorev1alpha1.Provisioning, "Allocation is now provisioning")
@@ -1099,12 +1349,20 @@ func computeK8SliceCharacteristics(origin *nodecorev1alpha1.K8SliceCharacteristi
 		Pods:         newPods,
 		Gpu: func() *nodecorev1alpha1.GPU {
 			switch {
-			case part.Gpu != nil && origin != nil:
-				// Gpu is present in the origin and in the partition
-				newGpu := origin.DeepCopy()
-				newGpu.Gpu.Count = 0
+			case part.Gpu != nil && origin.Gpu != nil:
+				// Gpu is present in the origin and in the partition: the GPUs and MIG instances of the partition
+				// are taken off the origin, while a partition that does not count them takes all the GPUs
+				newGpu := origin.Gpu.DeepCopy()
+				if part.Gpu.Count == 0 && len(part.Gpu.MIGProfiles) == 0 {
+					newGpu.Count = 0
+					return newGpu
+				}
+				newGpu.Count = max(newGpu.Count-part.Gpu.Count, 0)
+				for i := range newGpu.MIGProfiles {
+					newGpu.MIGProfiles[i].Count = max(newGpu.MIGProfiles[i].Count-part.Gpu.MIGCount(newGpu.MIGProfiles[i].Name), 0)
+				}
 
-				return newGpu.Gpu
+				return newGpu
 			case part.Gpu == nil && origin.Gpu != nil:
 				// Gpu is present in the origin but not in the partition
 				return origin.Gpu.DeepCopy()
@@ -1129,6 +1387,20 @@ func computeK8SliceCharacteristics(origin *nodecorev1alpha1.K8SliceCharacteristi
 				return nil
 			}
 		}(),
+		StorageClasses: func() []nodecorev1alpha1.StorageClassCapacity {
+			// The storage of the partition is taken off its StorageClass, when set
+			classes := slices.Clone(origin.StorageClasses)
+			for i := range classes {
+				if part.Storage != nil && classes[i].Name == part.StorageClass {
+					classes[i].Capacity = classes[i].Capacity.DeepCopy()
+					classes[i].Capacity.Sub(*part.Storage)
+					if classes[i].Capacity.Sign() < 0 {
+						classes[i].Capacity.Set(0)
+					}
+				}
+			}
+			return classes
+		}(),
 	}
 }
 
@@ -1201,6 +1473,8 @@ func reduceFlavorAvailability(ctx context.Context, flavor *nodecorev1alpha1.Flav
 			}
 
 			newFlavor := resourceforge.ForgeFlavorFromRef(flavor, newFlavorType)
+			// Keep track of the Flavor the remainder comes from, so that it can be merged back on release
+			newFlavor.Labels = services.RemainderLabels(flavor)
 			// Create new Flavor
 			if err := r.Create(ctx, newFlavor); err != nil {
 				klog.Errorf("Error when creating Flavor %s: %v", newFlavor.Name, err)
@@ -1315,12 +1589,14 @@ func (r *AllocationReconciler) fcToAllocation(_ context.Context, o client.Object
 	var filteredAllocations []nodecorev1alpha1.Allocation
 	for i := range allocations.Items {
 		allocation := allocations.Items[i]
-		if allocation.Status.Status == nodecorev1alpha1.Active || allocation.Status.Status == nodecorev1alpha1.Provisioning {
+		if allocation.Status.Status == nodecorev1alpha1.Active ||
+			allocation.Status.Status == nodecorev1alpha1.Degraded ||
+			allocation.Status.Status == nodecorev1alpha1.Provisioning {
 			filteredAllocations = append(filteredAllocations, allocation)
 		}
 	}
 	if len(filteredAllocations) == 0 {
-		klog.Infof("No allocations found in Active or Provisioning status")
+		klog.Infof("No allocations found in Active, Degraded or Provisioning status")
 		return nil
 	}
 	var requests []reconcile.Request
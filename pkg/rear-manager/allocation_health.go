@@ -0,0 +1,75 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rearmanager
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
+)
+
+// checkAllocationHealth evaluates the peering backing an active Allocation, and requeues it for the next evaluation.
+func (r *AllocationReconciler) checkAllocationHealth(ctx context.Context, req ctrl.Request, allocation *nodecorev1alpha1.Allocation,
+	remoteClusterID string, opts virtualfabricmanager.PeeringHealthOptions) (ctrl.Result, error) {
+	reasons, err := virtualfabricmanager.CheckPeeringHealth(ctx, r.Client, remoteClusterID, opts)
+	if err != nil {
+		klog.Errorf("Error when checking health of Allocation %s: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	return r.reportAllocationHealth(ctx, req, allocation, reasons)
+}
+
+// reportAllocationHealth moves an Allocation between Active and Degraded according to the given reasons,
+// emitting an event when its Degraded condition changes so that users learn when their capacity is impaired.
+func (r *AllocationReconciler) reportAllocationHealth(ctx context.Context, req ctrl.Request,
+	allocation *nodecorev1alpha1.Allocation, reasons []string) (ctrl.Result, error) {
+	result := ctrl.Result{RequeueAfter: flags.HealthCheckInterval}
+	degraded := allocation.Status.Status == nodecorev1alpha1.Degraded
+
+	switch {
+	case len(reasons) == 0 && !degraded:
+		return result, nil
+	case len(reasons) == 0:
+		klog.Infof("Allocation %s recovered", req.NamespacedName)
+		// Events are only emitted when the health of the allocation changes
+		if allocation.SetDegraded(nil, "Allocation recovered, it is Active again") {
+			r.Recorder.Event(allocation, corev1.EventTypeNormal, "Recovered", "Allocation recovered, it is Active again")
+		}
+	case degraded && slices.Equal(reasons, allocation.Status.DegradedReasons):
+		return result, nil
+	default:
+		msg := "Allocation degraded: " + strings.Join(reasons, "; ")
+		klog.Infof("Allocation %s is degraded: %s", req.NamespacedName, strings.Join(reasons, "; "))
+		if allocation.SetDegraded(reasons, msg) {
+			r.Recorder.Event(allocation, corev1.EventTypeWarning, "Degraded", msg)
+		}
+	}
+
+	if err := r.updateAllocationStatus(ctx, allocation); err != nil {
+		klog.Errorf("Error when updating Allocation %s status: %v", req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	return result, nil
+}
@@ -16,41 +16,120 @@ package rearmanager
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
 
-	// admissionv1 "k8s.io/api/admission/v1".
+	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/getters"
 )
 
+// Paths of the Allocation webhooks.
+const (
+	AllocationValidatingPath = "/validate-nodecore-fluidos-eu-v1alpha1-allocation"
+	AllocationMutatingPath   = "/mutate-nodecore-fluidos-eu-v1alpha1-allocation"
+)
+
+// allocationTransitions lists the statuses an Allocation can move to from each status, as driven by the Allocation controller.
+// An Allocation can always keep its status or be Released, and a Released Allocation cannot move anymore.
+// An Allocation in Error is not retried, so it can only be Released.
+var allocationTransitions = map[nodecorev1alpha1.Status][]nodecorev1alpha1.Status{
+	"":                                {nodecorev1alpha1.Inactive},
+	nodecorev1alpha1.Inactive:         {nodecorev1alpha1.Provisioning, nodecorev1alpha1.Peering, nodecorev1alpha1.Error},
+	nodecorev1alpha1.Provisioning:     {nodecorev1alpha1.ResourceCreation, nodecorev1alpha1.Active, nodecorev1alpha1.Error},
+	nodecorev1alpha1.Peering:          {nodecorev1alpha1.Provisioning, nodecorev1alpha1.Active, nodecorev1alpha1.Error},
+	nodecorev1alpha1.ResourceCreation: {nodecorev1alpha1.Active, nodecorev1alpha1.Error},
+	nodecorev1alpha1.Active:           {nodecorev1alpha1.Provisioning, nodecorev1alpha1.Degraded, nodecorev1alpha1.Error},
+	nodecorev1alpha1.Degraded:         {nodecorev1alpha1.Provisioning, nodecorev1alpha1.Active, nodecorev1alpha1.Error},
+	nodecorev1alpha1.Error:            {},
+}
+
 // Validator is the allocation webhook validator.
 type Validator struct {
-	client  client.Client
+	client client.Client
+	// reader reads from the API server, as an Allocation is created right after its Contract,
+	// which the cache of the client may not have received yet
+	reader  client.Reader
 	decoder admission.Decoder
 }
 
 // NewValidator creates a new allocation webhook validator.
-func NewValidator(c client.Client) *Validator {
-	return &Validator{client: c, decoder: admission.NewDecoder(runtime.NewScheme())}
+func NewValidator(c client.Client, reader client.Reader) *Validator {
+	return &Validator{client: c, reader: reader, decoder: admission.NewDecoder(c.Scheme())}
 }
 
 // Handle manages the validation of the Allocation.
 //
 //nolint:gocritic // This function cannot be changed
 func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
-	_ = ctx
-	_ = req
-	return admission.Allowed("allowed")
+	switch req.Operation {
+	case admissionv1.Create:
+		return v.HandleCreate(ctx, req)
+	case admissionv1.Update:
+		return v.HandleUpdate(ctx, req)
+	case admissionv1.Delete:
+		return v.HandleDelete(ctx, req)
+	default:
+		return admission.Allowed("allowed")
+	}
 }
 
 // HandleCreate manages the validation of the Allocation creation.
+// The referenced Contract must exist, involve this node as stated by the forwarding flag, and have no other Allocation.
 //
 //nolint:gocritic // This function cannot be changed
 func (v *Validator) HandleCreate(ctx context.Context, req admission.Request) admission.Response {
-	_ = ctx
-	_ = req
+	allocation, err := v.DecodeAllocation(req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if allocation.Spec.Contract.Name == "" {
+		return admission.Denied("the contract reference is required")
+	}
+
+	contract := &reservationv1alpha1.Contract{}
+	if err := v.reader.Get(ctx, client.ObjectKey{Name: allocation.Spec.Contract.Name, Namespace: allocation.Spec.Contract.Namespace}, contract); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return admission.Denied(fmt.Sprintf("contract %s/%s not found", allocation.Spec.Contract.Namespace, allocation.Spec.Contract.Name))
+		}
+		klog.Errorf("Error when getting Contract %s: %s", allocation.Spec.Contract.Name, err)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	nodeIdentity := getters.GetNodeIdentity(ctx, v.client)
+	if nodeIdentity == nil {
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("FLUIDOS Node identity not found"))
+	}
+	involved := contract.Spec.Buyer.NodeID == nodeIdentity.NodeID || contract.Spec.Seller.NodeID == nodeIdentity.NodeID
+	switch {
+	case !allocation.Spec.Forwarding && !involved:
+		return admission.Denied(fmt.Sprintf("this node is neither the buyer nor the seller of contract %s", contract.Name))
+	case allocation.Spec.Forwarding && involved:
+		return admission.Denied(fmt.Sprintf("this node is a party of contract %s, its allocation cannot be a forwarding one", contract.Name))
+	}
+
+	allocations := &nodecorev1alpha1.AllocationList{}
+	if err := v.reader.List(ctx, allocations, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Allocations: %s", err)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	for i := range allocations.Items {
+		other := &allocations.Items[i]
+		if other.Spec.Contract.Name == allocation.Spec.Contract.Name && other.Spec.Contract.Namespace == allocation.Spec.Contract.Namespace {
+			return admission.Denied(fmt.Sprintf("contract %s already has allocation %s/%s", contract.Name, other.Namespace, other.Name))
+		}
+	}
+
 	return admission.Allowed("allowed")
 }
 
@@ -64,11 +143,47 @@ func (v *Validator) HandleDelete(ctx context.Context, req admission.Request) adm
 }
 
 // HandleUpdate manages the validation of the Allocation update.
+// The spec of an Allocation is immutable, and its status can only follow the transitions of the Allocation controller.
 //
 //nolint:gocritic // This function cannot be changed
 func (v *Validator) HandleUpdate(ctx context.Context, req admission.Request) admission.Response {
 	_ = ctx
-	_ = req
+	allocation, err := v.DecodeAllocation(req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	oldAllocation, err := v.DecodeAllocation(req.OldObject)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if allocation.Spec.Contract.Name != oldAllocation.Spec.Contract.Name ||
+		allocation.Spec.Contract.Namespace != oldAllocation.Spec.Contract.Namespace {
+		return admission.Denied("the contract reference is immutable")
+	}
+	if allocation.Spec.Forwarding != oldAllocation.Spec.Forwarding {
+		return admission.Denied("the forwarding flag is immutable")
+	}
+
+	from, to := oldAllocation.Status.Status, allocation.Status.Status
+	if from == to {
+		return admission.Allowed("allowed")
+	}
+	if from == nodecorev1alpha1.Released {
+		return admission.Denied("a Released allocation cannot change its status")
+	}
+	if to == nodecorev1alpha1.Released {
+		return admission.Allowed("allowed")
+	}
+	// Unknown statuses are reset to Inactive by the Allocation controller
+	allowed, known := allocationTransitions[from]
+	if !known {
+		allowed = allocationTransitions[""]
+	}
+	if !slices.Contains(allowed, to) {
+		return admission.Denied(fmt.Sprintf("invalid status transition from %q to %q", from, to))
+	}
+
 	return admission.Allowed("allowed")
 }
 
@@ -78,3 +193,41 @@ func (v *Validator) DecodeAllocation(obj runtime.RawExtension) (pc *nodecorev1al
 	err = v.decoder.DecodeRaw(obj, pc)
 	return
 }
+
+// Mutator is the allocation webhook mutator.
+type Mutator struct {
+	decoder admission.Decoder
+}
+
+// NewMutator creates a new allocation webhook mutator.
+func NewMutator(c client.Client) *Mutator {
+	return &Mutator{decoder: admission.NewDecoder(c.Scheme())}
+}
+
+// Handle manages the defaulting of the Allocation.
+// The contract reference defaults to a Contract in the namespace of the Allocation.
+//
+//nolint:gocritic // This function cannot be changed
+func (m *Mutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	_ = ctx
+	allocation := &nodecorev1alpha1.Allocation{}
+	if err := m.decoder.DecodeRaw(req.Object, allocation); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if allocation.Spec.Contract.Namespace == "" {
+		allocation.Spec.Contract.Namespace = req.Namespace
+	}
+	if allocation.Spec.Contract.Kind == "" {
+		allocation.Spec.Contract.Kind = "Contract"
+	}
+	if allocation.Spec.Contract.APIVersion == "" {
+		allocation.Spec.Contract.APIVersion = reservationv1alpha1.GroupVersion.String()
+	}
+
+	marshaled, err := json.Marshal(allocation)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
@@ -0,0 +1,257 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rearmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	advertisementv1alpha1 "github.com/fluidos-project/node/apis/advertisement/v1alpha1"
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservation "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/getters"
+	"github.com/fluidos-project/node/pkg/utils/resourceforge"
+	"github.com/fluidos-project/node/pkg/utils/services"
+	"github.com/fluidos-project/node/pkg/utils/tools"
+)
+
+// errFlavorRestorePending is returned when the capacity of a Flavor cannot be restored yet,
+// because a recent Contract on the same Flavor has not been allocated or the Flavor is being reserved.
+var errFlavorRestorePending = errors.New("flavor restore pending")
+
+// restoreFlavorAvailability gives the capacity of a released K8Slice Contract back to the Flavor catalog.
+// The capacity still sold is recomputed from the Contracts on the same root Flavor, so the function can be
+// called again with the same result. If nothing is sold anymore, the remainders are deleted and the root
+// Flavor becomes available again. Otherwise the available remainders are merged into a single one.
+// It returns errFlavorRestorePending while a Contract on the root Flavor has not been allocated yet, or while a Transaction
+// or a Reservation on the root Flavor is in progress. Expired Contracts and Transactions are ignored, as well as the
+// Contracts and Reservations abandoned for longer than flags.ExpirationAllocation.
+func restoreFlavorAvailability(ctx context.Context, contract *reservation.Contract, cl client.Client) error {
+	rootName := services.FlavorRoot(&contract.Spec.Flavor)
+
+	root := &nodecorev1alpha1.Flavor{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: rootName, Namespace: flags.FluidosNamespace}, root); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			klog.Infof("Flavor %s not found, no availability to restore", rootName)
+			return nil
+		}
+		klog.Errorf("Error when getting Flavor %s: %v", rootName, err)
+		return err
+	}
+
+	rootK8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(root.Spec.FlavorType)
+	if err != nil {
+		klog.Errorf("Error when parsing Flavor %s: %v", root.Name, err)
+		return err
+	}
+
+	remainders := &nodecorev1alpha1.FlavorList{}
+	if err := cl.List(ctx, remainders, client.InNamespace(flags.FluidosNamespace),
+		client.MatchingLabels{consts.FluidosFlavorRootLabel: rootName}); err != nil {
+		klog.Errorf("Error when listing remainders of Flavor %s: %v", rootName, err)
+		return err
+	}
+	sort.Slice(remainders.Items, func(i, j int) bool {
+		return remainders.Items[i].Name < remainders.Items[j].Name
+	})
+
+	lineage := map[string]bool{rootName: true}
+	for i := range remainders.Items {
+		lineage[remainders.Items[i].Name] = true
+	}
+
+	if err := checkPendingReservations(ctx, cl, lineage); err != nil {
+		return err
+	}
+
+	contracts := &reservation.ContractList{}
+	if err := cl.List(ctx, contracts, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Contracts: %v", err)
+		return err
+	}
+
+	// Compute the capacity still available on the root Flavor and which Flavors are still sold
+	available := rootK8Slice.Characteristics.DeepCopy()
+	sold := map[string]bool{}
+	referenced := map[string]bool{}
+	for i := range contracts.Items {
+		other := &contracts.Items[i]
+		referenced[other.Spec.Flavor.Name] = true
+		if other.Name == contract.Name || !lineage[other.Spec.Flavor.Name] {
+			continue
+		}
+		if other.Spec.ExpirationTime != "" && tools.CheckExpiration(other.Spec.ExpirationTime) {
+			klog.Infof("Contract %s on Flavor %s is expired, its capacity is restored", other.Name, other.Spec.Flavor.Name)
+			continue
+		}
+		allocation, err := getters.GetAllocationByContractName(ctx, cl, other.Name)
+		if err != nil || allocation.Status.Status == "" || allocation.Status.Status == nodecorev1alpha1.Inactive {
+			if time.Since(other.CreationTimestamp.Time) > flags.ExpirationAllocation {
+				klog.Infof("Contract %s on Flavor %s has been abandoned without an Allocation, its capacity is restored",
+					other.Name, other.Spec.Flavor.Name)
+				continue
+			}
+			klog.Infof("Contract %s on Flavor %s is not allocated yet", other.Name, other.Spec.Flavor.Name)
+			return fmt.Errorf("%w: contract %s is not allocated yet", errFlavorRestorePending, other.Name)
+		}
+		if allocation.Status.Status == nodecorev1alpha1.Released || allocation.Status.Status == nodecorev1alpha1.Error {
+			continue
+		}
+		part, err := other.K8SlicePartition()
+		if err != nil {
+			klog.Errorf("Error when parsing partition of Contract %s: %v", other.Name, err)
+			return err
+		}
+		available = computeK8SliceCharacteristics(available, part)
+		sold[other.Spec.Flavor.Name] = true
+	}
+
+	if len(sold) == 0 {
+		// Nothing is sold anymore: the root Flavor gets back its whole capacity
+		for i := range remainders.Items {
+			if err := cl.Delete(ctx, &remainders.Items[i]); client.IgnoreNotFound(err) != nil {
+				klog.Errorf("Error when deleting Flavor %s: %v", remainders.Items[i].Name, err)
+				return err
+			}
+			klog.Infof("Flavor %s merged back into Flavor %s", remainders.Items[i].Name, rootName)
+		}
+		if !root.Spec.Availability {
+			root.Spec.Availability = true
+			if err := cl.Update(ctx, root); err != nil {
+				klog.Errorf("Error when updating Flavor %s: %v", rootName, err)
+				return err
+			}
+		}
+		klog.Infof("Flavor %s is available again", rootName)
+		return nil
+	}
+
+	// Part of the root Flavor is still sold: keep a single remainder with the free capacity.
+	// A remainder without some of the resources is kept, but it cannot be sold until it gets them back.
+	hasCapacity := available.CPU.Sign() > 0 && available.Memory.Sign() > 0 && available.Pods.Sign() > 0
+	hasFreeResources := available.CPU.Sign() > 0 || available.Memory.Sign() > 0 || available.Pods.Sign() > 0 ||
+		(available.Storage != nil && available.Storage.Sign() > 0)
+	var keeper *nodecorev1alpha1.Flavor
+	for i := range remainders.Items {
+		remainder := &remainders.Items[i]
+		if sold[remainder.Name] {
+			continue
+		}
+		// The Flavors bought by a Contract are never sold again
+		if hasFreeResources && keeper == nil && (remainder.Spec.Availability || !referenced[remainder.Name]) {
+			keeper = remainder
+			continue
+		}
+		if err := cl.Delete(ctx, remainder); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when deleting Flavor %s: %v", remainder.Name, err)
+			return err
+		}
+		klog.Infof("Flavor %s merged into the free capacity of Flavor %s", remainder.Name, rootName)
+	}
+
+	if !hasFreeResources {
+		klog.Infof("Flavor %s has no free capacity left", rootName)
+		return nil
+	}
+
+	if keeper == nil {
+		newFlavorType := root.Spec.FlavorType.DeepCopy()
+		keeper = resourceforge.ForgeFlavorFromRef(root, newFlavorType)
+		keeper.Labels = services.RemainderLabels(root)
+		if err := services.SetK8SliceCharacteristics(keeper, available); err != nil {
+			klog.Errorf("Error when forging remainder of Flavor %s: %v", rootName, err)
+			return err
+		}
+		keeper.Spec.Availability = hasCapacity
+		if err := cl.Create(ctx, keeper); err != nil {
+			klog.Errorf("Error when creating Flavor %s: %v", keeper.Name, err)
+			return err
+		}
+		klog.Infof("Flavor %s created with the free capacity of Flavor %s", keeper.Name, rootName)
+		return nil
+	}
+
+	if err := services.SetK8SliceCharacteristics(keeper, available); err != nil {
+		klog.Errorf("Error when updating remainder %s: %v", keeper.Name, err)
+		return err
+	}
+	keeper.Spec.Availability = hasCapacity
+	if err := cl.Update(ctx, keeper); err != nil {
+		klog.Errorf("Error when updating Flavor %s: %v", keeper.Name, err)
+		return err
+	}
+	klog.Infof("Flavor %s updated with the free capacity of Flavor %s", keeper.Name, rootName)
+
+	return nil
+}
+
+// checkPendingReservations returns errFlavorRestorePending if a Transaction or a Reservation on one of the Flavors
+// of the lineage is in progress, since the Flavor it references could be merged back before it is purchased.
+func checkPendingReservations(ctx context.Context, cl client.Client, lineage map[string]bool) error {
+	transactions := &reservation.TransactionList{}
+	if err := cl.List(ctx, transactions, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Transactions: %v", err)
+		return err
+	}
+	for i := range transactions.Items {
+		transaction := &transactions.Items[i]
+		if !lineage[transaction.Spec.FlavorID] || tools.CheckExpiration(transaction.Spec.ExpirationTime) {
+			continue
+		}
+		klog.Infof("Transaction %s on Flavor %s is in progress", transaction.Name, transaction.Spec.FlavorID)
+		return fmt.Errorf("%w: transaction %s is in progress", errFlavorRestorePending, transaction.Name)
+	}
+
+	reservations := &reservation.ReservationList{}
+	if err := cl.List(ctx, reservations, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Reservations: %v", err)
+		return err
+	}
+	for i := range reservations.Items {
+		res := &reservations.Items[i]
+		switch res.Status.Phase.Phase {
+		case nodecorev1alpha1.PhaseSolved, nodecorev1alpha1.PhaseFailed, nodecorev1alpha1.PhaseTimeout:
+			continue
+		}
+		// Once reserved, the Reservation is covered by its Transaction
+		if res.Status.TransactionID != "" || time.Since(res.CreationTimestamp.Time) > flags.ExpirationAllocation {
+			continue
+		}
+		peeringCandidate := &advertisementv1alpha1.PeeringCandidate{}
+		if err := cl.Get(ctx, client.ObjectKey{Name: res.Spec.PeeringCandidate.Name, Namespace: res.Spec.PeeringCandidate.Namespace},
+			peeringCandidate); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			klog.Errorf("Error when getting PeeringCandidate %s: %v", res.Spec.PeeringCandidate.Name, err)
+			return err
+		}
+		if !lineage[peeringCandidate.Spec.Flavor.Name] {
+			continue
+		}
+		klog.Infof("Reservation %s on Flavor %s is in progress", res.Name, peeringCandidate.Spec.Flavor.Name)
+		return fmt.Errorf("%w: reservation %s is in progress", errFlavorRestorePending, res.Name)
+	}
+
+	return nil
+}
@@ -843,6 +843,7 @@ func allocationPredicate(c client.Client) predicate.Predicate {
 	return predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			return (e.ObjectNew.(*nodecorev1alpha1.Allocation).Status.Status == nodecorev1alpha1.Active ||
+				e.ObjectNew.(*nodecorev1alpha1.Allocation).Status.Status == nodecorev1alpha1.Degraded ||
 				e.ObjectNew.(*nodecorev1alpha1.Allocation).Status.Status == nodecorev1alpha1.Released) &&
 				!IsProvider(context.Background(), e.ObjectNew.(*nodecorev1alpha1.Allocation), c)
 		},
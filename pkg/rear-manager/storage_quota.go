@@ -0,0 +1,149 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rearmanager
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservation "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+	"github.com/fluidos-project/node/pkg/utils/getters"
+	virtualfabricmanager "github.com/fluidos-project/node/pkg/virtual-fabric-manager"
+)
+
+// storageClassRequests returns the quota resource limiting the storage requested through a StorageClass.
+func storageClassRequests(storageClass string) corev1.ResourceName {
+	return corev1.ResourceName(storageClass + ".storageclass.storage.k8s.io/" + string(corev1.ResourceRequestsStorage))
+}
+
+// syncStorageQuota enforces in the tenant namespace of a buyer the persistent storage it has bought through the
+// Contracts of its Allocations that have not been released. A single ResourceQuota sums all of them, since the
+// ResourceQuotas of a namespace are enforced independently. It is deleted when no storage has been bought.
+func (r *AllocationReconciler) syncStorageQuota(ctx context.Context, buyerID string) error {
+	namespace := virtualfabricmanager.TenantNamespaceName(buyerID)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
+		// The quota is enforced once the peering has created the tenant namespace, and is removed with it
+		return client.IgnoreNotFound(err)
+	}
+
+	hard, err := r.boughtStorage(ctx, buyerID)
+	if err != nil {
+		return err
+	}
+
+	quota := &corev1.ResourceQuota{}
+	quota.Name = consts.StorageQuotaName
+	quota.Namespace = namespace
+	if len(hard) == 0 {
+		if err := r.Client.Delete(ctx, quota); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when deleting storage quota of cluster %s: %v", buyerID, err)
+			return err
+		}
+		return nil
+	}
+
+	res, err := controllerutil.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		quota.Spec.Hard = hard
+		return nil
+	})
+	if err != nil {
+		klog.Errorf("Error when enforcing storage quota of cluster %s: %v", buyerID, err)
+		return err
+	}
+	if res != controllerutil.OperationResultNone {
+		klog.Infof("Storage quota of cluster %s %s: %v", buyerID, res, hard)
+	}
+	return nil
+}
+
+// boughtStorage sums the persistent storage bought by a buyer through the K8Slice Contracts of its active Allocations,
+// in total and through each StorageClass.
+func (r *AllocationReconciler) boughtStorage(ctx context.Context, buyerID string) (corev1.ResourceList, error) {
+	allocations := &nodecorev1alpha1.AllocationList{}
+	if err := r.Client.List(ctx, allocations, client.InNamespace(flags.FluidosNamespace)); err != nil {
+		klog.Errorf("Error when listing Allocations: %v", err)
+		return nil, err
+	}
+
+	nodeIdentity := getters.GetNodeIdentity(ctx, r.Client)
+	if nodeIdentity == nil {
+		return nil, fmt.Errorf("error getting FLUIDOS Node identity")
+	}
+
+	hard := corev1.ResourceList{}
+	add := func(name corev1.ResourceName, qty resource.Quantity) {
+		total := hard[name]
+		total.Add(qty)
+		hard[name] = total
+	}
+
+	for i := range allocations.Items {
+		allocation := &allocations.Items[i]
+		if allocation.Status.Status == nodecorev1alpha1.Released || allocation.Status.Status == nodecorev1alpha1.Error {
+			continue
+		}
+		contract := &reservation.Contract{}
+		if err := r.Client.Get(ctx, types.NamespacedName{
+			Name:      allocation.Spec.Contract.Name,
+			Namespace: allocation.Spec.Contract.Namespace,
+		}, contract); err != nil {
+			if client.IgnoreNotFound(err) == nil {
+				continue
+			}
+			klog.Errorf("Error when getting Contract %s: %v", allocation.Spec.Contract.Name, err)
+			return nil, err
+		}
+		if contract.Spec.Buyer.AdditionalInformation == nil || contract.Spec.Buyer.AdditionalInformation.LiqoID != buyerID ||
+			contract.Spec.Flavor.Spec.FlavorType.TypeIdentifier != nodecorev1alpha1.TypeK8Slice || contract.Spec.Seller.NodeID != nodeIdentity.NodeID {
+			continue
+		}
+
+		partition, err := contract.K8SlicePartition()
+		if err != nil {
+			klog.Errorf("Error when parsing the partition of Contract %s: %v", contract.Name, err)
+			return nil, err
+		}
+		if partition.Storage == nil {
+			continue
+		}
+		add(corev1.ResourceRequestsStorage, *partition.Storage)
+
+		switch {
+		case partition.StorageClass != "":
+			add(storageClassRequests(partition.StorageClass), *partition.Storage)
+		case contract.Spec.Configuration == nil:
+			// The whole Flavor has been bought, with the storage of each of its StorageClasses
+			k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(contract.Spec.Flavor.Spec.FlavorType)
+			if err != nil {
+				return nil, err
+			}
+			for _, class := range k8Slice.Characteristics.StorageClasses {
+				add(storageClassRequests(class.Name), class.Capacity)
+			}
+		}
+	}
+
+	return hard, nil
+}
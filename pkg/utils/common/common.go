@@ -160,7 +160,7 @@ func filterNumberFilter(selectorValue float64, filter models.NumberFilter) bool
 		}
 		// Check if the selector value matches the filter value
 		if selectorValue != matchFilter.Value {
-			klog.Infof("Match Filter: %f - Selector Value: %s", matchFilter.Value, selectorValue)
+			klog.Infof("Match Filter: %f - Selector Value: %f", matchFilter.Value, selectorValue)
 			return false
 		}
 	case models.RangeFilter:
@@ -238,6 +238,32 @@ func filterStringFilter(selectorValue string, filter models.StringFilter) bool {
 	return true
 }
 
+// filterK8SliceStorage returns true if the persistent storage of the K8Slice characteristics fits the Storage and StorageClass filters.
+// With a StorageClass filter, at least one of the matching StorageClasses must offer a storage fitting the Storage filter;
+// otherwise, the Storage filter applies to the storage offered across all the StorageClasses.
+func filterK8SliceStorage(k8SliceSelector *models.K8SliceSelector, characteristics *nodecorev1alpha1.K8SliceCharacteristics) bool {
+	if k8SliceSelector.StorageClass == nil {
+		if k8SliceSelector.Storage == nil {
+			return true
+		}
+		var storage resource.Quantity
+		if characteristics.Storage != nil {
+			storage = *characteristics.Storage
+		}
+		return filterResourceQuantityFilter(storage, *k8SliceSelector.Storage)
+	}
+
+	for _, class := range characteristics.StorageClasses {
+		if !filterStringFilter(class.Name, *k8SliceSelector.StorageClass) {
+			continue
+		}
+		if k8SliceSelector.Storage == nil || filterResourceQuantityFilter(class.Capacity, *k8SliceSelector.Storage) {
+			return true
+		}
+	}
+	return false
+}
+
 // filterFlavorK8Slice return true if the K8Slice Flavor CR fits the K8Slice selector.
 func filterFlavorK8Slice(k8SliceSelector *models.K8SliceSelector, flavorTypeK8SliceCR *nodecorev1alpha1.K8Slice) bool {
 	// Architecture Filter
@@ -275,13 +301,9 @@ func filterFlavorK8Slice(k8SliceSelector *models.K8SliceSelector, flavorTypeK8Sl
 		}
 	}
 
-	// Storage Filter
-	if k8SliceSelector.Storage != nil {
-		// Check if the flavor matches the Storage filter
-		storageFilterModel := *k8SliceSelector.Storage
-		if !filterResourceQuantityFilter(*flavorTypeK8SliceCR.Characteristics.Storage, storageFilterModel) {
-			return false
-		}
+	// Storage and StorageClass Filters
+	if !filterK8SliceStorage(k8SliceSelector, &flavorTypeK8SliceCR.Characteristics) {
+		return false
 	}
 
 	if gpuTraits := flavorTypeK8SliceCR.Characteristics.Gpu; gpuTraits != nil {
@@ -509,6 +531,11 @@ func AllocationStatusCheck(solver *nodecorev1alpha1.Solver, allocation *nodecore
 		solver.Status.Peering = nodecorev1alpha1.PhaseSolved
 		solver.SetPhase(nodecorev1alpha1.PhaseRunning, "Allocation: active")
 	}
+	if allocation.Status.Status == nodecorev1alpha1.Degraded {
+		klog.Infof("Allocation %s is degraded", allocation.Name)
+		solver.Status.Peering = nodecorev1alpha1.PhaseSolved
+		solver.SetPhase(nodecorev1alpha1.PhaseRunning, "Allocation: degraded")
+	}
 	if allocation.Status.Status == nodecorev1alpha1.Provisioning {
 		klog.Infof("Allocation %s is provisioning", allocation.Name)
 		solver.Status.Peering = nodecorev1alpha1.PhaseRunning
@@ -16,6 +16,11 @@ package consts
 
 const (
 	NodeIdentityConfigMapName     = "fluidos-node-identity"
+	StaticPeersConfigMapName      = "fluidos-static-peers"
+	LocationsConfigMapName        = "fluidos-locations"
+	RateCardConfigMapName         = "fluidos-rate-card"
+	StorageConfigMapName          = "fluidos-storage"
+	StorageQuotaName              = "fluidos-storage"
 	LiqoClusterIdConfigMapName    = "liqo-clusterid-configmap"
 	LiqoNamespace                 = "liqo"
 	LiqoAuthTokenSecretNamePrefix = "remote-token-"
@@ -24,6 +29,18 @@ const (
 	FluidosContractLabel          = "reservation.fluidos.eu/contract"
 	FluidosServiceCredentials     = "nodecore.fluidos.eu/flavor-service-credentials"
 	FluidosServiceEndpoint        = "nodecore.fluidos.eu/flavor-service-endpoint"
+	FluidosFlavorRootLabel        = "nodecore.fluidos.eu/flavor-root"
+	FluidosFlavorWithdrawn        = "nodecore.fluidos.eu/withdrawn"
+	FluidosFlavorDisabled         = "nodecore.fluidos.eu/disabled"
+	FluidosFlavorNodePoolLabel    = "nodecore.fluidos.eu/node-pool"
+	FluidosFlavorTemplateLabel    = "nodecore.fluidos.eu/flavor-template"
+	FluidosLocationLatitude       = "nodecore.fluidos.eu/location-latitude"
+	FluidosLocationLongitude      = "nodecore.fluidos.eu/location-longitude"
+	FluidosLocationCountry        = "nodecore.fluidos.eu/location-country"
+	FluidosLocationCity           = "nodecore.fluidos.eu/location-city"
+	FluidosContractRenewal        = "reservation.fluidos.eu/renew"
+	FluidosContractAmendment      = "reservation.fluidos.eu/amend"
+	GatewayHealthRoute            = "/api/v2/health"
 )
 
 // ServiceCategory represents a category of a service
@@ -36,3 +53,13 @@ const (
 	MessageQueue ServiceCategory = "message-queue"
 	// TODO (Service): add more service categories based on ontology
 )
+
+// GPUResources maps the GPU vendors to the extended resource advertised by their device plugins.
+var GPUResources = map[string]string{
+	"nvidia": "nvidia.com/gpu",
+	"amd":    "amd.com/gpu",
+	"intel":  "gpu.intel.com/i915",
+}
+
+// NvidiaMIGResourcePrefix is the prefix of the extended resources advertised for the Multi-Instance GPU profiles.
+const NvidiaMIGResourcePrefix = "nvidia.com/mig-"
@@ -27,8 +27,14 @@ var (
 	ExpirationSolver       = 5 * time.Minute
 	ExpirationTransaction  = 20 * time.Second
 	ExpirationContract     = 365 * 24 * time.Hour
+	ExpirationWarning      = 7 * 24 * time.Hour
+	ExpirationPeeringToken = 24 * time.Hour
+	ExpirationAllocation   = 10 * time.Minute
+	ExpirationPeeringStep  = 10 * time.Minute
 	RefreshCacheInterval   = 20 * time.Second
 	LiqoCheckInterval      = 20 * time.Second
+	CredentialsInterval    = 10 * time.Minute
+	HealthCheckInterval    = 30 * time.Second
 )
 
 // Configs flags.
@@ -36,6 +42,7 @@ var (
 	HTTPPort          string
 	GRPCPort          string
 	ResourceNodeLabel string
+	NodePoolLabel     string
 )
 
 // Customization flags.
@@ -53,3 +60,9 @@ var (
 	MinCount     int64
 	MaxCount     int64
 )
+
+// Capacity flags.
+var (
+	CapacityMode            string
+	CapacityChangeThreshold int64
+)
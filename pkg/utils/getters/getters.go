@@ -17,9 +17,12 @@ package getters
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/liqotech/liqo/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -74,6 +77,7 @@ func GetNodeIdentity(ctx context.Context, cl client.Client) *nodecorev1alpha1.No
 }
 
 // GetLocalProviders retrieves the list of local providers ip addresses from the KnownCluster CRs.
+// The providers are sorted by the round trip time measured towards their gateway, the unreachable ones last.
 func GetLocalProviders(ctx context.Context, cl client.Client) []string {
 	knownclusters := networkv1alpha1.KnownClusterList{}
 	result := []string{}
@@ -89,30 +93,69 @@ func GetLocalProviders(ctx context.Context, cl client.Client) []string {
 		return nil
 	}
 
+	sort.SliceStable(knownclusters.Items, func(i, j int) bool {
+		rttI, rttJ := knownclusters.Items[i].Status.GatewayRTT, knownclusters.Items[j].Status.GatewayRTT
+		if rttI == nil || rttJ == nil {
+			return rttI != nil
+		}
+		return rttI.Duration < rttJ.Duration
+	})
+
 	for i := range knownclusters.Items {
+		// The static peers whose gateway is not reachable are skipped until their next health check succeeds
+		if knownclusters.Items[i].Status.Phase == networkv1alpha1.KnownClusterUnreachable {
+			continue
+		}
 		result = append(result, knownclusters.Items[i].Spec.Address)
 	}
 
 	return result
 }
 
-// GetLiqoCredentials retrieves the Liqo credentials from the local cluster.
-func GetLiqoCredentials(ctx context.Context, cl client.Client, restConfig *rest.Config) (*nodecorev1alpha1.LiqoCredentials, error) {
+// GetLiqoClusterID retrieves the Liqo cluster ID of the local cluster.
+func GetLiqoClusterID(ctx context.Context, restConfig *rest.Config) (string, error) {
 	// Transform the client to a clientSet
 	kubeClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
 		klog.Errorf("Error creating the clientSet: %s", err)
-		return nil, err
+		return "", err
 	}
 
 	localClusterID, err := utils.GetClusterID(ctx, kubeClient, consts.LiqoNamespace)
 	if err != nil {
 		klog.Errorf("Error getting the local cluster ID: %s", err)
+		return "", err
+	}
+
+	return string(localClusterID), nil
+}
+
+// GetLiqoCredentials retrieves the Liqo credentials of the local cluster to be handed to the given remote cluster.
+// The credentials only grant the permissions the remote cluster needs to peer with the local one, and expire
+// at the earliest between the given contract expiration and the peering token lifetime.
+func GetLiqoCredentials(
+	ctx context.Context,
+	cl client.Client,
+	restConfig *rest.Config,
+	remoteClusterID string,
+	flavorType nodecorev1alpha1.FlavorTypeIdentifier,
+	contractExpiration time.Time) (*nodecorev1alpha1.LiqoCredentials, error) {
+	if remoteClusterID == "" {
+		return nil, fmt.Errorf("remote cluster ID not provided")
+	}
+
+	localClusterID, err := GetLiqoClusterID(ctx, restConfig)
+	if err != nil {
 		return nil, err
 	}
 
 	// Generate Local Kubeconfig for remote cluster
-	kubeconfig, err := virtualfabricmanager.CreateKubeconfigForPeering(ctx, cl, string(localClusterID))
+	expiration := time.Now().Add(flags.ExpirationPeeringToken)
+	if contractExpiration.Before(expiration) {
+		expiration = contractExpiration
+	}
+
+	kubeconfig, tokenExpiration, err := virtualfabricmanager.CreateKubeconfigForPeering(ctx, cl, remoteClusterID, flavorType, expiration)
 	if err != nil {
 		klog.Errorf("Error generating the kubeconfig: %s", err)
 		return nil, err
@@ -126,8 +169,9 @@ func GetLiqoCredentials(ctx context.Context, cl client.Client, restConfig *rest.
 	}
 
 	return &nodecorev1alpha1.LiqoCredentials{
-		ClusterID:  string(localClusterID),
-		Kubeconfig: kubeconfigEncoded,
+		ClusterID:      localClusterID,
+		Kubeconfig:     kubeconfigEncoded,
+		ExpirationTime: tokenExpiration.Format(time.RFC3339),
 	}, nil
 }
 
@@ -213,6 +257,7 @@ func GetBlueprint(ctx context.Context, c client.Client, flavorName string) (*nod
 }
 
 // GetAllocationByContractName retrieves the Allocation with the given contract name.
+// It returns a NotFound error if the contract has no Allocation.
 func GetAllocationByContractName(ctx context.Context, c client.Client, contractName string) (*nodecorev1alpha1.Allocation, error) {
 	// Get all the Allocations with spec.contract.name == contractName
 	allocations := &nodecorev1alpha1.AllocationList{}
@@ -228,5 +273,5 @@ func GetAllocationByContractName(ctx context.Context, c client.Client, contractN
 		}
 	}
 
-	return nil, fmt.Errorf("allocation with contract name %s not found", contractName)
+	return nil, apierrors.NewNotFound(nodecorev1alpha1.GroupVersion.WithResource("allocations").GroupResource(), "for contract "+contractName)
 }
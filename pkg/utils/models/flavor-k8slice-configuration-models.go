@@ -18,11 +18,12 @@ import "k8s.io/apimachinery/pkg/api/resource"
 
 // K8SliceConfiguration represents the configuration properties of a K8Slice Flavor.
 type K8SliceConfiguration struct {
-	CPU     resource.Quantity   `json:"cpu"`
-	Memory  resource.Quantity   `json:"memory"`
-	Pods    resource.Quantity   `json:"pods"`
-	Gpu     *GpuCharacteristics `json:"gpu,omitempty"`
-	Storage *resource.Quantity  `json:"storage,omitempty"`
+	CPU          resource.Quantity   `json:"cpu"`
+	Memory       resource.Quantity   `json:"memory"`
+	Pods         resource.Quantity   `json:"pods"`
+	Gpu          *GpuCharacteristics `json:"gpu,omitempty"`
+	Storage      *resource.Quantity  `json:"storage,omitempty"`
+	StorageClass string              `json:"storageClass,omitempty"`
 }
 
 // GetConfigurationType returns the type of the Configuration.
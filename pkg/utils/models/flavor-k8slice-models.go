@@ -53,16 +53,30 @@ type GpuCharacteristics struct {
 	HourlyRate            float64           `json:"hourly_rate"`
 	Provider              string            `json:"provider"`
 	PreEmptible           bool              `json:"pre_emptible"`
+	MIGProfiles           []MIGProfile      `json:"mig_profiles,omitempty"`
+}
+
+// MIGProfile represents the instances of a Multi-Instance GPU profile.
+type MIGProfile struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
 }
 
 // K8SliceCharacteristics represents the characteristics of a Kubernetes slice.
 type K8SliceCharacteristics struct {
-	Architecture string              `json:"architecture"`
-	CPU          resource.Quantity   `json:"cpu"`
-	Memory       resource.Quantity   `json:"memory"`
-	Pods         resource.Quantity   `json:"pods"`
-	Gpu          *GpuCharacteristics `json:"gpu,omitempty"`
-	Storage      *resource.Quantity  `json:"storage,omitempty"`
+	Architecture   string                 `json:"architecture"`
+	CPU            resource.Quantity      `json:"cpu"`
+	Memory         resource.Quantity      `json:"memory"`
+	Pods           resource.Quantity      `json:"pods"`
+	Gpu            *GpuCharacteristics    `json:"gpu,omitempty"`
+	Storage        *resource.Quantity     `json:"storage,omitempty"`
+	StorageClasses []StorageClassCapacity `json:"storageClasses,omitempty"`
+}
+
+// StorageClassCapacity represents the persistent storage offered through a StorageClass.
+type StorageClassCapacity struct {
+	Name     string            `json:"name"`
+	Capacity resource.Quantity `json:"capacity"`
 }
 
 // K8SliceProperties represents the properties of a Kubernetes slice.
@@ -108,6 +122,7 @@ type K8SliceSelector struct {
 	Memory       *ResourceQuantityFilter `scheme:"memory,omitempty"`
 	Pods         *ResourceQuantityFilter `scheme:"pods,omitempty"`
 	Storage      *ResourceQuantityFilter `scheme:"storage,omitempty"`
+	StorageClass *StringFilter           `scheme:"storageClass,omitempty"`
 	GPUFields    map[string]FilterData   `scheme:"gpuFields,omitempty"`
 }
 
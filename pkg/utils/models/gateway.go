@@ -25,6 +25,26 @@ type PurchaseRequest struct {
 	IngressTelemetryEndpoint *TelemetryServer `json:"ingressTelemetryEndpoint,omitempty"`
 }
 
+// CredentialsRotationRequest is the request model for rotating the Liqo credentials of a Contract.
+type CredentialsRotationRequest struct {
+	// Token is the current token of the buyer, used to prove it owns the credentials being rotated.
+	Token string `json:"token"`
+}
+
+// ContractRenewalRequest is the request model for renewing a Contract before it expires.
+type ContractRenewalRequest struct {
+	// Token is the current token of the buyer, used to prove it owns the Contract being renewed.
+	Token string `json:"token"`
+}
+
+// ContractAmendmentRequest is the request model for resizing the resources bought with a Contract.
+type ContractAmendmentRequest struct {
+	// Token is the current token of the buyer, used to prove it owns the Contract being amended.
+	Token string `json:"token"`
+	// Configuration is the new configuration of the resources bought with the Contract.
+	Configuration Configuration `json:"configuration"`
+}
+
 // ReserveRequest is the request model for reserving a Flavor.
 type ReserveRequest struct {
 	FlavorID      string         `json:"flavorID"`
@@ -25,6 +25,8 @@ type NodeInfo struct {
 	Architecture    string          `json:"architecture"`
 	OperatingSystem string          `json:"os"`
 	ResourceMetrics ResourceMetrics `json:"resources"`
+	// InvalidAnnotations lists the annotations of the node that have been ignored, as they are invalid or unsupported.
+	InvalidAnnotations []string `json:"invalidAnnotations,omitempty"`
 }
 
 // GPUMetrics represents GPU metrics.
@@ -45,11 +47,12 @@ type GPUMetrics struct {
 }
 
 type GPUSharingMetrics struct {
-	MultiInstance   bool   `json:"multi_instance,omitempty"`
-	Shared          bool   `json:"shared,omitempty"`
-	SharingStrategy string `json:"sharing_strategy,omitempty"`
-	Dedicated       bool   `json:"dedicated,omitempty"`
-	Interruptible   bool   `json:"interruptible,omitempty"`
+	MultiInstance   bool         `json:"multi_instance,omitempty"`
+	Shared          bool         `json:"shared,omitempty"`
+	SharingStrategy string       `json:"sharing_strategy,omitempty"`
+	Dedicated       bool         `json:"dedicated,omitempty"`
+	Interruptible   bool         `json:"interruptible,omitempty"`
+	MIGProfiles     []MIGProfile `json:"mig_profiles,omitempty"`
 }
 
 type GPUNetworkMetrics struct {
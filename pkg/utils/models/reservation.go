@@ -37,6 +37,8 @@ type Transaction struct {
 	Buyer          NodeIdentity   `json:"buyer"`
 	ClusterID      string         `json:"clusterID"`
 	ExpirationTime string         `json:"expirationTime"`
+	// Price is the price of the partition reserved, at which the Contract is bought
+	Price *Price `json:"price,omitempty"`
 }
 
 // TelemetryServer represents a TelemetryServer object with its characteristics.
@@ -63,6 +65,7 @@ type Contract struct {
 
 // LiqoCredentials contains the credentials of a Liqo cluster to establish a peering.
 type LiqoCredentials struct {
-	ClusterID  string `json:"clusterID"`
-	Kubeconfig string `json:"kubeconfig"`
+	ClusterID      string `json:"clusterID"`
+	Kubeconfig     string `json:"kubeconfig"`
+	ExpirationTime string `json:"expirationTime,omitempty"`
 }
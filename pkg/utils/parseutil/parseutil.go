@@ -47,6 +47,7 @@ func ParseFlavorSelector(selector *nodecorev1alpha1.Selector) (models.Selector,
 				Memory:       nil,
 				Pods:         nil,
 				Storage:      nil,
+				StorageClass: nil,
 				GPUFields:    nil,
 			}, nil
 		}
@@ -359,7 +360,7 @@ func ParseStringFilter(filter *nodecorev1alpha1.StringFilter) (models.StringFilt
 
 // parseK8SliceFilters parses a K8SliceSelector into a K8SliceSelector model.
 func parseK8SliceFilters(k8sSelector *nodecorev1alpha1.K8SliceSelector) (*models.K8SliceSelector, error) {
-	var architectureFilterModel models.StringFilter
+	var architectureFilterModel, storageClassFilterModel models.StringFilter
 	var cpuFilterModel, memoryFilterModel, podsFilterModel, storageFilterModel models.ResourceQuantityFilter
 
 	// Parse the Architecture filter
@@ -412,6 +413,16 @@ func parseK8SliceFilters(k8sSelector *nodecorev1alpha1.K8SliceSelector) (*models
 		klog.Info("Storage filter is nil")
 	}
 
+	// Parse the StorageClass filter
+	klog.Info("Parsing the StorageClass filter")
+	storageClassFilterModel, err = ParseStringFilter(k8sSelector.StorageClassFilter)
+	if err != nil {
+		return nil, err
+	}
+	if storageClassFilterModel.Data == nil {
+		klog.Info("StorageClass filter is nil")
+	}
+
 	// Generate the model for the K8Slice selector
 	k8SliceSelector := models.K8SliceSelector{
 		Architecture: func() *models.StringFilter {
@@ -444,6 +455,12 @@ func parseK8SliceFilters(k8sSelector *nodecorev1alpha1.K8SliceSelector) (*models
 			}
 			return nil
 		}(),
+		StorageClass: func() *models.StringFilter {
+			if k8sSelector.StorageClassFilter != nil {
+				return &storageClassFilterModel
+			}
+			return nil
+		}(),
 		GPUFields: func() map[string]models.FilterData {
 			gpuFilters := map[string]models.FilterData{}
 
@@ -528,7 +545,8 @@ func ParseConfiguration(configuration *nodecorev1alpha1.Configuration, flavor *n
 				}
 				return nil
 			}(),
-			Storage: configuration.Storage,
+			Storage:      configuration.Storage,
+			StorageClass: configuration.StorageClass,
 		}
 		// Marshal the K8Slice configuration to JSON
 		configurationData, err := json.Marshal(k8sliceConfigurationJSON)
@@ -721,7 +739,8 @@ func ParseFlavor(flavor *nodecorev1alpha1.Flavor) *models.Flavor {
 					}
 					return nil
 				}(),
-				Storage: flavorTypeStruct.Characteristics.Storage,
+				Storage:        flavorTypeStruct.Characteristics.Storage,
+				StorageClasses: ToStorageClassCapacities(flavorTypeStruct.Characteristics.StorageClasses),
 			},
 			Properties: models.K8SliceProperties{
 				Latency:           flavorTypeStruct.Properties.Latency,
@@ -903,8 +922,9 @@ func ParseContract(contract *reservationv1alpha1.Contract) *models.Contract {
 		}(),
 		Seller: ParseNodeIdentity(contract.Spec.Seller),
 		PeeringTargetCredentials: models.LiqoCredentials{
-			ClusterID:  contract.Spec.PeeringTargetCredentials.ClusterID,
-			Kubeconfig: contract.Spec.PeeringTargetCredentials.Kubeconfig,
+			ClusterID:      contract.Spec.PeeringTargetCredentials.ClusterID,
+			Kubeconfig:     contract.Spec.PeeringTargetCredentials.Kubeconfig,
+			ExpirationTime: contract.Spec.PeeringTargetCredentials.ExpirationTime,
 		},
 		ExpirationTime:           contract.Spec.ExpirationTime,
 		ExtraInformation:         contract.Spec.ExtraInformation,
@@ -52,5 +52,15 @@ func ToGpuCharacteristics(input v1alpha1.GPU) *models.GpuCharacteristics {
 		HourlyRate:            input.HourlyRate,
 		Provider:              input.Provider,
 		PreEmptible:           input.PreEmptible,
+		MIGProfiles: func() []models.MIGProfile {
+			if input.MIGProfiles == nil {
+				return nil
+			}
+			profiles := make([]models.MIGProfile, 0, len(input.MIGProfiles))
+			for _, profile := range input.MIGProfiles {
+				profiles = append(profiles, models.MIGProfile{Name: profile.Name, Count: profile.Count})
+			}
+			return profiles
+		}(),
 	}
 }
@@ -52,6 +52,16 @@ func ToNodeCoreGPU(in models.GpuCharacteristics) *nodecorev1alpha1.GPU {
 		HourlyRate:            in.HourlyRate,
 		Provider:              in.Provider,
 		PreEmptible:           in.PreEmptible,
+		MIGProfiles: func() []nodecorev1alpha1.MIGProfile {
+			if in.MIGProfiles == nil {
+				return nil
+			}
+			profiles := make([]nodecorev1alpha1.MIGProfile, 0, len(in.MIGProfiles))
+			for _, profile := range in.MIGProfiles {
+				profiles = append(profiles, nodecorev1alpha1.MIGProfile{Name: profile.Name, Count: profile.Count})
+			}
+			return profiles
+		}(),
 	}
 
 }
@@ -0,0 +1,44 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parseutil
+
+import (
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/models"
+)
+
+// ToNodeCoreStorageClasses converts the StorageClasses of a K8Slice model to the ones of a K8Slice Flavor.
+func ToNodeCoreStorageClasses(in []models.StorageClassCapacity) []nodecorev1alpha1.StorageClassCapacity {
+	if in == nil {
+		return nil
+	}
+	classes := make([]nodecorev1alpha1.StorageClassCapacity, 0, len(in))
+	for _, class := range in {
+		classes = append(classes, nodecorev1alpha1.StorageClassCapacity{Name: class.Name, Capacity: class.Capacity})
+	}
+	return classes
+}
+
+// ToStorageClassCapacities converts the StorageClasses of a K8Slice Flavor to the ones of a K8Slice model.
+func ToStorageClassCapacities(in []nodecorev1alpha1.StorageClassCapacity) []models.StorageClassCapacity {
+	if in == nil {
+		return nil
+	}
+	classes := make([]models.StorageClassCapacity, 0, len(in))
+	for _, class := range in {
+		classes = append(classes, models.StorageClassCapacity{Name: class.Name, Capacity: class.Capacity})
+	}
+	return classes
+}
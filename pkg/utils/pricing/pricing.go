@@ -0,0 +1,187 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+)
+
+// RateCardKey is the key of the rate card in its ConfigMap.
+const RateCardKey = "rateCard"
+
+// gibibyte is the unit of the memory and storage rates.
+const gibibyte = 1 << 30
+
+// periodHours maps the supported periods to their length in hours, to convert the hourly rates of the GPUs.
+var periodHours = map[string]float64{
+	"hour":    1,
+	"hourly":  1,
+	"day":     24,
+	"daily":   24,
+	"week":    7 * 24,
+	"weekly":  7 * 24,
+	"month":   730,
+	"monthly": 730,
+	"year":    8760,
+	"yearly":  8760,
+}
+
+// GPURates holds the rates of the GPUs, per GPU.
+type GPURates struct {
+	// Default is the rate of the GPUs whose model is not listed and that have no hourly rate.
+	Default float64 `json:"default,omitempty"`
+	// Models maps the GPU models to their rate.
+	Models map[string]float64 `json:"models,omitempty"`
+}
+
+// RateCard holds the rates used to price the K8Slice Flavors and their partitions, for the given currency and period.
+type RateCard struct {
+	// Currency of the prices.
+	Currency string `json:"currency"`
+	// Period of the prices (e.g., hourly or monthly).
+	Period string `json:"period"`
+	// Base is the flat amount charged for every Flavor.
+	Base float64 `json:"base,omitempty"`
+	// CPU is the rate per CPU core.
+	CPU float64 `json:"cpu,omitempty"`
+	// Memory is the rate per GiB of memory.
+	Memory float64 `json:"memory,omitempty"`
+	// Storage is the rate per GiB of storage.
+	Storage float64 `json:"storage,omitempty"`
+	// GPU holds the rates of the GPUs.
+	GPU GPURates `json:"gpu,omitempty"`
+	// Regions maps the regions (topology.kubernetes.io/region) to the multiplier applied to their prices.
+	Regions map[string]float64 `json:"regions,omitempty"`
+}
+
+// Validate checks that the rate card has a currency and a period, and that its rates and multipliers are valid.
+func (c *RateCard) Validate() error {
+	if c.Currency == "" || c.Period == "" {
+		return fmt.Errorf("currency and period are required")
+	}
+	rates := map[string]float64{"base": c.Base, "cpu": c.CPU, "memory": c.Memory, "storage": c.Storage, "gpu default": c.GPU.Default}
+	for model, rate := range c.GPU.Models {
+		rates["gpu "+model] = rate
+	}
+	for name, rate := range rates {
+		if rate < 0 {
+			return fmt.Errorf("%s rate %g must not be negative", name, rate)
+		}
+	}
+	for region, multiplier := range c.Regions {
+		if multiplier <= 0 {
+			return fmt.Errorf("multiplier %g of region %s must be positive", multiplier, region)
+		}
+	}
+	return nil
+}
+
+// GetRateCard retrieves the rate card from its ConfigMap. It returns nil if no rate card is configured.
+func GetRateCard(ctx context.Context, cl client.Client) (*RateCard, error) {
+	cm := &corev1.ConfigMap{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: consts.RateCardConfigMapName, Namespace: flags.FluidosNamespace}, cm); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	raw, ok := cm.Data[RateCardKey]
+	if !ok {
+		return nil, nil
+	}
+
+	rateCard := &RateCard{}
+	if err := yaml.Unmarshal([]byte(raw), rateCard); err != nil {
+		return nil, fmt.Errorf("invalid rate card: %w", err)
+	}
+	if err := rateCard.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate card: %w", err)
+	}
+	return rateCard, nil
+}
+
+// Price returns the price of the given K8Slice characteristics in the given region.
+func (c *RateCard) Price(characteristics *nodecorev1alpha1.K8SliceCharacteristics, region string) nodecorev1alpha1.Price {
+	amount := c.Base +
+		c.CPU*characteristics.CPU.AsApproximateFloat64() +
+		c.Memory*characteristics.Memory.AsApproximateFloat64()/gibibyte
+	if characteristics.Storage != nil {
+		amount += c.Storage * characteristics.Storage.AsApproximateFloat64() / gibibyte
+	}
+	if characteristics.Gpu != nil && characteristics.Gpu.Count > 0 {
+		amount += c.gpuRate(characteristics.Gpu) * float64(characteristics.Gpu.Count)
+	}
+	if multiplier, ok := c.Regions[region]; ok {
+		amount *= multiplier
+	}
+
+	return nodecorev1alpha1.Price{
+		Amount:   strconv.FormatFloat(amount, 'f', 2, 64),
+		Currency: c.Currency,
+		Period:   c.Period,
+	}
+}
+
+// PriceConfiguration returns the price of a partition of a K8Slice Flavor in the given region.
+// The GPUs of the partition are priced as the ones of the Flavor.
+func (c *RateCard) PriceConfiguration(k8Slice *nodecorev1alpha1.K8Slice, configuration *nodecorev1alpha1.K8SliceConfiguration,
+	region string) nodecorev1alpha1.Price {
+	characteristics := &nodecorev1alpha1.K8SliceCharacteristics{
+		CPU:     configuration.CPU,
+		Memory:  configuration.Memory,
+		Pods:    configuration.Pods,
+		Storage: configuration.Storage,
+	}
+	if configuration.Gpu != nil && k8Slice.Characteristics.Gpu != nil {
+		gpu := k8Slice.Characteristics.Gpu.DeepCopy()
+		gpu.Count = configuration.Gpu.Count
+		characteristics.Gpu = gpu
+	}
+	return c.Price(characteristics, region)
+}
+
+// gpuRate returns the rate of a GPU: the one of its model if listed, else its own hourly rate, else the default one.
+func (c *RateCard) gpuRate(gpu *nodecorev1alpha1.GPU) float64 {
+	if rate, ok := c.GPU.Models[gpu.Model]; ok {
+		return rate
+	}
+	if hours, ok := periodHours[c.Period]; ok && gpu.HourlyRate > 0 {
+		return gpu.HourlyRate * hours
+	}
+	return c.GPU.Default
+}
+
+// ScalePrice scales the price of a whole K8Slice Flavor, or of a partition of it, to another partition
+// by the mean of their CPU and memory ratios. The price is kept unchanged if it cannot be scaled.
+func ScalePrice(price nodecorev1alpha1.Price, whole, part *nodecorev1alpha1.K8SliceCharacteristics) nodecorev1alpha1.Price {
+	amount, err := strconv.ParseFloat(price.Amount, 64)
+	if err != nil || whole.CPU.Sign() <= 0 || whole.Memory.Sign() <= 0 {
+		return price
+	}
+
+	cpuRatio := float64(part.CPU.MilliValue()) / float64(whole.CPU.MilliValue())
+	memoryRatio := float64(part.Memory.Value()) / float64(whole.Memory.Value())
+	price.Amount = strconv.FormatFloat(amount*(cpuRatio+memoryRatio)/2, 'f', 2, 64)
+
+	return price
+}
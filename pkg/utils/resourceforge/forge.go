@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"regexp"
 	"strings"
 	"time"
 
@@ -325,8 +326,8 @@ func ForgeFlavorFromRef(f *nodecorev1alpha1.Flavor, newFlavorType *nodecorev1alp
 
 // FORGER FUNCTIONS FROM OBJECTS
 
-// ForgeTransactionObj creates a new Transaction object.
-func ForgeTransactionObj(id string, req *models.ReserveRequest) *models.Transaction {
+// ForgeTransactionObj creates a new Transaction object, reserving the Flavor at the given price.
+func ForgeTransactionObj(id string, req *models.ReserveRequest, price nodecorev1alpha1.Price) *models.Transaction {
 	return &models.Transaction{
 		TransactionID: id,
 		Buyer:         req.Buyer,
@@ -339,6 +340,11 @@ func ForgeTransactionObj(id string, req *models.ReserveRequest) *models.Transact
 			return nil
 		}(),
 		ExpirationTime: tools.GetExpirationTime(1, 0, 0),
+		Price: &models.Price{
+			Amount:   price.Amount,
+			Currency: price.Currency,
+			Period:   price.Period,
+		},
 	}
 }
 
@@ -351,8 +357,9 @@ func ForgeContractObj(contract *reservationv1alpha1.Contract) models.Contract {
 		BuyerClusterID: contract.Spec.BuyerClusterID,
 		Seller:         parseutil.ParseNodeIdentity(contract.Spec.Seller),
 		PeeringTargetCredentials: models.LiqoCredentials{
-			ClusterID:  contract.Spec.PeeringTargetCredentials.ClusterID,
-			Kubeconfig: contract.Spec.PeeringTargetCredentials.Kubeconfig,
+			ClusterID:      contract.Spec.PeeringTargetCredentials.ClusterID,
+			Kubeconfig:     contract.Spec.PeeringTargetCredentials.Kubeconfig,
+			ExpirationTime: contract.Spec.PeeringTargetCredentials.ExpirationTime,
 		},
 		Configuration: func() *models.Configuration {
 			if contract.Spec.Configuration != nil {
@@ -411,8 +418,9 @@ func ForgeContractFromObj(contract *models.Contract) (*reservationv1alpha1.Contr
 			BuyerClusterID: contract.BuyerClusterID,
 			Seller:         *ForgeNodeIdentitiesFromObj(&contract.Seller),
 			PeeringTargetCredentials: nodecorev1alpha1.LiqoCredentials{
-				ClusterID:  contract.PeeringTargetCredentials.ClusterID,
-				Kubeconfig: contract.PeeringTargetCredentials.Kubeconfig,
+				ClusterID:      contract.PeeringTargetCredentials.ClusterID,
+				Kubeconfig:     contract.PeeringTargetCredentials.Kubeconfig,
+				ExpirationTime: contract.PeeringTargetCredentials.ExpirationTime,
 			},
 			TransactionID: contract.TransactionID,
 			Configuration: func() *nodecorev1alpha1.Configuration {
@@ -479,6 +487,16 @@ func ForgeTransactionFromObj(transaction *models.Transaction) *reservationv1alph
 				}
 				return nil
 			}(),
+			Price: func() *nodecorev1alpha1.Price {
+				if transaction.Price != nil {
+					return &nodecorev1alpha1.Price{
+						Amount:   transaction.Price.Amount,
+						Currency: transaction.Price.Currency,
+						Period:   transaction.Price.Period,
+					}
+				}
+				return nil
+			}(),
 		},
 	}
 }
@@ -505,7 +523,8 @@ func ForgeConfigurationFromObj(configuration models.Configuration) (*nodecorev1a
 
 				return nil
 			}(),
-			Storage: configurationStruct.Storage,
+			Storage:      configurationStruct.Storage,
+			StorageClass: configurationStruct.StorageClass,
 		}
 
 		// Marshal the K8Slice configuration to JSON
@@ -579,7 +598,8 @@ func ForgeConfigurationObj(configuration *nodecorev1alpha1.Configuration) (*mode
 				}
 				return nil
 			}(),
-			Storage: configurationStruct.Storage,
+			Storage:      configurationStruct.Storage,
+			StorageClass: configurationStruct.StorageClass,
 		}
 
 		// Marshal the K8Slice configuration to JSON
@@ -785,11 +805,12 @@ func ForgeFlavorFromObj(flavor *models.Flavor) (*nodecorev1alpha1.Flavor, error)
 		}
 		flavorTypeData := nodecorev1alpha1.K8Slice{
 			Characteristics: nodecorev1alpha1.K8SliceCharacteristics{
-				Architecture: flavorTypeDataModel.Characteristics.Architecture,
-				CPU:          flavorTypeDataModel.Characteristics.CPU,
-				Memory:       flavorTypeDataModel.Characteristics.Memory,
-				Pods:         flavorTypeDataModel.Characteristics.Pods,
-				Storage:      flavorTypeDataModel.Characteristics.Storage,
+				Architecture:   flavorTypeDataModel.Characteristics.Architecture,
+				CPU:            flavorTypeDataModel.Characteristics.CPU,
+				Memory:         flavorTypeDataModel.Characteristics.Memory,
+				Pods:           flavorTypeDataModel.Characteristics.Pods,
+				Storage:        flavorTypeDataModel.Characteristics.Storage,
+				StorageClasses: parseutil.ToNodeCoreStorageClasses(flavorTypeDataModel.Characteristics.StorageClasses),
 				Gpu: func() *nodecorev1alpha1.GPU {
 					if flavorTypeDataModel.Characteristics.Gpu != nil {
 						return parseutil.ToNodeCoreGPU(*flavorTypeDataModel.Characteristics.Gpu)
@@ -1078,14 +1099,53 @@ func ForgeK8SliceConfiguration(selector nodecorev1alpha1.K8SliceSelector, flavor
 		}
 	}
 
+	var storageClass string
+	if selector.StorageClassFilter != nil {
+		// Parse StorageClass filter
+		klog.Info("Parsing StorageClass filter")
+		storageClass = forgeStorageClass(selector.StorageClassFilter, flavor, storage)
+		if storageClass == "" {
+			klog.Errorf("No StorageClass of the Flavor matches the StorageClass filter")
+			return nil
+		}
+	}
+
 	// Compose configuration based on values gathered from filters
 	return &nodecorev1alpha1.K8SliceConfiguration{
-		CPU:     cpu,
-		Memory:  memory,
-		Pods:    pods,
-		Gpu:     gpu,
-		Storage: storage,
+		CPU:          cpu,
+		Memory:       memory,
+		Pods:         pods,
+		Gpu:          gpu,
+		Storage:      storage,
+		StorageClass: storageClass,
+	}
+}
+
+// forgeStorageClass returns the first StorageClass of the K8Slice Flavor matching the StorageClass filter
+// and offering at least the given storage, if any.
+func forgeStorageClass(filter *nodecorev1alpha1.StringFilter, flavor *nodecorev1alpha1.K8Slice, storage *resource.Quantity) string {
+	filterType, filterData, err := nodecorev1alpha1.ParseStringFilter(filter)
+	if err != nil {
+		klog.Errorf("Error when parsing StorageClass filter: %s", err)
+		return ""
+	}
+
+	for _, class := range flavor.Characteristics.StorageClasses {
+		if storage != nil && class.Capacity.Cmp(*storage) < 0 {
+			continue
+		}
+		switch filterType {
+		case nodecorev1alpha1.TypeMatchFilter:
+			if class.Name == filterData.(nodecorev1alpha1.StringMatchSelector).Value {
+				return class.Name
+			}
+		case nodecorev1alpha1.TypeRangeFilter:
+			if matched, _ := regexp.MatchString(filterData.(nodecorev1alpha1.StringRangeSelector).Regex, class.Name); matched {
+				return class.Name
+			}
+		}
 	}
+	return ""
 }
 
 // ForgeAllocation creates an Allocation from a Contract.
@@ -1129,16 +1189,18 @@ func ForgeDefaultServiceConfiguration(serviceFlavor *nodecorev1alpha1.ServiceFla
 // ForgeLiqoCredentialsObj creates a LiqoCredentials object from a LiqoCredentials CR.
 func ForgeLiqoCredentialsObj(liqoCredentials *nodecorev1alpha1.LiqoCredentials) (*models.LiqoCredentials, error) {
 	return &models.LiqoCredentials{
-		ClusterID:  liqoCredentials.ClusterID,
-		Kubeconfig: liqoCredentials.Kubeconfig,
+		ClusterID:      liqoCredentials.ClusterID,
+		Kubeconfig:     liqoCredentials.Kubeconfig,
+		ExpirationTime: liqoCredentials.ExpirationTime,
 	}, nil
 }
 
 // ForgeLiqoCredentialsFromObj creates a LiqoCredentials CR from a LiqoCredentials object.
 func ForgeLiqoCredentialsFromObj(liqoCredentials *models.LiqoCredentials) (*nodecorev1alpha1.LiqoCredentials, error) {
 	return &nodecorev1alpha1.LiqoCredentials{
-		ClusterID:  liqoCredentials.ClusterID,
-		Kubeconfig: liqoCredentials.Kubeconfig,
+		ClusterID:      liqoCredentials.ClusterID,
+		Kubeconfig:     liqoCredentials.Kubeconfig,
+		ExpirationTime: liqoCredentials.ExpirationTime,
 	}, nil
 }
 
@@ -1383,19 +1445,25 @@ func ForgeSecretForService(contract *reservationv1alpha1.Contract,
 	return secretCredentials, nil
 }
 
-// ForgeKnownCluster creates a KnownCluster from cluster ID and IP address.
-func ForgeKnownCluster(id, address string) *networkv1alpha1.KnownCluster {
+// ForgeKnownCluster creates a KnownCluster from the NodeIdentity of the announcing node and the source it has been discovered from.
+func ForgeKnownCluster(nodeIdentity *nodecorev1alpha1.NodeIdentity, source *networkv1alpha1.KnownClusterSource) *networkv1alpha1.KnownCluster {
+	now := tools.GetTimeNow()
 	return &networkv1alpha1.KnownCluster{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      namings.ForgeKnownClusterName(id),
+			Name:      namings.ForgeKnownClusterName(nodeIdentity.NodeID),
 			Namespace: flags.FluidosNamespace,
 		},
 		Spec: networkv1alpha1.KnownClusterSpec{
-			Address: address,
+			Address: nodeIdentity.IP,
+			NodeID:  nodeIdentity.NodeID,
+			Domain:  nodeIdentity.Domain,
+			Source:  source,
 		},
 		Status: networkv1alpha1.KnownClusterStatus{
 			ExpirationTime: tools.GetExpirationTime(0, 0, 10),
-			LastUpdateTime: tools.GetTimeNow(),
+			LastUpdateTime: now,
+			FirstSeenTime:  now,
+			LastSeenTime:   now,
 		},
 	}
 }
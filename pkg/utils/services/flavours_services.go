@@ -16,12 +16,15 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
 	"github.com/fluidos-project/node/pkg/utils/flags"
 )
 
@@ -84,3 +87,38 @@ func GetFlavorByID(flavorID string, cl client.Client) (*nodecorev1alpha1.Flavor,
 
 	return flavor, nil
 }
+
+// FlavorRoot returns the name of the Flavor from which the given Flavor has been partitioned.
+func FlavorRoot(flavor *nodecorev1alpha1.Flavor) string {
+	if root, ok := flavor.GetLabels()[consts.FluidosFlavorRootLabel]; ok && root != "" {
+		return root
+	}
+	return flavor.Name
+}
+
+// RemainderLabels returns the labels of a remainder partitioned from the given Flavor:
+// the labels of the Flavor, with the root label pointing to the Flavor it has been partitioned from.
+func RemainderLabels(flavor *nodecorev1alpha1.Flavor) map[string]string {
+	labels := make(map[string]string, len(flavor.Labels)+1)
+	for k, v := range flavor.Labels {
+		labels[k] = v
+	}
+	labels[consts.FluidosFlavorRootLabel] = FlavorRoot(flavor)
+	return labels
+}
+
+// SetK8SliceCharacteristics replaces the characteristics of a K8Slice Flavor.
+func SetK8SliceCharacteristics(flavor *nodecorev1alpha1.Flavor, characteristics *nodecorev1alpha1.K8SliceCharacteristics) error {
+	k8Slice, err := nodecorev1alpha1.ParseK8SliceFlavor(flavor.Spec.FlavorType)
+	if err != nil {
+		return err
+	}
+	k8Slice.Characteristics = *characteristics
+
+	k8SliceBytes, err := json.Marshal(k8Slice)
+	if err != nil {
+		return err
+	}
+	flavor.Spec.FlavorType.TypeData = runtime.RawExtension{Raw: k8SliceBytes}
+	return nil
+}
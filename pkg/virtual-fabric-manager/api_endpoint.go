@@ -0,0 +1,160 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/fluidos-project/node/pkg/utils/consts"
+	"github.com/fluidos-project/node/pkg/utils/flags"
+)
+
+const (
+	// apiServerURLKey is the key of the node identity ConfigMap holding the public URL of the API server.
+	apiServerURLKey = "apiServerURL"
+	// apiServerCAKey is the key of the node identity ConfigMap holding the PEM CA bundle of the public API server URL.
+	apiServerCAKey = "apiServerCA"
+	// defaultAPIServerPort is the port of the API server assumed when it cannot be detected.
+	defaultAPIServerPort = 6443
+	// apiServerProbeTimeout is the timeout of the reachability check of the API server endpoint.
+	apiServerProbeTimeout = 5 * time.Second
+)
+
+// apiEndpoint is the endpoint of the API server written in the kubeconfigs handed to consumers.
+type apiEndpoint struct {
+	URL string
+	CA  []byte
+}
+
+// getAPIEndpoint returns the public endpoint of the API server of the local cluster.
+// The URL and CA bundle set in the node identity ConfigMap take precedence, then the endpoint is detected
+// from the control plane nodes and the CA bundle of the cluster is used.
+func getAPIEndpoint(ctx context.Context, cl client.Client) (*apiEndpoint, error) {
+	cm := &corev1.ConfigMap{}
+	err := cl.Get(ctx, client.ObjectKey{Name: consts.NodeIdentityConfigMapName, Namespace: flags.FluidosNamespace}, cm)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Error getting the node identity ConfigMap: %s", err)
+		return nil, err
+	}
+
+	endpoint := &apiEndpoint{
+		URL: cm.Data[apiServerURLKey],
+		CA:  []byte(cm.Data[apiServerCAKey]),
+	}
+
+	if endpoint.URL == "" {
+		if endpoint.URL, err = getControlPlaneURL(ctx, cl); err != nil {
+			klog.Error(err)
+			return nil, err
+		}
+		klog.InfofDepth(1, "API server URL not configured, detected %s", endpoint.URL)
+	}
+
+	if len(endpoint.CA) == 0 {
+		if endpoint.CA, err = getClusterCA(ctx, cl); err != nil {
+			return nil, err
+		}
+	}
+
+	return endpoint, nil
+}
+
+// getControlPlaneURL detects the URL of the API server from the addresses of the control plane nodes,
+// preferring the external ones. The port is the one of the kubernetes Service endpoints.
+func getControlPlaneURL(ctx context.Context, cl client.Client) (string, error) {
+	port := int32(defaultAPIServerPort)
+
+	ep := &corev1.Endpoints{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: "kubernetes", Namespace: metav1.NamespaceDefault}, ep); err != nil {
+		klog.Errorf("Error getting the kubernetes endpoints: %s", err)
+	} else if len(ep.Subsets) > 0 && len(ep.Subsets[0].Ports) > 0 {
+		port = ep.Subsets[0].Ports[0].Port
+	}
+
+	// Get control plane node list
+	nodeList := &corev1.NodeList{}
+	if err := cl.List(ctx, nodeList); err != nil {
+		klog.Error(err)
+		return "", err
+	}
+
+	for _, addressType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for i := range nodeList.Items {
+			node := &nodeList.Items[i]
+
+			_, existsControl := node.Labels["node-role.kubernetes.io/control-plane"]
+			_, existsMaster := node.Labels["node-role.kubernetes.io/master"]
+			if !existsControl && !existsMaster {
+				continue
+			}
+
+			for _, address := range node.Status.Addresses {
+				if address.Type == addressType {
+					return "https://" + net.JoinHostPort(address.Address, strconv.Itoa(int(port))), nil
+				}
+			}
+		}
+	}
+
+	// Managed clusters do not expose their control plane nodes: fall back to the kubernetes Service endpoints
+	if len(ep.Subsets) > 0 && len(ep.Subsets[0].Addresses) > 0 {
+		return "https://" + net.JoinHostPort(ep.Subsets[0].Addresses[0].IP, strconv.Itoa(int(port))), nil
+	}
+
+	return "", fmt.Errorf("unable to retrieve the control plane URL")
+}
+
+// checkAPIEndpoint checks that the API server answers on the endpoint, with a certificate signed by its CA bundle.
+// Any HTTP response is accepted, as the probe is not authenticated. The probe is sent from the local cluster:
+// it catches a wrong URL or CA bundle, not the firewalls or NAT between the consumer and the endpoint.
+func checkAPIEndpoint(ctx context.Context, endpoint *apiEndpoint) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(endpoint.CA) {
+		return fmt.Errorf("invalid CA bundle for API server %s", endpoint.URL)
+	}
+
+	httpClient := &http.Client{
+		Timeout: apiServerProbeTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.URL+"/version", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("invalid API server URL %s: %w", endpoint.URL, err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		klog.Errorf("API server %s is not reachable: %s", endpoint.URL, err)
+		return fmt.Errorf("API server %s is not reachable: %w", endpoint.URL, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+	"fmt"
+
+	authv1beta1 "github.com/liqotech/liqo/apis/authentication/v1beta1"
+	liqov1beta1 "github.com/liqotech/liqo/apis/core/v1beta1"
+	networkingv1beta1 "github.com/liqotech/liqo/apis/networking/v1beta1"
+	offloadingv1beta1 "github.com/liqotech/liqo/apis/offloading/v1beta1"
+	fcutils "github.com/liqotech/liqo/pkg/utils/foreigncluster"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons reported by CheckPeeringHealth.
+const (
+	HealthForeignClusterNotReady   = "ForeignClusterNotReady"
+	HealthNetworkNotConnected      = "NetworkNotConnected"
+	HealthResourceSliceNotAccepted = "ResourceSliceNotAccepted"
+	HealthVirtualNodeNotReady      = "VirtualNodeNotReady"
+)
+
+// PeeringHealthOptions selects the signals evaluated by CheckPeeringHealth.
+type PeeringHealthOptions struct {
+	// ResourceSlice is the name of the ResourceSlice backing the peering, in the tenant namespace of the remote cluster.
+	// The ResourceSlice is not checked if empty.
+	ResourceSlice string
+	// VirtualNode enables the check of the VirtualNode named after the ResourceSlice, and of the node it creates.
+	// It only applies to the consumer side of the peering.
+	VirtualNode bool
+}
+
+// CheckPeeringHealth evaluates the health of the peering with the given remote cluster.
+// It returns the reasons why the peering is impaired, in the form "Reason: details", or none if it is healthy.
+func CheckPeeringHealth(ctx context.Context, cl client.Client, remoteClusterID string, opts PeeringHealthOptions) ([]string, error) {
+	var reasons []string
+	namespace := TenantNamespaceName(remoteClusterID)
+
+	fc, err := fcutils.GetForeignClusterByID(ctx, cl, liqov1beta1.ClusterID(remoteClusterID))
+	switch {
+	case apierrors.IsNotFound(err):
+		reasons = append(reasons, HealthForeignClusterNotReady+": ForeignCluster not found")
+	case err != nil:
+		klog.Errorf("Error when getting ForeignCluster %s: %s", remoteClusterID, err)
+		return nil, err
+	case !fcutils.IsNetworkingEstablished(fc) || !fcutils.IsAuthenticationModuleEnabled(fc) || !fcutils.IsOffloadingModuleEnabled(fc):
+		reasons = append(reasons, HealthForeignClusterNotReady+": networking, authentication or offloading not ready")
+	}
+
+	connections := &networkingv1beta1.ConnectionList{}
+	if err := cl.List(ctx, connections, client.InNamespace(namespace)); err != nil {
+		klog.Errorf("Error when listing Connections in namespace %s: %s", namespace, err)
+		return nil, err
+	}
+	switch {
+	case len(connections.Items) == 0:
+		reasons = append(reasons, HealthNetworkNotConnected+": Connection not found")
+	case connections.Items[0].Status.Value != networkingv1beta1.Connected:
+		reasons = append(reasons, fmt.Sprintf("%s: Connection is %q", HealthNetworkNotConnected, connections.Items[0].Status.Value))
+	}
+
+	if opts.ResourceSlice == "" {
+		return reasons, nil
+	}
+
+	rs := &authv1beta1.ResourceSlice{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: opts.ResourceSlice, Namespace: namespace}, rs); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("Error when getting ResourceSlice %s: %s", opts.ResourceSlice, err)
+			return nil, err
+		}
+		reasons = append(reasons, HealthResourceSliceNotAccepted+": ResourceSlice not found")
+	} else if condition := resourceSliceCondition(rs, authv1beta1.ResourceSliceConditionTypeResources); condition == nil ||
+		condition.Status != authv1beta1.ResourceSliceConditionAccepted {
+		reasons = append(reasons, HealthResourceSliceNotAccepted+": resources not accepted by the provider")
+	}
+
+	if !opts.VirtualNode {
+		return reasons, nil
+	}
+
+	if reason, err := checkVirtualNode(ctx, cl, opts.ResourceSlice, namespace); err != nil {
+		return nil, err
+	} else if reason != "" {
+		reasons = append(reasons, HealthVirtualNodeNotReady+": "+reason)
+	}
+
+	return reasons, nil
+}
+
+// resourceSliceCondition returns the condition of the given type of a ResourceSlice, if any.
+func resourceSliceCondition(rs *authv1beta1.ResourceSlice, conditionType authv1beta1.ResourceSliceConditionType) *authv1beta1.ResourceSliceCondition {
+	for i := range rs.Status.Conditions {
+		if rs.Status.Conditions[i].Type == conditionType {
+			return &rs.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// checkVirtualNode checks that a VirtualNode is running and that the node it creates is Ready.
+// It returns why the VirtualNode is not ready, or an empty string if it is.
+func checkVirtualNode(ctx context.Context, cl client.Client, name, namespace string) (string, error) {
+	virtualNode := &offloadingv1beta1.VirtualNode{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, virtualNode); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "VirtualNode not found", nil
+		}
+		klog.Errorf("Error when getting VirtualNode %s: %s", name, err)
+		return "", err
+	}
+
+	for i := range virtualNode.Status.Conditions {
+		condition := &virtualNode.Status.Conditions[i]
+		if condition.Status != offloadingv1beta1.RunningConditionStatusType {
+			return fmt.Sprintf("%s is %q", condition.Type, condition.Status), nil
+		}
+	}
+
+	node := &corev1.Node{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: name}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "node not found", nil
+		}
+		klog.Errorf("Error when getting node %s: %s", name, err)
+		return "", err
+	}
+
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == corev1.NodeReady && node.Status.Conditions[i].Status != corev1.ConditionTrue {
+			return "node is not Ready", nil
+		}
+	}
+
+	return "", nil
+}
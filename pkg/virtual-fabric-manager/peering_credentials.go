@@ -0,0 +1,157 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+)
+
+const (
+	// minPeeringTokenLifetime is the shortest lifetime accepted by the API server for a bound token.
+	minPeeringTokenLifetime = 10 * time.Minute
+	// rootCAConfigMapName is the ConfigMap published in every namespace with the CA bundle of the API server.
+	rootCAConfigMapName = "kube-root-ca.crt"
+	// rootCAConfigMapKey is the key of the CA bundle in the root CA ConfigMap.
+	rootCAConfigMapKey = "ca.crt"
+)
+
+// requestPeeringToken requests a token bound to the ServiceAccount of a consumer, valid until the given expiration.
+// It returns the token and its actual expiration, which may be earlier than the requested one.
+func requestPeeringToken(ctx context.Context, sa *corev1.ServiceAccount, expiration time.Time, cl client.Client) (string, time.Time, error) {
+	lifetime := time.Until(expiration)
+	if lifetime < minPeeringTokenLifetime {
+		lifetime = minPeeringTokenLifetime
+	}
+
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: ptr.To(int64(lifetime.Seconds())),
+		},
+	}
+	if err := cl.SubResource("token").Create(ctx, sa, tokenRequest); err != nil {
+		klog.Errorf("Error requesting a token for ServiceAccount %s: %s", sa.Name, err)
+		return "", time.Time{}, err
+	}
+
+	return tokenRequest.Status.Token, tokenRequest.Status.ExpirationTimestamp.Time, nil
+}
+
+// getClusterCA retrieves the CA bundle of the API server.
+func getClusterCA(ctx context.Context, cl client.Client) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: rootCAConfigMapName, Namespace: consts.LiqoNamespace}, cm); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	caCert, ok := cm.Data[rootCAConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s/%s does not contain %s", cm.Namespace, cm.Name, rootCAConfigMapKey)
+	}
+
+	return []byte(caCert), nil
+}
+
+// deleteLegacyPeeringSecret deletes the long-lived token Secret created for a consumer by previous versions, if any.
+func deleteLegacyPeeringSecret(ctx context.Context, consumerClusterID string, cl client.Client) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      peeringResourceName(consumerClusterID),
+			Namespace: consts.LiqoNamespace,
+		},
+	}
+	if err := cl.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		klog.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokePeeringCredentials revokes the credentials handed to a consumer.
+// Deleting the ServiceAccount invalidates every token issued for it, while the RBAC resources are removed
+// so that a ServiceAccount created again with the same name does not inherit any permission.
+func RevokePeeringCredentials(ctx context.Context, cl client.Client, consumerClusterID string) error {
+	name := peeringResourceName(consumerClusterID)
+
+	objects := []client.Object{
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: consts.LiqoNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: consts.LiqoNamespace}},
+	}
+	for _, flavorType := range []nodecorev1alpha1.FlavorTypeIdentifier{nodecorev1alpha1.TypeK8Slice, nodecorev1alpha1.TypeService} {
+		roleName := peeringRoleName(consumerClusterID, flavorType)
+		objects = append(objects,
+			&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: roleName}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: roleName}},
+		)
+		for _, namespace := range []string{consts.LiqoNamespace, TenantNamespaceName(consumerClusterID)} {
+			objects = append(objects,
+				&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: namespace}},
+				&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: namespace}},
+			)
+		}
+	}
+
+	for _, obj := range objects {
+		if err := cl.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error deleting %T %s: %s", obj, obj.GetName(), err)
+			return err
+		}
+	}
+
+	klog.Infof("Peering credentials of cluster %s revoked", consumerClusterID)
+	return nil
+}
+
+// ValidatePeeringToken checks that the given token is still valid and was issued to the given consumer.
+func ValidatePeeringToken(ctx context.Context, cl client.Client, consumerClusterID, token string) error {
+	if token == "" {
+		return fmt.Errorf("token not provided")
+	}
+
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	}
+	if err := cl.Create(ctx, review); err != nil {
+		klog.Errorf("Error reviewing the token: %s", err)
+		return err
+	}
+
+	if !review.Status.Authenticated {
+		return fmt.Errorf("token not authenticated: %s", review.Status.Error)
+	}
+
+	expected := fmt.Sprintf("system:serviceaccount:%s:%s", consts.LiqoNamespace, peeringResourceName(consumerClusterID))
+	if review.Status.User.Username != expected {
+		return fmt.Errorf("token issued to %s, not to cluster %s", review.Status.User.Username, consumerClusterID)
+	}
+
+	return nil
+}
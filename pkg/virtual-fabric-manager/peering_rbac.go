@@ -0,0 +1,335 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+
+	liqoConsts "github.com/liqotech/liqo/pkg/consts"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+)
+
+// TenantNamespaceName returns the name of the Liqo tenant namespace dedicated to the given cluster.
+func TenantNamespaceName(clusterID string) string {
+	return "liqo-tenant-" + clusterID
+}
+
+// peeringResourceName returns the name of the ServiceAccount and RBAC resources granted to a consumer.
+func peeringResourceName(consumerClusterID string) string {
+	return "liqo-cluster-" + consumerClusterID
+}
+
+// peeringRoleName returns the name of the RBAC resources granted to a consumer for the given flavor type.
+// The permissions of a Service are kept apart, so that a consumer buying both a K8Slice and a Service
+// from the same provider is granted both sets.
+func peeringRoleName(consumerClusterID string, flavorType nodecorev1alpha1.FlavorTypeIdentifier) string {
+	if flavorType == nodecorev1alpha1.TypeService {
+		return peeringResourceName(consumerClusterID) + "-service"
+	}
+	return peeringResourceName(consumerClusterID)
+}
+
+// PeeringClusterRules returns the cluster-wide permissions granted to a consumer for the given flavor type.
+// They only allow to read and remove the tenant namespace dedicated to the consumer,
+// which is created in advance by the provider. For a Service, the consumer peers the provider with itself
+// and reads the node created by the VirtualNode on the provider instead of removing the namespace.
+func PeeringClusterRules(consumerClusterID string, flavorType nodecorev1alpha1.FlavorTypeIdentifier) []rbacv1.PolicyRule {
+	if flavorType == nodecorev1alpha1.TypeService {
+		return []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"namespaces"},
+				ResourceNames: []string{TenantNamespaceName(consumerClusterID)},
+				Verbs:         []string{"get"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"nodes"},
+				Verbs:     []string{"get"},
+			},
+		}
+	}
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"namespaces"},
+			ResourceNames: []string{TenantNamespaceName(consumerClusterID)},
+			Verbs:         []string{"get", "delete"},
+		},
+	}
+}
+
+// PeeringLiqoNamespaceRules returns the permissions granted to a consumer in the Liqo namespace for the given flavor type,
+// used to retrieve the Liqo cluster ID and the network CIDRs of the provider. For a Service, the consumer also
+// generates the Tenant of the provider, which requires the authentication keys of the provider.
+func PeeringLiqoNamespaceRules(flavorType nodecorev1alpha1.FlavorTypeIdentifier) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"get", "list"},
+		},
+		{
+			APIGroups: []string{"ipam.liqo.io"},
+			Resources: []string{"networks"},
+			Verbs:     []string{"get", "list"},
+		},
+	}
+	if flavorType == nodecorev1alpha1.TypeService {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{liqoConsts.AuthKeysSecretName},
+			Verbs:         []string{"get"},
+		})
+	}
+	return rules
+}
+
+// PeeringTenantRules returns the permissions granted to a consumer in its tenant namespace for the given flavor type.
+// For a K8Slice, they cover the resources the consumer creates on the provider to establish the network
+// and the authentication, and to remove them when the peering is torn down. For a Service, the provider
+// consumes the resources of the consumer: they cover the resources of the consuming side of the peering,
+// created on the provider by the consumer.
+func PeeringTenantRules(flavorType nodecorev1alpha1.FlavorTypeIdentifier) []rbacv1.PolicyRule {
+	if flavorType == nodecorev1alpha1.TypeService {
+		return []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"networking.liqo.io"},
+				Resources: []string{"configurations", "publickeys"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{"networking.liqo.io"},
+				Resources: []string{"gatewayclients"},
+				Verbs:     []string{"get", "create"},
+			},
+			{
+				APIGroups: []string{"networking.liqo.io"},
+				Resources: []string{"connections"},
+				Verbs:     []string{"list"},
+			},
+			{
+				APIGroups: []string{"authentication.liqo.io"},
+				Resources: []string{"identities"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{"authentication.liqo.io"},
+				Resources: []string{"resourceslices"},
+				Verbs:     []string{"get", "create"},
+			},
+			{
+				APIGroups: []string{"offloading.liqo.io"},
+				Resources: []string{"virtualnodes"},
+				Verbs:     []string{"get"},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get", "list", "create"},
+			},
+		}
+	}
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"networking.liqo.io"},
+			Resources: []string{"configurations", "gatewayservers", "publickeys"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{"networking.liqo.io"},
+			Resources: []string{"connections"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{"authentication.liqo.io"},
+			Resources: []string{"tenants"},
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get", "create"},
+		},
+	}
+}
+
+// createOrGetPeeringTenantNamespace creates the tenant namespace dedicated to a consumer, if it does not exist yet.
+func createOrGetPeeringTenantNamespace(ctx context.Context, consumerClusterID string, cl client.Client) (*corev1.Namespace, error) {
+	ns := &corev1.Namespace{}
+	err := cl.Get(ctx, client.ObjectKey{Name: TenantNamespaceName(consumerClusterID)}, ns)
+	if err == nil {
+		return ns, nil
+	}
+	if client.IgnoreNotFound(err) != nil {
+		klog.Error(err)
+		return nil, err
+	}
+
+	klog.InfofDepth(1, "Tenant namespace does not exist, creating a new one")
+	ns = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: TenantNamespaceName(consumerClusterID),
+			Labels: map[string]string{
+				liqoConsts.RemoteClusterID:      consumerClusterID,
+				liqoConsts.TenantNamespaceLabel: "true",
+			},
+		},
+	}
+	if err := cl.Create(ctx, ns); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	return ns, nil
+}
+
+// ensurePeeringClusterRole creates or updates the ClusterRole granted to a consumer for the given flavor type.
+func ensurePeeringClusterRole(ctx context.Context, consumerClusterID string, flavorType nodecorev1alpha1.FlavorTypeIdentifier,
+	cl client.Client) (*rbacv1.ClusterRole, error) {
+	cr := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: peeringRoleName(consumerClusterID, flavorType),
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, cl, cr, func() error {
+		cr.Rules = PeeringClusterRules(consumerClusterID, flavorType)
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	return cr, nil
+}
+
+// ensurePeeringClusterRoleBinding creates or updates the ClusterRoleBinding of the consumer ServiceAccount.
+func ensurePeeringClusterRoleBinding(
+	ctx context.Context,
+	cr *rbacv1.ClusterRole,
+	sa *corev1.ServiceAccount,
+	cl client.Client) (*rbacv1.ClusterRoleBinding, error) {
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cr.Name,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, cl, crb, func() error {
+		crb.Subjects = []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      sa.Name,
+				Namespace: sa.Namespace,
+			},
+		}
+		if crb.CreationTimestamp.IsZero() {
+			crb.RoleRef = rbacv1.RoleRef{
+				Kind:     "ClusterRole",
+				Name:     cr.Name,
+				APIGroup: rbacv1.GroupName,
+			}
+		}
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	return crb, nil
+}
+
+// ensurePeeringRole creates or updates a Role granted to a consumer in the given namespace, and binds it to its ServiceAccount.
+func ensurePeeringRole(
+	ctx context.Context,
+	name,
+	namespace string,
+	rules []rbacv1.PolicyRule,
+	sa *corev1.ServiceAccount,
+	cl client.Client) error {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, cl, role, func() error {
+		role.Rules = rules
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, cl, rb, func() error {
+		rb.Subjects = []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      sa.Name,
+				Namespace: sa.Namespace,
+			},
+		}
+		if rb.CreationTimestamp.IsZero() {
+			rb.RoleRef = rbacv1.RoleRef{
+				Kind:     "Role",
+				Name:     role.Name,
+				APIGroup: rbacv1.GroupName,
+			}
+		}
+		return nil
+	}); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// ensurePeeringRBAC grants a consumer the permissions it needs to peer with this cluster for the given flavor type:
+// a ClusterRole limited to its tenant namespace, and namespaced Roles in the Liqo and tenant namespaces.
+func ensurePeeringRBAC(ctx context.Context, consumerClusterID string, flavorType nodecorev1alpha1.FlavorTypeIdentifier,
+	sa *corev1.ServiceAccount, cl client.Client) error {
+	tenantNamespace, err := createOrGetPeeringTenantNamespace(ctx, consumerClusterID, cl)
+	if err != nil {
+		return err
+	}
+
+	cr, err := ensurePeeringClusterRole(ctx, consumerClusterID, flavorType, cl)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ensurePeeringClusterRoleBinding(ctx, cr, sa, cl); err != nil {
+		return err
+	}
+
+	name := peeringRoleName(consumerClusterID, flavorType)
+	if err := ensurePeeringRole(ctx, name, consts.LiqoNamespace, PeeringLiqoNamespaceRules(flavorType), sa, cl); err != nil {
+		return err
+	}
+
+	return ensurePeeringRole(ctx, name, tenantNamespace.Name, PeeringTenantRules(flavorType), sa, cl)
+}
@@ -0,0 +1,242 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+)
+
+const testConsumerClusterID = "consumer"
+
+func TestPeeringClusterRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		flavorType nodecorev1alpha1.FlavorTypeIdentifier
+		want       []rbacv1.PolicyRule
+	}{
+		{
+			name:       "K8Slice",
+			flavorType: nodecorev1alpha1.TypeK8Slice,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"liqo-tenant-consumer"},
+					Verbs: []string{"get", "delete"}},
+			},
+		},
+		{
+			name:       "Service",
+			flavorType: nodecorev1alpha1.TypeService,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"liqo-tenant-consumer"},
+					Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeeringClusterRules(testConsumerClusterID, tt.flavorType); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PeeringClusterRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeeringLiqoNamespaceRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		flavorType nodecorev1alpha1.FlavorTypeIdentifier
+		want       []rbacv1.PolicyRule
+	}{
+		{
+			name:       "K8Slice",
+			flavorType: nodecorev1alpha1.TypeK8Slice,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{"ipam.liqo.io"}, Resources: []string{"networks"}, Verbs: []string{"get", "list"}},
+			},
+		},
+		{
+			name:       "Service",
+			flavorType: nodecorev1alpha1.TypeService,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{"ipam.liqo.io"}, Resources: []string{"networks"}, Verbs: []string{"get", "list"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"authentication-keys"}, Verbs: []string{"get"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeeringLiqoNamespaceRules(tt.flavorType); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PeeringLiqoNamespaceRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeeringTenantRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		flavorType nodecorev1alpha1.FlavorTypeIdentifier
+		want       []rbacv1.PolicyRule
+	}{
+		{
+			name:       "K8Slice",
+			flavorType: nodecorev1alpha1.TypeK8Slice,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{"networking.liqo.io"}, Resources: []string{"configurations", "gatewayservers", "publickeys"},
+					Verbs: []string{"get", "list", "watch", "create", "delete"}},
+				{APIGroups: []string{"networking.liqo.io"}, Resources: []string{"connections"}, Verbs: []string{"get", "list", "watch"}},
+				{APIGroups: []string{"authentication.liqo.io"}, Resources: []string{"tenants"},
+					Verbs: []string{"get", "list", "watch", "create", "delete"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "create"}},
+			},
+		},
+		{
+			name:       "Service",
+			flavorType: nodecorev1alpha1.TypeService,
+			want: []rbacv1.PolicyRule{
+				{APIGroups: []string{"networking.liqo.io"}, Resources: []string{"configurations", "publickeys"}, Verbs: []string{"create"}},
+				{APIGroups: []string{"networking.liqo.io"}, Resources: []string{"gatewayclients"}, Verbs: []string{"get", "create"}},
+				{APIGroups: []string{"networking.liqo.io"}, Resources: []string{"connections"}, Verbs: []string{"list"}},
+				{APIGroups: []string{"authentication.liqo.io"}, Resources: []string{"identities"}, Verbs: []string{"create"}},
+				{APIGroups: []string{"authentication.liqo.io"}, Resources: []string{"resourceslices"}, Verbs: []string{"get", "create"}},
+				{APIGroups: []string{"offloading.liqo.io"}, Resources: []string{"virtualnodes"}, Verbs: []string{"get"}},
+				{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list", "create"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeeringTenantRules(tt.flavorType); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PeeringTenantRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsurePeeringRBAC(t *testing.T) {
+	ctx := context.Background()
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: peeringResourceName(testConsumerClusterID), Namespace: consts.LiqoNamespace},
+	}
+	tenantNamespace := TenantNamespaceName(testConsumerClusterID)
+
+	tests := []struct {
+		name       string
+		flavorType nodecorev1alpha1.FlavorTypeIdentifier
+		roleName   string
+	}{
+		{name: "K8Slice", flavorType: nodecorev1alpha1.TypeK8Slice, roleName: "liqo-cluster-consumer"},
+		{name: "Service", flavorType: nodecorev1alpha1.TypeService, roleName: "liqo-cluster-consumer-service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A drifted Role must be restored to the exact permission set
+			drifted := &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.roleName, Namespace: tenantNamespace},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+			}
+			cl := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(drifted).Build()
+
+			if err := ensurePeeringRBAC(ctx, testConsumerClusterID, tt.flavorType, sa, cl); err != nil {
+				t.Fatalf("ensurePeeringRBAC() error = %v", err)
+			}
+
+			ns := &corev1.Namespace{}
+			if err := cl.Get(ctx, client.ObjectKey{Name: tenantNamespace}, ns); err != nil {
+				t.Fatalf("tenant namespace not created: %v", err)
+			}
+
+			cr := &rbacv1.ClusterRole{}
+			if err := cl.Get(ctx, client.ObjectKey{Name: tt.roleName}, cr); err != nil {
+				t.Fatalf("ClusterRole not created: %v", err)
+			}
+			if want := PeeringClusterRules(testConsumerClusterID, tt.flavorType); !reflect.DeepEqual(cr.Rules, want) {
+				t.Errorf("ClusterRole rules = %v, want %v", cr.Rules, want)
+			}
+			crb := &rbacv1.ClusterRoleBinding{}
+			if err := cl.Get(ctx, client.ObjectKey{Name: tt.roleName}, crb); err != nil {
+				t.Fatalf("ClusterRoleBinding not created: %v", err)
+			}
+			checkPeeringBinding(t, crb.RoleRef, crb.Subjects, "ClusterRole", tt.roleName, sa)
+
+			roles := map[string][]rbacv1.PolicyRule{
+				consts.LiqoNamespace: PeeringLiqoNamespaceRules(tt.flavorType),
+				tenantNamespace:      PeeringTenantRules(tt.flavorType),
+			}
+			for namespace, want := range roles {
+				role := &rbacv1.Role{}
+				if err := cl.Get(ctx, client.ObjectKey{Name: tt.roleName, Namespace: namespace}, role); err != nil {
+					t.Fatalf("Role not created in namespace %s: %v", namespace, err)
+				}
+				if !reflect.DeepEqual(role.Rules, want) {
+					t.Errorf("Role rules in namespace %s = %v, want %v", namespace, role.Rules, want)
+				}
+				rb := &rbacv1.RoleBinding{}
+				if err := cl.Get(ctx, client.ObjectKey{Name: tt.roleName, Namespace: namespace}, rb); err != nil {
+					t.Fatalf("RoleBinding not created in namespace %s: %v", namespace, err)
+				}
+				checkPeeringBinding(t, rb.RoleRef, rb.Subjects, "Role", tt.roleName, sa)
+			}
+		})
+	}
+}
+
+func TestEnsurePeeringRBACKeepsFlavorTypesApart(t *testing.T) {
+	ctx := context.Background()
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: peeringResourceName(testConsumerClusterID), Namespace: consts.LiqoNamespace},
+	}
+	cl := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+
+	for _, flavorType := range []nodecorev1alpha1.FlavorTypeIdentifier{nodecorev1alpha1.TypeK8Slice, nodecorev1alpha1.TypeService} {
+		if err := ensurePeeringRBAC(ctx, testConsumerClusterID, flavorType, sa, cl); err != nil {
+			t.Fatalf("ensurePeeringRBAC(%s) error = %v", flavorType, err)
+		}
+	}
+
+	role := &rbacv1.Role{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: "liqo-cluster-consumer", Namespace: TenantNamespaceName(testConsumerClusterID)}, role); err != nil {
+		t.Fatalf("K8Slice Role not found: %v", err)
+	}
+	if want := PeeringTenantRules(nodecorev1alpha1.TypeK8Slice); !reflect.DeepEqual(role.Rules, want) {
+		t.Errorf("K8Slice Role rules = %v, want %v", role.Rules, want)
+	}
+}
+
+func checkPeeringBinding(t *testing.T, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject, kind, name string, sa *corev1.ServiceAccount) {
+	t.Helper()
+	wantRoleRef := rbacv1.RoleRef{Kind: kind, Name: name, APIGroup: rbacv1.GroupName}
+	if roleRef != wantRoleRef {
+		t.Errorf("RoleRef = %v, want %v", roleRef, wantRoleRef)
+	}
+	wantSubjects := []rbacv1.Subject{{Kind: "ServiceAccount", Name: sa.Name, Namespace: sa.Namespace}}
+	if !reflect.DeepEqual(subjects, wantSubjects) {
+		t.Errorf("Subjects = %v, want %v", subjects, wantSubjects)
+	}
+}
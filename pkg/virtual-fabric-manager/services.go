@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/liqotech/liqo/apis/authentication/v1beta1"
@@ -31,8 +32,8 @@ import (
 	"github.com/liqotech/liqo/pkg/utils"
 	ipamLiqo "github.com/liqotech/liqo/pkg/utils/ipam"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -43,6 +44,7 @@ import (
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	reservation "github.com/fluidos-project/node/apis/reservation/v1alpha1"
 	"github.com/fluidos-project/node/pkg/utils/consts"
 )
@@ -90,48 +92,43 @@ func DecodeKubeconfig(encodedKubeconfig string) (*clientcmdapi.Config, error) {
 }
 
 // CreateKubeconfigForPeering creates a kubeconfig for peering with a remote cluster.
-func CreateKubeconfigForPeering(ctx context.Context, cl client.Client, consumerClusterID string) (*clientcmdapi.Config, error) {
+// The kubeconfig carries a token bound to the ServiceAccount of the consumer, valid at most until the given expiration,
+// which is returned together with the kubeconfig.
+func CreateKubeconfigForPeering(
+	ctx context.Context,
+	cl client.Client,
+	consumerClusterID string,
+	flavorType nodecorev1alpha1.FlavorTypeIdentifier,
+	expiration time.Time) (*clientcmdapi.Config, time.Time, error) {
 	// Create a Service Account
 	sa, err := createOrGetPeeringServiceAccount(ctx, consumerClusterID, cl)
 	if err != nil {
 		klog.Error(err)
-		return nil, err
-	}
-	// Create a ClusterRole
-	cr, err := createOrGetPeeringClusterRole(ctx, consumerClusterID, cl)
-	if err != nil {
-		klog.Error(err)
-		return nil, err
+		return nil, time.Time{}, err
 	}
-	// Create a ClusterRoleBinding
-	_, err = createOrGetPeeringClusterRoleBinding(ctx, consumerClusterID, cr, sa, cl)
-	if err != nil {
+	// Grant the permissions needed by the consumer for the flavor type, limited to its tenant namespace
+	if err := ensurePeeringRBAC(ctx, consumerClusterID, flavorType, sa, cl); err != nil {
 		klog.Error(err)
-		return nil, err
+		return nil, time.Time{}, err
 	}
-	// Create a Secret
-	secret, err := createOrGetPeeringSecret(ctx, consumerClusterID, sa, cl)
-	if err != nil {
-		klog.Error(err)
-		return nil, err
+	// Long-lived tokens are no longer handed out: drop the one created by previous versions, if any
+	if err := deleteLegacyPeeringSecret(ctx, consumerClusterID, cl); err != nil {
+		return nil, time.Time{}, err
 	}
 
-	// Sleep for a while to allow the Secret to be populated.
-	time.Sleep(5 * time.Second)
-
-	// Retrieve the token from the secret just created.
-	if err := cl.Get(ctx, client.ObjectKey{Name: secret.Name, Namespace: secret.Namespace}, secret); err != nil {
-		klog.Error(err)
-		return nil, err
+	// Request a short-lived token bound to the ServiceAccount
+	token, tokenExpiration, err := requestPeeringToken(ctx, sa, expiration, cl)
+	if err != nil {
+		return nil, time.Time{}, err
 	}
-
-	token := string(secret.Data[corev1.ServiceAccountTokenKey])
-	caCert := string(secret.Data[corev1.ServiceAccountRootCAKey])
-	// TODO: Retrieve the server URL from the remote cluster.
-	serverURL, err := getControlPlaneURL(ctx, cl)
+	// Hand out only an endpoint that answers with the expected CA bundle. The check runs from this cluster,
+	// so it cannot tell whether the consumer can reach the endpoint as well
+	endpoint, err := getAPIEndpoint(ctx, cl)
 	if err != nil {
-		klog.Error(err)
-		return nil, err
+		return nil, time.Time{}, err
+	}
+	if err := checkAPIEndpoint(ctx, endpoint); err != nil {
+		return nil, time.Time{}, err
 	}
 
 	// Create a kubeconfig for peering with the remote cluster.
@@ -140,8 +137,8 @@ func CreateKubeconfigForPeering(ctx context.Context, cl client.Client, consumerC
 		Kind:       "Config",
 		Clusters: map[string]*clientcmdapi.Cluster{
 			consumerClusterID: {
-				Server:                   serverURL,
-				CertificateAuthorityData: []byte(caCert),
+				Server:                   endpoint.URL,
+				CertificateAuthorityData: endpoint.CA,
 			},
 		},
 		AuthInfos: map[string]*clientcmdapi.AuthInfo{
@@ -158,39 +155,7 @@ func CreateKubeconfigForPeering(ctx context.Context, cl client.Client, consumerC
 		CurrentContext: consumerClusterID,
 	}
 
-	return kubeConfig, nil
-}
-
-// getControlPlaneURL retrieves the public control plane URL of the cluster.
-func getControlPlaneURL(ctx context.Context, cl client.Client) (string, error) {
-	port := "6443"
-
-	// Get control plane node list
-	nodeList := &corev1.NodeList{}
-	if err := cl.List(ctx, nodeList); err != nil {
-		klog.Error(err)
-		return "", err
-	}
-
-	// Iterate over nodes to find the control plane node
-	for i := range nodeList.Items {
-		node := &nodeList.Items[i]
-		klog.InfofDepth(1, "Node: %s - Found %d labels", node.Name, len(node.Labels))
-
-		_, existsControl := node.Labels["node-role.kubernetes.io/control-plane"]
-		_, existsMaster := node.Labels["node-role.kubernetes.io/master"]
-
-		if existsControl || existsMaster {
-			// Get the control plane node IP
-			for _, address := range node.Status.Addresses {
-				if address.Type == corev1.NodeInternalIP {
-					return "https://" + address.Address + ":" + port, nil
-				}
-			}
-		}
-	}
-
-	return "", fmt.Errorf("unable to retrieve the control plane URL")
+	return kubeConfig, tokenExpiration, nil
 }
 
 // createPeeringServiceAccount creates a ServiceAccount to be used for peering with a remote cluster.
@@ -198,7 +163,7 @@ func createOrGetPeeringServiceAccount(ctx context.Context, consumerClusterID str
 	// Get the ServiceAccount if it already exists
 	// If the ServiceAccount does not exist, create it
 	sa := &corev1.ServiceAccount{}
-	err := cl.Get(ctx, client.ObjectKey{Name: "liqo-cluster-" + consumerClusterID, Namespace: consts.LiqoNamespace}, sa)
+	err := cl.Get(ctx, client.ObjectKey{Name: peeringResourceName(consumerClusterID), Namespace: consts.LiqoNamespace}, sa)
 	if err != nil {
 		if client.IgnoreNotFound(err) != nil {
 			klog.Error(err)
@@ -208,7 +173,7 @@ func createOrGetPeeringServiceAccount(ctx context.Context, consumerClusterID str
 
 		sa = &corev1.ServiceAccount{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "liqo-cluster-" + consumerClusterID,
+				Name:      peeringResourceName(consumerClusterID),
 				Namespace: consts.LiqoNamespace,
 			},
 		}
@@ -222,148 +187,16 @@ func createOrGetPeeringServiceAccount(ctx context.Context, consumerClusterID str
 	return sa, nil
 }
 
-// createPeeringClusterRole creates a ClusterRole to be used for peering with a remote cluster.
-func createOrGetPeeringClusterRole(ctx context.Context, consumerClusterID string, cl client.Client) (*rbacv1.ClusterRole, error) {
-	// Get the ClusterRole if it already exists
-	// If the ClusterRole does not exist, create it
-	cr := &rbacv1.ClusterRole{}
-	err := cl.Get(ctx, client.ObjectKey{Name: "liqo-cluster-" + consumerClusterID}, cr)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			klog.Error(err)
-			return nil, err
-		}
-		klog.InfofDepth(1, "ClusterRole does not exist, creating a new one")
-
-		cr = &rbacv1.ClusterRole{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "liqo-cluster-" + consumerClusterID,
-			},
-			// TODO: Define the exact rules for Liqo peering
-			Rules: []rbacv1.PolicyRule{
-				{
-					APIGroups: []string{"networking.liqo.io"},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-				{
-					APIGroups: []string{"offloading.liqo.io"},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-				{
-					APIGroups: []string{"authentication.liqo.io"},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-				{
-					APIGroups: []string{"core.liqo.io"},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-				{
-					APIGroups: []string{"ipam.liqo.io"},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-				{
-					APIGroups: []string{""},
-					Resources: []string{"*"},
-					Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
-				},
-			},
-		}
-
-		err := cl.Create(ctx, cr)
-		if err != nil {
-			klog.Error(err)
-			return nil, err
-		}
-	}
-	return cr, nil
-}
-
-// createPeeringClusterRoleBinding creates a ClusterRoleBinding to be used for peering with a remote cluster.
-func createOrGetPeeringClusterRoleBinding(
-	ctx context.Context,
-	consumerClusterID string,
-	cr *rbacv1.ClusterRole,
-	sa *corev1.ServiceAccount,
-	cl client.Client) (*rbacv1.ClusterRoleBinding, error) {
-	// Get the ClusterRoleBinding if it already exists
-	// If the ClusterRoleBinding does not exist, create it
-	crb := &rbacv1.ClusterRoleBinding{}
-	err := cl.Get(ctx, client.ObjectKey{Name: "liqo-cluster-" + consumerClusterID, Namespace: consts.LiqoNamespace}, crb)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			klog.Error(err)
-			return nil, err
-		}
-		klog.InfofDepth(1, "ClusterRoleBinding does not exist, creating a new one")
-		crb = &rbacv1.ClusterRoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "liqo-cluster-" + consumerClusterID,
-			},
-			Subjects: []rbacv1.Subject{
-				{
-					Kind:      "ServiceAccount",
-					Name:      sa.Name,
-					Namespace: sa.Namespace,
-				},
-			},
-			RoleRef: rbacv1.RoleRef{
-				Kind:     "ClusterRole",
-				Name:     cr.Name,
-				APIGroup: "rbac.authorization.k8s.io",
-			},
-		}
-
-		err := cl.Create(ctx, crb)
-		if err != nil {
-			klog.Error(err)
-			return nil, err
-		}
-	}
-	return crb, nil
-}
-
-// createOrGetPeeringSecret creates a Secret to be used for peering with a remote cluster.
-func createOrGetPeeringSecret(ctx context.Context, consumerClusterID string, sa *corev1.ServiceAccount, cl client.Client) (*corev1.Secret, error) {
-	// Get the Secret if it already exists
-	// If the Secret does not exist, create it
-	secret := &corev1.Secret{}
-	err := cl.Get(ctx, client.ObjectKey{Name: "liqo-cluster-" + consumerClusterID, Namespace: consts.LiqoNamespace}, secret)
-	if err != nil {
-		if client.IgnoreNotFound(err) != nil {
-			klog.Error(err)
-			return nil, err
-		}
-		klog.InfofDepth(1, "Secret not found, creating a new one")
-		// Create the Secret
-		secret = &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "liqo-cluster-" + consumerClusterID,
-				Namespace: consts.LiqoNamespace,
-				Annotations: map[string]string{
-					"kubernetes.io/service-account.name": sa.Name,
-				},
-			},
-			Type: corev1.SecretTypeServiceAccountToken,
-		}
+func createTenantNamespace(ctx context.Context, cl client.Client, clusterID corev1beta1.ClusterID) (string, error) {
+	name := TenantNamespaceName(string(clusterID))
 
-		err := cl.Create(ctx, secret)
-		if err != nil {
-			klog.Error(err)
-			return nil, err
-		}
+	// The provider creates in advance the tenant namespace of its consumers, which are not allowed to create namespaces
+	if err := cl.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{}); err == nil {
+		klog.InfofDepth(1, "Tenant namespace %s already exists in %s cluster", name, clusterID)
+		return name, nil
 	}
 
-	return secret, nil
-}
-
-func createTenantNamespace(ctx context.Context, cl client.Client, clusterID corev1beta1.ClusterID) (string, error) {
 	// Create tenant namespace
-	name := "liqo-tenant-" + string(clusterID)
 	tenantNamespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
@@ -406,41 +239,41 @@ func CreateKubeClientFromConfig(kubeconfig *clientcmdapi.Config, localScheme *ru
 }
 
 // EstablishNetwork enables the networking module of Liqo between two clusters.
+// It returns ErrPeeringInProgress until the connections on both clusters are established.
 func EstablishNetwork(
 	ctx context.Context,
 	localClient client.Client,
 	localRestConfig *rest.Config,
 	remoteClient client.Client,
 	remoteRestConfig *rest.Config,
-) (localConn, remoteConn *networkingv1beta1.Connection, localNsName, remoteNsName string, er error) {
-	// Retrieve remote liqo cluster id
-
+) error {
 	klog.InfofDepth(1, "Establishing network...")
 
 	// Transform the client to a clientSet
 	remoteKubeClient, err := kubernetes.NewForConfig(remoteRestConfig)
 	if err != nil {
 		klog.Errorf("Error creating the clientSet: %s", err)
-		return nil, nil, "", "", err
+		return err
 	}
 
+	// Retrieve remote liqo cluster id
 	remoteClusterIdentity, err := utils.GetClusterID(ctx, remoteKubeClient, consts.LiqoNamespace)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	localKubeClient, err := kubernetes.NewForConfig(localRestConfig)
 	if err != nil {
 		klog.Errorf("Error creating the clientSet: %s", err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	// Retrieve local liqo cluster id
 	localClusterIdentity, err := utils.GetClusterID(ctx, localKubeClient, consts.LiqoNamespace)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	klog.InfoDepth(1, "Creating tenant namespaces...")
@@ -450,7 +283,7 @@ func EstablishNetwork(
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -459,7 +292,7 @@ func EstablishNetwork(
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -475,14 +308,14 @@ func EstablishNetwork(
 
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	// Remote cluster applies Local configuration
 	if err = remoteClient.Create(ctx, localConfiguration); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -498,14 +331,14 @@ func EstablishNetwork(
 
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	// Local cluster applies Remote configuration
 	if err = localClient.Create(ctx, remoteConfiguration); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -513,7 +346,7 @@ func EstablishNetwork(
 
 	klog.InfoDepth(1, "Creating Gateway Server and Client...")
 
-	gwServer, gatewayServerIP, gatewayServerPort, remoteSecretRef, err := createGatewayServer(
+	gwServer, err := createGatewayServer(
 		ctx,
 		localClusterIdentity,
 		remoteClient,
@@ -521,32 +354,33 @@ func EstablishNetwork(
 		remoteNamespaceName,
 	)
 	if err != nil {
-		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	klog.InfofDepth(
 		1,
 		"Gateway Server IP %s and Port %d created. Remote secret ref %s detected.",
-		gatewayServerIP,
-		gatewayServerPort,
-		remoteSecretRef.Name,
+		gwServer.Status.Endpoint.Addresses,
+		gwServer.Status.Endpoint.Port,
+		gwServer.Status.SecretRef.Name,
 	)
 
-	gwClient, localSecretRef, err := createGatewayClient(
+	gwClient, err := createGatewayClient(
 		ctx,
 		localClient,
 		localKubeClient,
 		localNamespaceName,
 		remoteClusterIdentity,
-		gatewayServerIP,
-		gatewayServerPort,
+		gwServer.Status.Endpoint.Addresses,
+		gwServer.Status.Endpoint.Port,
 	)
 	if err != nil {
-		return nil, nil, "", "", err
+		return err
 	}
 
-	klog.InfofDepth(1, "Gatewat Client created. Local secret reference %s found", localSecretRef.Name)
+	klog.InfofDepth(1, "Gateway Client created. Local secret reference %s found", gwClient.Status.SecretRef.Name)
+
+	klog.InfoDepth(1, "Creating public keys...")
 
 	// Generate public key on Local cluster
 	localPublicKey, err := generatePublicKey(
@@ -554,11 +388,11 @@ func EstablishNetwork(
 		localClient,
 		localClusterIdentity,
 		remoteNamespaceName,
-		localSecretRef,
+		gwClient.Status.SecretRef,
 	)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	// Create public key on Local cluster
@@ -566,7 +400,7 @@ func EstablishNetwork(
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -578,11 +412,11 @@ func EstablishNetwork(
 		remoteClient,
 		remoteClusterIdentity,
 		localNamespaceName,
-		remoteSecretRef,
+		gwServer.Status.SecretRef,
 	)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, "", "", err
+		return err
 	}
 
 	// Create public key on Remote cluster
@@ -590,7 +424,7 @@ func EstablishNetwork(
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, "", "", err
+			return err
 		}
 	}
 
@@ -598,113 +432,52 @@ func EstablishNetwork(
 
 	klog.InfoDepth(1, "Checking connections both on local and remote clusters...")
 
-	localConnection, remoteConnection, err := checkConnections(
-		ctx,
-		localClient,
-		localClusterIdentity,
-		remoteClient,
-		remoteClusterIdentity,
-		gwClient.Name,
-		gwServer.Name,
-	)
-	if err != nil {
-		klog.Error(err)
-		return nil, nil, "", "", err
+	// Check connection on local cluster, owned by the gateway client
+	if err := checkConnection(ctx, localClient, localNamespaceName, remoteClusterIdentity, gwClient.Name); err != nil {
+		return err
 	}
 
-	return localConnection, remoteConnection, localNamespaceName, remoteNamespaceName, nil
+	// Check connection on remote cluster, owned by the gateway server
+	return checkConnection(ctx, remoteClient, remoteNamespaceName, localClusterIdentity, gwServer.Name)
 }
 
-func checkConnections(
+// checkConnection checks that the Connection towards remoteClusterIdentity owned by the given gateway is established.
+// It returns ErrPeeringInProgress until it is Connected.
+func checkConnection(
 	ctx context.Context,
-	localClient client.Client,
-	localClusterIdentity corev1beta1.ClusterID,
-	remoteClient client.Client,
+	cl client.Client,
+	namespaceName string,
 	remoteClusterIdentity corev1beta1.ClusterID,
-	gwClientName,
-	gwServerName string,
-) (localConn, remoteConn *networkingv1beta1.Connection, er error) {
-	// Check connection on local cluster
-	localConnection := &networkingv1beta1.Connection{}
-	remoteConnection := &networkingv1beta1.Connection{}
-
-	timeout := time.After(2 * time.Minute)
-	tick := time.Tick(5 * time.Second)
-
-outerLoopLocalConnection:
-	for {
-		select {
-		case <-timeout:
-			return nil, nil, fmt.Errorf("timed out waiting for local connection to be ready")
-		case <-tick:
-			// Get the local connection, between all the connections in the cluster
-			// Choose the one labeled liqo.io/remote-cluster-id to be the remote cluster id and owner reference to be the gateway client
-			localConnections := &networkingv1beta1.ConnectionList{}
-			err := localClient.List(ctx, localConnections, client.MatchingLabels{liqoConsts.RemoteClusterID: string(remoteClusterIdentity)})
-			if err != nil {
-				if client.IgnoreNotFound(err) != nil {
-					klog.Error(err)
-					return nil, nil, err
-				}
-			} else {
-				break outerLoopLocalConnection
-			}
-			// Get the connection with the gateway client as owner reference
-			for i := range localConnections.Items {
-				conn := &localConnections.Items[i]
-				if conn.OwnerReferences[0].Name == gwClientName {
-					localConnection = conn
-					// Check the connection status
-					if localConnection.Status.Value == networkingv1beta1.Connected {
-						break outerLoopLocalConnection
-					}
-					klog.InfofDepth(1, "Local connection %s not ready, it is in status %s", localConnection.Name, localConnection.Status.Value)
-				}
-			}
-		}
+	gatewayName string,
+) error {
+	// Get the connection, between all the connections in the cluster
+	// Choose the one labeled liqo.io/remote-cluster-id to be the remote cluster id and owner reference to be the gateway
+	connections := &networkingv1beta1.ConnectionList{}
+	err := cl.List(ctx, connections, client.InNamespace(namespaceName),
+		client.MatchingLabels{liqoConsts.RemoteClusterID: string(remoteClusterIdentity)})
+	if err != nil {
+		klog.Error(err)
+		return err
 	}
 
-	klog.InfofDepth(1, "Local connection %s found", localConnection.Name)
-
-	// Check connection on remote cluster
-outerLoopRemoteConnection:
-	for {
-		select {
-		case <-timeout:
-			return nil, nil, fmt.Errorf("timed out waiting for remote connection to be ready")
-		case <-tick:
-			// Get the remote connection, between all the connections in the cluster
-			// Choose the one labeled liqo.io/remote-cluster-id to be the remote cluster id and owner reference to be the gateway server
-			remoteConnections := &networkingv1beta1.ConnectionList{}
-			err := remoteClient.List(ctx, remoteConnections, client.MatchingLabels{liqoConsts.RemoteClusterID: string(localClusterIdentity)})
-			if err != nil {
-				if client.IgnoreNotFound(err) != nil {
-					klog.Error(err)
-					return nil, nil, err
-				}
-			} else {
-				break outerLoopRemoteConnection
-			}
-			// Get the connection with the gateway server as owner reference
-			for i := range remoteConnections.Items {
-				conn := &remoteConnections.Items[i]
-				if conn.OwnerReferences[0].Name == gwServerName {
-					remoteConnection = conn
-					// Check the connection status
-					if remoteConnection.Status.Value == networkingv1beta1.Connected {
-						break outerLoopRemoteConnection
-					}
-					klog.InfofDepth(1, "Remote connection %s not ready, it is in status %s", remoteConnection.Name, remoteConnection.Status.Value)
-				}
-			}
+	for i := range connections.Items {
+		conn := &connections.Items[i]
+		if len(conn.OwnerReferences) == 0 || conn.OwnerReferences[0].Name != gatewayName {
+			continue
+		}
+		// Check the connection status
+		if conn.Status.Value != networkingv1beta1.Connected {
+			return fmt.Errorf("%w: connection %s/%s is in status %q", ErrPeeringInProgress, namespaceName, conn.Name, conn.Status.Value)
 		}
+		klog.InfofDepth(1, "Connection %s/%s established", namespaceName, conn.Name)
+		return nil
 	}
 
-	klog.InfofDepth(1, "Remote connection %s found", remoteConnection.Name)
-
-	return localConnection, remoteConnection, nil
+	return fmt.Errorf("%w: waiting for the connection of gateway %s/%s", ErrPeeringInProgress, namespaceName, gatewayName)
 }
 
+// createGatewayClient creates the GatewayClient on the local cluster.
+// It returns ErrPeeringInProgress until its Secret reference is set.
 func createGatewayClient(
 	ctx context.Context,
 	localClient client.Client,
@@ -713,7 +486,7 @@ func createGatewayClient(
 	remoteClusterIdentity corev1beta1.ClusterID,
 	gatewayServerIP []string,
 	gatewayServerPort int32,
-) (gwCl *networkingv1beta1.GatewayClient, localSecRef *corev1.ObjectReference, er error) {
+) (*networkingv1beta1.GatewayClient, error) {
 	// Create GatewayClient on Local cluster
 	gwClientName := string(remoteClusterIdentity)
 	gatewayClient, err := networkForgeLiqo.GatewayClient(
@@ -733,7 +506,7 @@ func createGatewayClient(
 	)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Create GatewayClient on Local cluster
@@ -741,57 +514,37 @@ func createGatewayClient(
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, err
+			return nil, err
 		}
 	}
 
 	klog.InfofDepth(1, "GatewayClient %s created", gwClientName)
 
-	klog.InfoDepth(1, "Creating public keys...")
-
-	// Retrieve local localSecret for the local cluster
-	timeout := time.After(2 * time.Minute)
-	tick := time.Tick(5 * time.Second)
-
-outerLoopGwClient:
-	for {
-		select {
-		case <-timeout:
-			return nil, nil, fmt.Errorf("timed out waiting for GatewayClient Secret Ref to be ready")
-		case <-tick:
-			// Retrieve the gateway client
-			err = localClient.Get(ctx, client.ObjectKey{Name: gwClientName, Namespace: localNamespaceName}, gatewayClient)
-			if err != nil {
-				klog.Error(err)
-				return nil, nil, err
-			}
-			if gatewayClient.Status.SecretRef != nil {
-				break outerLoopGwClient
-			}
-		}
+	// Retrieve the gateway client
+	err = localClient.Get(ctx, client.ObjectKey{Name: gwClientName, Namespace: localNamespaceName}, gatewayClient)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	if gatewayClient.Status.SecretRef == nil {
+		return nil, fmt.Errorf("%w: waiting for the Secret of GatewayClient %s/%s", ErrPeeringInProgress, localNamespaceName, gwClientName)
 	}
 
-	localSecretRef := gatewayClient.Status.SecretRef
-
-	return gatewayClient, localSecretRef, nil
+	return gatewayClient, nil
 }
 
+// createGatewayServer creates the GatewayServer on the remote cluster.
+// It returns ErrPeeringInProgress until its endpoint and Secret reference are set.
 func createGatewayServer(
 	ctx context.Context,
 	localClusterIdentity corev1beta1.ClusterID,
 	remoteClient client.Client,
 	remoteKubeClient kubernetes.Interface,
 	remoteNamespaceName string,
-) (
-	gatewayServer *networkingv1beta1.GatewayServer,
-	gatewayServerIP []string,
-	gatewayServerPort int32,
-	remoteSecretRef *corev1.ObjectReference,
-	err error,
-) {
+) (*networkingv1beta1.GatewayServer, error) {
 	// Create GatewayServer on Remote cluster
 	gwServerName := string(localClusterIdentity)
-	gatewayServer, err = networkForgeLiqo.GatewayServer(
+	gatewayServer, err := networkForgeLiqo.GatewayServer(
 		remoteNamespaceName,
 		&gwServerName,
 		&networkForgeLiqo.GwServerOptions{
@@ -807,49 +560,33 @@ func createGatewayServer(
 	)
 	if err != nil {
 		klog.Error(err)
-		return nil, nil, 0, nil, err
+		return nil, err
 	}
 
 	if err = remoteClient.Create(ctx, gatewayServer); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			klog.Error(err)
-			return nil, nil, 0, nil, err
+			return nil, err
 		}
 	}
 
 	klog.InfofDepth(1, "GatewayServer %s created", gwServerName)
 
-	// Wait for the GatewayServer to be ready with a timeout
-	timeout := time.After(2 * time.Minute)
-	tick := time.Tick(5 * time.Second)
-
-outerLoopGwServer:
-	for {
-		select {
-		case <-timeout:
-			return nil, nil, 0, nil, fmt.Errorf("timed out waiting for GatewayServer to be ready")
-		case <-tick:
-			// Retrieve the GatewayServer IP
-			err = remoteClient.Get(ctx, client.ObjectKey{Name: gwServerName, Namespace: remoteNamespaceName}, gatewayServer)
-			if err != nil {
-				klog.Error(err)
-				return nil, nil, 0, nil, err
-			}
-			if gatewayServer.Status.Endpoint != nil && gatewayServer.Status.Endpoint.Addresses != nil && gatewayServer.Status.SecretRef != nil {
-				break outerLoopGwServer
-			}
-		}
+	// Retrieve the GatewayServer endpoint
+	err = remoteClient.Get(ctx, client.ObjectKey{Name: gwServerName, Namespace: remoteNamespaceName}, gatewayServer)
+	if err != nil {
+		klog.Error(err)
+		return nil, err
+	}
+	if gatewayServer.Status.Endpoint == nil || gatewayServer.Status.Endpoint.Addresses == nil || gatewayServer.Status.SecretRef == nil {
+		return nil, fmt.Errorf("%w: waiting for GatewayServer %s/%s to be ready", ErrPeeringInProgress, remoteNamespaceName, gwServerName)
 	}
 
-	// Retrieve the GatewayServer IP
-	gatewayServerIP = gatewayServer.Status.Endpoint.Addresses
-	gatewayServerPort = gatewayServer.Status.Endpoint.Port
-	remoteSecretRef = gatewayServer.Status.SecretRef
-
-	return gatewayServer, gatewayServerIP, gatewayServerPort, remoteSecretRef, nil
+	return gatewayServer, nil
 }
 
 // Authentication enables the authentication module of Liqo between two clusters.
+// It returns ErrPeeringInProgress while the remote cluster has not completed its part of the challenge.
 func Authentication(
 	ctx context.Context,
 	localClient client.Client,
@@ -899,34 +636,24 @@ func Authentication(
 	// Create Nonce secret on Remote cluster
 	err = remoteClient.Create(ctx, nonceSecret)
 	if err != nil {
-		klog.Error(err)
-		return err
+		if !errors.IsAlreadyExists(err) {
+			klog.Error(err)
+			return err
+		}
 	}
 
 	klog.InfofDepth(1, "Nonce secret %s created in remote cluster %s", nonceSecret.Name, remoteClusterIdentity)
 
-	// Get signed nonce
-	timeout := time.After(2 * time.Minute)
-	tick := time.Tick(5 * time.Second)
-outerLoopNonce:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timed out waiting for signed nonce")
-		case <-tick:
-			// Retrieve the nonce secret
-			err = remoteClient.Get(ctx, client.ObjectKey{Name: nonceSecret.Name, Namespace: remoteNamespaceName}, nonceSecret)
-			if err != nil {
-				klog.Error(err)
-				return err
-			}
-			if nonceSecret.Data["nonce"] != nil {
-				break outerLoopNonce
-			}
-		}
+	// Retrieve the nonce secret, filled in by the remote cluster
+	err = remoteClient.Get(ctx, client.ObjectKey{Name: nonceSecret.Name, Namespace: remoteNamespaceName}, nonceSecret)
+	if err != nil {
+		klog.Error(err)
+		return err
 	}
-
 	nonceData := nonceSecret.Data["nonce"]
+	if nonceData == nil {
+		return fmt.Errorf("%w: waiting for nonce %s/%s", ErrPeeringInProgress, remoteNamespaceName, nonceSecret.Name)
+	}
 
 	// Ensure signed nonce
 	klog.InfoDepth(1, "Ensuring signed nonce...")
@@ -942,28 +669,11 @@ outerLoopNonce:
 		return err
 	}
 
-	// Retrieving signed nonce
+	// Retrieving signed nonce, until it is signed the secret carries no signature
 	klog.InfoDepth(1, "Retrieving signed nonce...")
-	signedNonce := []byte{}
-	_ = signedNonce
-outerLoopSignedNonce:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timed out waiting for signed nonce")
-		case <-tick:
-			// Retrieve the signed nonce secret
-			signedNonce, err = authenticationUtils.RetrieveSignedNonce(ctx, localClient, remoteClusterIdentity, localNamespaceName)
-			if err != nil {
-				if client.IgnoreNotFound(err) != nil {
-					klog.Error(err)
-					return err
-				}
-			}
-			if signedNonce != nil {
-				break outerLoopSignedNonce
-			}
-		}
+	signedNonce, err := authenticationUtils.RetrieveSignedNonce(ctx, localClient, remoteClusterIdentity, localNamespaceName)
+	if err != nil {
+		return fmt.Errorf("%w: waiting for signed nonce: %w", ErrPeeringInProgress, err)
 	}
 
 	tenant, err := authenticationUtils.GenerateTenant(
@@ -983,29 +693,22 @@ outerLoopSignedNonce:
 	// Create Tenant on Remote cluster
 	err = remoteClient.Create(ctx, tenant)
 	if err != nil {
-		klog.Error(err)
-		return err
+		if !errors.IsAlreadyExists(err) {
+			klog.Error(err)
+			return err
+		}
 	}
 
 	klog.InfofDepth(1, "Tenant %s created in remote cluster %s", tenant.Name, remoteClusterIdentity)
 
-	// Wait for tenant status to be ready
-outerLoopTenant:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timed out waiting for tenant to be ready")
-		case <-tick:
-			// Retrieve the tenant
-			err = remoteClient.Get(ctx, client.ObjectKey{Name: tenant.Name, Namespace: tenant.Namespace}, tenant)
-			if err != nil {
-				klog.Error(err)
-				return err
-			}
-			if tenant.Status.AuthParams != nil && tenant.Status.TenantNamespace != "" {
-				break outerLoopTenant
-			}
-		}
+	// Check the tenant status
+	err = remoteClient.Get(ctx, client.ObjectKey{Name: tenant.Name, Namespace: tenant.Namespace}, tenant)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+	if tenant.Status.AuthParams == nil || tenant.Status.TenantNamespace == "" {
+		return fmt.Errorf("%w: waiting for tenant %s/%s to be ready", ErrPeeringInProgress, tenant.Namespace, tenant.Name)
 	}
 
 	klog.InfofDepth(1, "Tenant %s ready", tenant.Name)
@@ -1029,8 +732,10 @@ outerLoopTenant:
 	// Create Identity on Local cluster
 	err = localClient.Create(ctx, identity)
 	if err != nil {
-		klog.Error(err)
-		return err
+		if !errors.IsAlreadyExists(err) {
+			klog.Error(err)
+			return err
+		}
 	}
 
 	klog.InfofDepth(1, "Identity %s created in local cluster %s", identity.Name, localClusterIdentity)
@@ -1038,7 +743,8 @@ outerLoopTenant:
 	return nil
 }
 
-// Offloading enables the offloading module of Liqo between two clusters.
+// Offloading requests to the remote cluster the resources of the contract through a ResourceSlice.
+// It returns ErrPeeringInProgress until the resources are granted, and an error if the remote cluster denies them.
 func Offloading(
 	ctx context.Context,
 	localClient client.Client,
@@ -1088,61 +794,102 @@ func Offloading(
 
 	// Create resourceslice on Local cluster
 	err = localClient.Create(ctx, rs)
+	if err != nil {
+		if !errors.IsAlreadyExists(err) {
+			klog.Error(err)
+			return err
+		}
+	}
+
+	// Check the resource slice status authentication to be ready and resources accepted
+	err = localClient.Get(ctx, client.ObjectKey{Name: rs.Name, Namespace: rs.Namespace}, rs)
 	if err != nil {
 		klog.Error(err)
 		return err
 	}
-
-	// Wait for resource slice status authentication to be ready and resources accepted
-	timeout := time.After(2 * time.Minute)
-	tick := time.Tick(5 * time.Second)
-
-outerLoopQuotas:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timed out waiting for quotas to be ready")
-		case <-tick:
-			// Retrieve the resourceslice
-			err = localClient.Get(ctx, client.ObjectKey{Name: rs.Name, Namespace: rs.Namespace}, rs)
-			if err != nil {
-				klog.Error(err)
-				return err
-			}
-			if rs.Status.Resources != nil && rs.Status.AuthParams != nil {
-				break outerLoopQuotas
-			}
-		}
+	if condition := resourceSliceCondition(rs, v1beta1.ResourceSliceConditionTypeResources); condition != nil &&
+		condition.Status == v1beta1.ResourceSliceConditionDenied {
+		return fmt.Errorf("resources of ResourceSlice %s/%s denied by cluster %s: %s", rs.Namespace, rs.Name, remoteClusterIdentity, condition.Message)
+	}
+	if rs.Status.Resources == nil || rs.Status.AuthParams == nil {
+		return fmt.Errorf("%w: waiting for the resources of ResourceSlice %s/%s", ErrPeeringInProgress, rs.Namespace, rs.Name)
 	}
 
 	klog.InfofDepth(1, "ResourceSlice %s created in local cluster %s", rs.Name, localNamespaceName)
 
-	klog.InfoDepth(1, "Waiting for VirtualNode...")
-	// Wait for virtualnode created in local cluster
-outerLoopVirtualNode:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timed out waiting for virtual node to be ready")
-		case <-tick:
-			// Retrieve the virtual node
-			virtualNode := &offloadingv1beta1.VirtualNode{}
-			err = localClient.Get(ctx, client.ObjectKey{Name: rs.Name, Namespace: rs.Namespace}, virtualNode)
-			if err != nil {
-				if client.IgnoreNotFound(err) != nil {
-					klog.Error(err)
-					return err
-				}
-			}
-			break outerLoopVirtualNode
+	return nil
+}
+
+// waitVirtualNode returns ErrPeeringInProgress until the VirtualNode of the contract and the node it creates are ready.
+func waitVirtualNode(ctx context.Context, localClient client.Client, localNamespaceName string, contract *reservation.Contract) error {
+	reason, err := checkVirtualNode(ctx, localClient, contract.Name, localNamespaceName)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return fmt.Errorf("%w: waiting for VirtualNode %s/%s: %s", ErrPeeringInProgress, localNamespaceName, contract.Name, reason)
+	}
+
+	klog.InfofDepth(1, "VirtualNode %s ready in namespace %s", contract.Name, localNamespaceName)
+
+	return nil
+}
+
+// SyncResourceSlice aligns the resources requested by the ResourceSlice of a contract with its current configuration,
+// so that an amended contract resizes the resources offloaded to the remote cluster without a new peering.
+// It does nothing if the ResourceSlice does not exist yet.
+func SyncResourceSlice(ctx context.Context, localClient client.Client, contract *reservation.Contract, remoteClusterID string) error {
+	rs := &v1beta1.ResourceSlice{}
+	if err := localClient.Get(ctx, client.ObjectKey{Name: contract.Name, Namespace: TenantNamespaceName(remoteClusterID)}, rs); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
 		}
+		klog.Errorf("Error when getting ResourceSlice %s: %s", contract.Name, err)
+		return err
 	}
 
-	klog.InfofDepth(1, "VirtualNode %s created in namespace %s", rs.Name, localNamespaceName)
+	resources, err := getContractResourcesByClusterID(contract)
+	if err != nil {
+		return err
+	}
+	resourceList := corev1.ResourceList{}
+	for name, value := range resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			klog.Errorf("Error when parsing resource %s of contract %s: %s", name, contract.Name, err)
+			return err
+		}
+		resourceList[name] = quantity
+	}
+
+	if resourceListEqual(rs.Spec.Resources, resourceList) {
+		return nil
+	}
+
+	rs.Spec.Resources = resourceList
+	if err := localClient.Update(ctx, rs); err != nil {
+		klog.Errorf("Error when updating ResourceSlice %s/%s: %s", rs.Namespace, rs.Name, err)
+		return err
+	}
+	klog.Infof("ResourceSlice %s/%s resized to %v", rs.Namespace, rs.Name, resources)
 
 	return nil
 }
 
+// resourceListEqual returns whether two resource lists contain the same quantities.
+func resourceListEqual(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, quantity := range a {
+		other, ok := b[name]
+		if !ok || quantity.Cmp(other) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func generatePublicKey(
 	ctx context.Context,
 	cl client.Client,
@@ -1216,42 +963,82 @@ func createConfiguration(
 	return configuration, nil
 }
 
-// PeerWithCluster creates a ForeignCluster resource to peer with a remote cluster.
+// PeeringReporter is invoked by PeerWithCluster before each peering step is performed.
+type PeeringReporter func(step nodecorev1alpha1.PeeringStep, msg string)
+
+// peeringStep is a step of the peering performed by PeerWithCluster.
+type peeringStep struct {
+	step nodecorev1alpha1.PeeringStep
+	msg  string
+	run  func() error
+}
+
+// PeerWithCluster establishes the peering with a remote cluster for a contract: networking, authentication,
+// then the ResourceSlice of the contract and its VirtualNode. Steps already completed before from are skipped,
+// and nothing is done if from is PeeringCompleted. An unknown step is reported as an error.
+// No step blocks: a step waiting on the clusters returns ErrPeeringInProgress, and the caller is expected
+// to call PeerWithCluster again later, from the last reported step. Every step tolerates resources that
+// already exist, so it can be resumed at any point.
 func PeerWithCluster(
 	ctx context.Context,
 	localClient client.Client,
 	localRestConfig *rest.Config,
 	remoteclient client.Client,
 	remoteRestConfig *rest.Config,
-	contract *reservation.Contract) (*networkingv1beta1.Connection, error) {
-	// Establish network with remote cluster
-	localConnection, _, localNamespaceName, remoteNamespaceName, err := EstablishNetwork(
-		ctx,
-		localClient,
-		localRestConfig,
-		remoteclient,
-		remoteRestConfig)
+	contract *reservation.Contract,
+	from nodecorev1alpha1.PeeringStep,
+	report PeeringReporter) error {
+	localClusterIdentity, err := getClusterIdentity(ctx, localRestConfig)
 	if err != nil {
 		klog.Error(err)
-		return nil, err
+		return err
 	}
 
-	// Authenticate with remote cluster
-	err = Authentication(ctx, localClient, localRestConfig, remoteclient, remoteRestConfig, localNamespaceName, remoteNamespaceName)
+	remoteClusterIdentity, err := getClusterIdentity(ctx, remoteRestConfig)
 	if err != nil {
-		if !errors.IsAlreadyExists(err) {
-			klog.Error(err)
-			return nil, err
+		klog.Error(err)
+		return err
+	}
+
+	localNamespaceName := TenantNamespaceName(string(remoteClusterIdentity))
+	remoteNamespaceName := TenantNamespaceName(string(localClusterIdentity))
+
+	steps := []peeringStep{
+		{nodecorev1alpha1.PeeringEstablishingNetwork, "Establishing network with cluster " + string(remoteClusterIdentity), func() error {
+			return EstablishNetwork(ctx, localClient, localRestConfig, remoteclient, remoteRestConfig)
+		}},
+		{nodecorev1alpha1.PeeringAuthenticating, "Authenticating with cluster " + string(remoteClusterIdentity), func() error {
+			return Authentication(ctx, localClient, localRestConfig, remoteclient, remoteRestConfig, localNamespaceName, remoteNamespaceName)
+		}},
+		{nodecorev1alpha1.PeeringRequestingResources, "Requesting resources through ResourceSlice " + contract.Name, func() error {
+			return Offloading(ctx, localClient, remoteRestConfig, localNamespaceName, contract)
+		}},
+		{nodecorev1alpha1.PeeringWaitingVirtualNode, "Waiting for VirtualNode " + contract.Name, func() error {
+			return waitVirtualNode(ctx, localClient, localNamespaceName, contract)
+		}},
+	}
+
+	if from == nodecorev1alpha1.PeeringCompleted {
+		return nil
+	}
+	first := 0
+	if from != "" {
+		first = slices.IndexFunc(steps, func(s peeringStep) bool { return s.step == from })
+		if first < 0 {
+			return fmt.Errorf("unknown peering step %q", from)
 		}
 	}
 
-	err = Offloading(ctx, localClient, remoteRestConfig, localNamespaceName, contract)
-	if err != nil {
-		klog.Error(err)
-		return nil, err
+	for _, s := range steps[first:] {
+		report(s.step, s.msg)
+		if err := s.run(); err != nil {
+			return err
+		}
 	}
 
-	return localConnection, nil
+	klog.Infof("Peering with cluster %s established for contract %s", remoteClusterIdentity, contract.Name)
+
+	return nil
 }
 
 // OffloadNamespace creates a NamespaceOffloading inside the specified namespace with given pod offloading strategy and cluster selector.
@@ -0,0 +1,348 @@
+// Copyright 2022-2025 FLUIDOS Project
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualfabricmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authv1beta1 "github.com/liqotech/liqo/apis/authentication/v1beta1"
+	corev1beta1 "github.com/liqotech/liqo/apis/core/v1beta1"
+	networkingv1beta1 "github.com/liqotech/liqo/apis/networking/v1beta1"
+	offloadingv1beta1 "github.com/liqotech/liqo/apis/offloading/v1beta1"
+	"github.com/liqotech/liqo/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
+	reservation "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
+)
+
+// ErrPeeringInProgress is returned by PeerWithCluster when a step is still waiting
+// for the clusters to converge. The caller is expected to retry later.
+var ErrPeeringInProgress = errors.New("peering in progress")
+
+// ErrTeardownInProgress is returned by UnpeerWithCluster when a step is still waiting
+// for resources to go away. The caller is expected to retry later.
+var ErrTeardownInProgress = errors.New("teardown in progress")
+
+// UnpeeringReporter is invoked by UnpeerWithCluster before each teardown step is performed.
+type UnpeeringReporter func(step nodecorev1alpha1.ReleaseStep, msg string)
+
+// UnpeerWithCluster tears down what PeerWithCluster created for a contract, in reverse order.
+// Offloaded pods are drained and the ResourceSlice and VirtualNode of the contract are removed.
+// Authentication, networking and the tenant namespaces are removed only if keepPeering is false,
+// that is when no other contract uses the same peer. Every step tolerates resources that are
+// already gone, so the routine can be run again until it returns nil. The provider revokes the
+// credentials once the contract is released, and then tears down its side itself: the remote
+// resources are skipped when the credentials are no longer accepted.
+func UnpeerWithCluster(
+	ctx context.Context,
+	localClient client.Client,
+	localRestConfig *rest.Config,
+	remoteClient client.Client,
+	contract *reservation.Contract,
+	keepPeering bool,
+	report UnpeeringReporter) error {
+	localClusterIdentity, err := getClusterIdentity(ctx, localRestConfig)
+	if err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	// The provider cluster is not queried, as the credentials may already have been revoked
+	remoteClusterIdentity := corev1beta1.ClusterID(contract.Spec.PeeringTargetCredentials.ClusterID)
+
+	localNamespaceName := TenantNamespaceName(string(remoteClusterIdentity))
+	remoteNamespaceName := TenantNamespaceName(string(localClusterIdentity))
+
+	klog.Infof("Unpeering contract %s from cluster %s", contract.Name, remoteClusterIdentity)
+
+	report(nodecorev1alpha1.ReleaseDrainingPods, "Draining pods offloaded on VirtualNode "+contract.Name)
+	if err := drainVirtualNode(ctx, localClient, contract.Name); err != nil {
+		return err
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingResourceSlice, "Removing ResourceSlice "+contract.Name)
+	rs := &authv1beta1.ResourceSlice{}
+	if err := deleteAndWait(ctx, localClient, client.ObjectKey{Name: contract.Name, Namespace: localNamespaceName}, rs); err != nil {
+		return err
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingVirtualNode, "Removing VirtualNode "+contract.Name)
+	vn := &offloadingv1beta1.VirtualNode{}
+	if err := deleteAndWait(ctx, localClient, client.ObjectKey{Name: contract.Name, Namespace: localNamespaceName}, vn); err != nil {
+		return err
+	}
+
+	if keepPeering {
+		klog.Infof("Cluster %s is still used by other contracts, keeping the peering", remoteClusterIdentity)
+		return nil
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingNamespaceOffload,
+		"Removing NamespaceOffloadings targeting cluster "+string(remoteClusterIdentity))
+	if err := removeNamespaceOffloadings(ctx, localClient, remoteClusterIdentity); err != nil {
+		return err
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingAuthentication, "Removing authentication with cluster "+string(remoteClusterIdentity))
+	if err := deleteAllInNamespace(ctx, localClient, &authv1beta1.IdentityList{}, localNamespaceName); err != nil {
+		return err
+	}
+	if err := deleteAllInNamespace(ctx, remoteClient, &authv1beta1.TenantList{}, remoteNamespaceName); ignoreRevoked(err) != nil {
+		return err
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingNetworking, "Removing networking with cluster "+string(remoteClusterIdentity))
+	for _, list := range []client.ObjectList{
+		&networkingv1beta1.GatewayClientList{},
+		&networkingv1beta1.PublicKeyList{},
+		&networkingv1beta1.ConfigurationList{},
+	} {
+		if err := deleteAllInNamespace(ctx, localClient, list, localNamespaceName); err != nil {
+			return err
+		}
+	}
+	for _, list := range []client.ObjectList{
+		&networkingv1beta1.GatewayServerList{},
+		&networkingv1beta1.PublicKeyList{},
+		&networkingv1beta1.ConfigurationList{},
+	} {
+		if err := deleteAllInNamespace(ctx, remoteClient, list, remoteNamespaceName); ignoreRevoked(err) != nil {
+			return err
+		}
+	}
+
+	report(nodecorev1alpha1.ReleaseRemovingTenantNamespace, "Removing tenant namespaces "+localNamespaceName+" and "+remoteNamespaceName)
+	localNamespace := &corev1.Namespace{}
+	if err := deleteAndWait(ctx, localClient, client.ObjectKey{Name: localNamespaceName}, localNamespace); err != nil {
+		return err
+	}
+	remoteNamespace := &corev1.Namespace{}
+	if err := deleteAndWait(ctx, remoteClient, client.ObjectKey{Name: remoteNamespaceName}, remoteNamespace); ignoreRevoked(err) != nil {
+		return err
+	}
+
+	klog.Infof("Peering with cluster %s removed", remoteClusterIdentity)
+
+	return nil
+}
+
+// RemoveConsumerPeering tears down on the provider the peering of a consumer that has not torn it down itself:
+// the ResourceSlices, the Tenant, the gateway server, the public keys and the configurations in its tenant namespace
+// are deleted, then the tenant namespace. It returns ErrTeardownInProgress until the tenant namespace is gone.
+func RemoveConsumerPeering(ctx context.Context, cl client.Client, consumerClusterID string) error {
+	namespace := TenantNamespaceName(consumerClusterID)
+	for _, list := range []client.ObjectList{
+		&authv1beta1.ResourceSliceList{},
+		&authv1beta1.TenantList{},
+		&networkingv1beta1.GatewayServerList{},
+		&networkingv1beta1.PublicKeyList{},
+		&networkingv1beta1.ConfigurationList{},
+	} {
+		if err := deleteAllInNamespace(ctx, cl, list, namespace); err != nil {
+			return err
+		}
+	}
+
+	return deleteAndWait(ctx, cl, client.ObjectKey{Name: namespace}, &corev1.Namespace{})
+}
+
+// getClusterIdentity retrieves the Liqo cluster ID of the cluster reachable through restConfig.
+func getClusterIdentity(ctx context.Context, restConfig *rest.Config) (corev1beta1.ClusterID, error) {
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		klog.Errorf("Error creating the clientSet: %s", err)
+		return "", err
+	}
+
+	return utils.GetClusterID(ctx, kubeClient, consts.LiqoNamespace)
+}
+
+// PodNodeNameField is the field selecting the pods scheduled on a node. The clients used to unpeer must index it when cached.
+const PodNodeNameField = "spec.nodeName"
+
+// drainVirtualNode cordons the node backing the VirtualNode and evicts the pods scheduled on it.
+// It returns ErrTeardownInProgress until no pod is left on the node.
+func drainVirtualNode(ctx context.Context, cl client.Client, nodeName string) error {
+	node := &corev1.Node{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Infof("Node %s not found, nothing to drain", nodeName)
+			return nil
+		}
+		klog.Error(err)
+		return err
+	}
+
+	if !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		if err := cl.Update(ctx, node); err != nil {
+			klog.Errorf("Error when cordoning node %s: %s", nodeName, err)
+			return err
+		}
+		klog.Infof("Node %s cordoned", nodeName)
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.MatchingFields{PodNodeNameField: nodeName}); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	remaining := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		remaining++
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		eviction := &policyv1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+			},
+		}
+		if err := cl.SubResource("eviction").Create(ctx, pod, eviction); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if apierrors.IsTooManyRequests(err) {
+				klog.Infof("Eviction of pod %s/%s blocked by a disruption budget", pod.Namespace, pod.Name)
+				continue
+			}
+			klog.Errorf("Error when evicting pod %s/%s: %s", pod.Namespace, pod.Name, err)
+			return err
+		}
+		klog.Infof("Pod %s/%s evicted from node %s", pod.Namespace, pod.Name, nodeName)
+	}
+
+	if remaining > 0 {
+		return fmt.Errorf("%w: %d pods still on node %s", ErrTeardownInProgress, remaining, nodeName)
+	}
+
+	return nil
+}
+
+// ignoreRevoked returns nil if the error comes from credentials revoked by the provider, which then tears down its side itself.
+func ignoreRevoked(err error) error {
+	if apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err) {
+		klog.Infof("Credentials of the provider cluster not accepted anymore, its side is torn down by the provider: %s", err)
+		return nil
+	}
+	return err
+}
+
+// deleteAndWait deletes the object identified by key and returns ErrTeardownInProgress until it is gone.
+func deleteAndWait(ctx context.Context, cl client.Client, key client.ObjectKey, obj client.Object) error {
+	if err := cl.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Error(err)
+		return err
+	}
+
+	if obj.GetDeletionTimestamp() == nil {
+		if err := cl.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when deleting %T %s: %s", obj, key, err)
+			return err
+		}
+		klog.Infof("%T %s deleted", obj, key)
+	}
+
+	return fmt.Errorf("%w: waiting for %T %s to be removed", ErrTeardownInProgress, obj, key)
+}
+
+// deleteAllInNamespace deletes every object of the given list type in the namespace.
+func deleteAllInNamespace(ctx context.Context, cl client.Client, list client.ObjectList, namespace string) error {
+	if err := cl.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		klog.Error(err)
+		return err
+	}
+
+	return meta.EachListItem(list, func(o runtime.Object) error {
+		obj, ok := o.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected object %T", o)
+		}
+		if err := cl.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when deleting %T %s/%s: %s", obj, obj.GetNamespace(), obj.GetName(), err)
+			return err
+		}
+		return nil
+	})
+}
+
+// removeNamespaceOffloadings deletes the NamespaceOffloadings whose only target is the given cluster.
+func removeNamespaceOffloadings(ctx context.Context, cl client.Client, clusterID corev1beta1.ClusterID) error {
+	nsOffloadings := &offloadingv1beta1.NamespaceOffloadingList{}
+	if err := cl.List(ctx, nsOffloadings); err != nil {
+		klog.Error(err)
+		return err
+	}
+
+	for i := range nsOffloadings.Items {
+		nsOffloading := &nsOffloadings.Items[i]
+		if !targetsOnlyCluster(nsOffloading, clusterID) {
+			continue
+		}
+		if err := cl.Delete(ctx, nsOffloading); client.IgnoreNotFound(err) != nil {
+			klog.Errorf("Error when deleting NamespaceOffloading %s/%s: %s", nsOffloading.Namespace, nsOffloading.Name, err)
+			return err
+		}
+		klog.Infof("NamespaceOffloading %s/%s deleted", nsOffloading.Namespace, nsOffloading.Name)
+	}
+
+	return nil
+}
+
+// targetsOnlyCluster checks whether the cluster selector of the NamespaceOffloading selects only the given cluster.
+func targetsOnlyCluster(nsOffloading *offloadingv1beta1.NamespaceOffloading, clusterID corev1beta1.ClusterID) bool {
+	terms := nsOffloading.Spec.ClusterSelector.NodeSelectorTerms
+	if len(terms) == 0 {
+		return false
+	}
+	for i := range terms {
+		found := false
+		for _, expr := range terms[i].MatchExpressions {
+			if expr.Key != consts.LiqoRemoteClusterIDLabel {
+				continue
+			}
+			if expr.Operator != corev1.NodeSelectorOpIn || len(expr.Values) != 1 || expr.Values[0] != string(clusterID) {
+				return false
+			}
+			found = true
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -15,12 +15,16 @@
 package virtualfabricmanager
 
 import (
+	"strconv"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
 	nodecorev1alpha1 "github.com/fluidos-project/node/apis/nodecore/v1alpha1"
 	reservationv1alpha1 "github.com/fluidos-project/node/apis/reservation/v1alpha1"
+	"github.com/fluidos-project/node/pkg/utils/consts"
 )
 
 func getContractResourcesByClusterID(contract *reservationv1alpha1.Contract) (map[corev1.ResourceName]string, error) {
@@ -90,8 +94,13 @@ func addResources(resources map[corev1.ResourceName]string,
 	case nodecorev1alpha1.TypeK8Slice:
 		// Force casting
 		k8sliceConfiguration := configurationData.(nodecorev1alpha1.K8SliceConfiguration)
+		// The GPUs of the configuration are the ones of the Flavor
+		var gpu *nodecorev1alpha1.GPU
+		if k8sliceFlavor, ok := flavorData.(nodecorev1alpha1.K8Slice); ok {
+			gpu = k8sliceFlavor.Characteristics.Gpu
+		}
 		// Obtain the resources from the configuration
-		resourcesToAdd = mapK8SliceConfigurationToResources(&k8sliceConfiguration)
+		resourcesToAdd = mapK8SliceConfigurationToResources(&k8sliceConfiguration, gpu)
 	case nodecorev1alpha1.TypeVM:
 		// TODO (VM): Implement Liqo resource management for VMs
 		klog.Errorf("VM configuration not supported yet")
@@ -128,18 +137,33 @@ func addResources(resources map[corev1.ResourceName]string,
 	return resources, nil
 }
 
-func mapK8SliceConfigurationToResources(k8SliceConfiguration *nodecorev1alpha1.K8SliceConfiguration) map[corev1.ResourceName]string {
+func mapK8SliceConfigurationToResources(k8SliceConfiguration *nodecorev1alpha1.K8SliceConfiguration,
+	flavorGpu *nodecorev1alpha1.GPU) map[corev1.ResourceName]string {
 	resources := make(map[corev1.ResourceName]string)
 	resources[corev1.ResourceCPU] = k8SliceConfiguration.CPU.String()
 	resources[corev1.ResourceMemory] = k8SliceConfiguration.Memory.String()
 	resources[corev1.ResourcePods] = k8SliceConfiguration.Pods.String()
 	if k8SliceConfiguration.Storage != nil {
 		resources[corev1.ResourceStorage] = k8SliceConfiguration.Storage.String()
-		resources[corev1.ResourceEphemeralStorage] = k8SliceConfiguration.Storage.String()
+	}
+	if k8SliceConfiguration.Gpu != nil && flavorGpu != nil {
+		addGPUResources(resources, k8SliceConfiguration.Gpu, flavorGpu.Vendor)
 	}
 	return resources
 }
 
+// addGPUResources adds the GPUs and MIG instances to the resources, as the extended resources of the device plugin of their vendor.
+func addGPUResources(resources map[corev1.ResourceName]string, gpu *nodecorev1alpha1.GPU, vendor string) {
+	if name, ok := consts.GPUResources[strings.ToLower(vendor)]; ok && gpu.Count > 0 {
+		resources[corev1.ResourceName(name)] = strconv.FormatInt(gpu.Count, 10)
+	}
+	for _, profile := range gpu.MIGProfiles {
+		if profile.Count > 0 {
+			resources[corev1.ResourceName(consts.NvidiaMIGResourcePrefix+profile.Name)] = strconv.FormatInt(profile.Count, 10)
+		}
+	}
+}
+
 func mapServiceWithConfigurationToResources(service *nodecorev1alpha1.ServiceFlavor,
 	serviceConfiguration *nodecorev1alpha1.ServiceConfiguration) map[corev1.ResourceName]string {
 	var hostingPolicy nodecorev1alpha1.HostingPolicy
@@ -207,7 +231,9 @@ func mapK8SliceToResources(k8Slice *nodecorev1alpha1.K8Slice) map[corev1.Resourc
 	resources[corev1.ResourcePods] = k8Slice.Characteristics.Pods.String()
 	if k8Slice.Characteristics.Storage != nil {
 		resources[corev1.ResourceStorage] = k8Slice.Characteristics.Storage.String()
-		resources[corev1.ResourceEphemeralStorage] = k8Slice.Characteristics.Storage.String()
+	}
+	if k8Slice.Characteristics.Gpu != nil {
+		addGPUResources(resources, k8Slice.Characteristics.Gpu, k8Slice.Characteristics.Gpu.Vendor)
 	}
 	return resources
 }
@@ -31,8 +31,8 @@ read_input "Type the role: publisher | subscriber | both" "role"
 read_input ".json file for RULE" "rule_file"
 read_input ".json file for metrics" "metric_file"
 
-rule_json=$(jq -c . "$rule_file" | sed 's/"/\\"/g')
-metric_json=$(jq -c . "$metric_file" | sed 's/"/\\"/g')
+rule_json=$(jq -c . "$rule_file")
+metric_json=$(jq -c . "$metric_file")
 
 broker_ca_secret="$broker_name"-ca-"$RANDOM"
 broker_client_secret="$broker_name"-cl-"$RANDOM"
@@ -62,8 +62,8 @@ spec:
   name: $broker_name
   address: $address
   role: $role
-  rule: "$rule_json"
-  metric: "$metric_json"
+  rule: $rule_json
+  metric: $metric_json
   cacert: $broker_ca_secret
   clcert: $broker_client_secret
 